@@ -0,0 +1,258 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// AdminFeedback is a stored submission as returned by the admin API.
+type AdminFeedback struct {
+	ID                 string `json:"id"`
+	SubmittedAt        string `json:"submittedAt,omitempty"`
+	ClientSubmittedAt  string `json:"clientSubmittedAt,omitempty"`
+	Helpfulness        string `json:"helpfulness"`
+	SetupDifficulty    int    `json:"setupDifficulty"`
+	DocsQuality        string `json:"docsQuality"`
+	SetupIssues        string `json:"setupIssues"`
+	AdditionalFeedback string `json:"additionalFeedback"`
+	Email              string `json:"email"`
+	Source             string `json:"source"`
+	OverflowRef        string `json:"overflowRef,omitempty"`
+	Status             string `json:"status"`
+	Notes              string `json:"notes"`
+
+	ContactConsent       bool   `json:"contactConsent"`
+	QuoteConsent         bool   `json:"quoteConsent"`
+	PrivacyPolicyVersion string `json:"privacyPolicyVersion,omitempty"`
+	ConsentedAt          string `json:"consentedAt,omitempty"`
+
+	Redactions string `json:"redactions,omitempty"`
+}
+
+// AdminFeedbackListResponse is a page of submissions.
+type AdminFeedbackListResponse struct {
+	Success       bool            `json:"success"`
+	Items         []AdminFeedback `json:"items"`
+	NextPageToken string          `json:"nextPageToken,omitempty"`
+	Total         int             `json:"total"`
+}
+
+// AdminFeedbackResponse wraps a single submission.
+type AdminFeedbackResponse struct {
+	Success  bool          `json:"success"`
+	Feedback AdminFeedback `json:"feedback"`
+}
+
+// TriageRequest updates the triage state of a submission.
+type TriageRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// HandleAdminFeedback serves the admin feedback API. Without an id it lists
+// submissions; with an id it returns one submission (GET) or updates its
+// triage state (PATCH or POST). Callers must be authenticated by the auth
+// middleware with feedback:read, and feedback:triage to make changes.
+func HandleAdminFeedback(w http.ResponseWriter, r *http.Request, id string) {
+	if !requirePrincipalScope(w, r, auth.ScopeFeedbackRead) {
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Admin request received but Google Sheets service is not available")
+		http.Error(w, "Feedback storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		listAdminFeedback(ctx, w, r)
+	case id != "" && r.Method == http.MethodGet:
+		getAdminFeedback(ctx, w, id)
+	case id != "" && (r.Method == http.MethodPatch || r.Method == http.MethodPost):
+		if !requirePrincipalScope(w, r, auth.ScopeFeedbackTriage) {
+			return
+		}
+		triageAdminFeedback(ctx, w, r, id)
+	default:
+		logging.Errorf("Invalid method %s for admin feedback endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listAdminFeedback(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := &google.FeedbackQuery{
+		Source:      params.Get("source"),
+		Helpfulness: params.Get("helpfulness"),
+		SetupIssue:  params.Get("setupIssue"),
+		Status:      params.Get("status"),
+		PageToken:   params.Get("pageToken"),
+	}
+
+	var err error
+	if query.From, err = parseDateParam(params.Get("from")); err != nil {
+		http.Error(w, "Invalid 'from' date", http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseDateParam(params.Get("to")); err != nil {
+		http.Error(w, "Invalid 'to' date", http.StatusBadRequest)
+		return
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if query.Status != "" && !google.IsValidTriageStatus(query.Status) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	page, err := sheetsService.ListFeedback(ctx, query)
+	if err != nil {
+		logging.Errorf("Failed to list feedback: %v", err)
+		http.Error(w, "Failed to list feedback", http.StatusBadGateway)
+		return
+	}
+
+	response := AdminFeedbackListResponse{
+		Success:       true,
+		Items:         make([]AdminFeedback, 0, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+		Total:         page.Total,
+	}
+	for _, item := range page.Items {
+		response.Items = append(response.Items, toAdminFeedback(item))
+	}
+
+	writeJSON(w, response)
+}
+
+func getAdminFeedback(ctx context.Context, w http.ResponseWriter, id string) {
+	feedback, err := sheetsService.GetFeedback(ctx, id)
+	if errors.Is(err, google.ErrFeedbackNotFound) {
+		http.Error(w, "Feedback not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to get feedback %s: %v", id, err)
+		http.Error(w, "Failed to get feedback", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, AdminFeedbackResponse{Success: true, Feedback: toAdminFeedback(feedback)})
+}
+
+func triageAdminFeedback(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) {
+	var req TriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.Errorf("Failed to decode triage request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Status == "" && strings.TrimSpace(req.Note) == "" {
+		http.Error(w, "Status or note is required", http.StatusBadRequest)
+		return
+	}
+	if req.Status != "" && !google.IsValidTriageStatus(req.Status) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := sheetsService.UpdateTriage(ctx, id, &google.TriageUpdate{Status: req.Status, Note: req.Note})
+	if errors.Is(err, google.ErrFeedbackNotFound) {
+		http.Error(w, "Feedback not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to update triage for %s: %v", id, err)
+		http.Error(w, "Failed to update feedback", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, AdminFeedbackResponse{Success: true, Feedback: toAdminFeedback(feedback)})
+}
+
+// requirePrincipalScope checks the principal attached by the auth middleware
+// and writes the error response when it is missing or lacks scope.
+func requirePrincipalScope(w http.ResponseWriter, r *http.Request, scope string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		logging.Errorf("Admin request reached handler without a principal")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if !principal.HasScope(scope) {
+		logging.Errorf("Principal %s lacks scope %s", principal.Subject, scope)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain YYYY-MM-DD dates.
+func parseDateParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func toAdminFeedback(f *google.FeedbackData) AdminFeedback {
+	return AdminFeedback{
+		ID:                 f.ID,
+		SubmittedAt:        formatTime(f.SubmittedAt),
+		ClientSubmittedAt:  formatTime(f.ClientSubmittedAt),
+		Helpfulness:        f.Helpfulness,
+		SetupDifficulty:    f.SetupDifficulty,
+		DocsQuality:        f.DocsQuality,
+		SetupIssues:        f.SetupIssues,
+		AdditionalFeedback: f.AdditionalFeedback,
+		Email:              f.Email,
+		Source:             f.Source,
+		OverflowRef:        f.OverflowRef,
+		Status:             f.Status,
+		Notes:              f.Notes,
+
+		ContactConsent:       f.ContactConsent,
+		QuoteConsent:         f.QuoteConsent,
+		PrivacyPolicyVersion: f.PrivacyPolicyVersion,
+		ConsentedAt:          formatTime(f.ConsentedAt),
+
+		Redactions: f.Redactions,
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,336 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// stubSheetsService is an in-memory SheetsService for handler tests.
+type stubSheetsService struct {
+	items    []*google.FeedbackData
+	lastList *google.FeedbackQuery
+
+	privacyRecords []google.PrivacyRecord
+	rotations      int
+	retention      []bool
+	compactions    int
+	deliveries     []google.DeliveryRecord
+	// subscribers are keyed by email hash, like the sheet.
+	subscribers map[string]google.Subscriber
+	downloads   []google.DownloadEvent
+	events      []google.EventCount
+	eventsErr   error
+	// location is the service time zone, UTC when nil.
+	location *time.Location
+}
+
+func (s *stubSheetsService) AppendFeedback(ctx context.Context, feedback *google.FeedbackData) error {
+	s.items = append(s.items, feedback)
+	return nil
+}
+
+func (s *stubSheetsService) ListFeedback(ctx context.Context, query *google.FeedbackQuery) (*google.FeedbackPage, error) {
+	s.lastList = query
+	return &google.FeedbackPage{Items: s.items, Total: len(s.items)}, nil
+}
+
+func (s *stubSheetsService) GetFeedback(ctx context.Context, id string) (*google.FeedbackData, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return nil, google.ErrFeedbackNotFound
+}
+
+func (s *stubSheetsService) FeedbackAnswers(ctx context.Context, to time.Time) ([]google.FeedbackAnswers, error) {
+	answers := make([]google.FeedbackAnswers, 0, len(s.items))
+	for _, item := range s.items {
+		answers = append(answers, google.FeedbackAnswers{
+			SubmittedAt:     item.SubmittedAt,
+			Helpfulness:     item.Helpfulness,
+			SetupDifficulty: item.SetupDifficulty,
+			DocsQuality:     item.DocsQuality,
+			SetupIssues:     item.SetupIssues,
+		})
+	}
+	return answers, nil
+}
+
+func (s *stubSheetsService) UpdateTriage(ctx context.Context, id string, update *google.TriageUpdate) (*google.FeedbackData, error) {
+	item, err := s.GetFeedback(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Status != "" {
+		item.Status = update.Status
+	}
+	if update.Note != "" {
+		item.Notes = update.Note
+	}
+	return item, nil
+}
+
+func (s *stubSheetsService) FindFeedbackByEmail(ctx context.Context, email string) ([]*google.FeedbackData, error) {
+	var matches []*google.FeedbackData
+	for _, item := range s.items {
+		if google.NormalizeEmail(item.Email) == google.NormalizeEmail(email) {
+			matches = append(matches, item)
+		}
+	}
+	return matches, nil
+}
+
+func (s *stubSheetsService) EraseFeedbackByEmail(ctx context.Context, email string) (int, error) {
+	matches, _ := s.FindFeedbackByEmail(ctx, email)
+	for _, item := range matches {
+		item.Email = ""
+		item.AdditionalFeedback = google.ErasedMarker
+	}
+	return len(matches), nil
+}
+
+func (s *stubSheetsService) RecordPrivacyRequest(ctx context.Context, record *google.PrivacyRecord) error {
+	s.privacyRecords = append(s.privacyRecords, *record)
+	return nil
+}
+
+func (s *stubSheetsService) RotateFieldKeys(ctx context.Context) (*google.KeyRotationResult, error) {
+	s.rotations++
+	return &google.KeyRotationResult{Scanned: len(s.items)}, nil
+}
+
+func (s *stubSheetsService) ApplyRetention(ctx context.Context, policy *google.RetentionPolicy, dryRun bool) (*google.RetentionReport, error) {
+	s.retention = append(s.retention, dryRun)
+	return &google.RetentionReport{DryRun: dryRun, EmailsDropped: []string{}, RowsDeleted: []string{"abc123"}}, nil
+}
+
+func (s *stubSheetsService) RecordDelivery(ctx context.Context, record *google.DeliveryRecord) error {
+	s.deliveries = append(s.deliveries, *record)
+	return nil
+}
+
+func (s *stubSheetsService) FindSubscriber(ctx context.Context, email string) (*google.Subscriber, error) {
+	subscriber, ok := s.subscribers[google.NormalizeEmail(email)]
+	if !ok {
+		return nil, google.ErrSubscriberNotFound
+	}
+	return &subscriber, nil
+}
+
+func (s *stubSheetsService) SaveSubscriber(ctx context.Context, subscriber *google.Subscriber) error {
+	if s.subscribers == nil {
+		s.subscribers = map[string]google.Subscriber{}
+	}
+	if subscriber.ID == "" {
+		subscriber.ID = fmt.Sprintf("sub-%d", len(s.subscribers)+1)
+	}
+	if subscriber.Email != "" {
+		subscriber.EmailHash = google.NormalizeEmail(subscriber.Email)
+	}
+	s.subscribers[subscriber.EmailHash] = *subscriber
+	return nil
+}
+
+func (s *stubSheetsService) EraseSubscriber(ctx context.Context, email string) (bool, error) {
+	hash := google.NormalizeEmail(email)
+	_, ok := s.subscribers[hash]
+	delete(s.subscribers, hash)
+	return ok, nil
+}
+
+func (s *stubSheetsService) CompactCounts(ctx context.Context) (*google.CompactionResult, error) {
+	s.compactions++
+	return &google.CompactionResult{DownloadRows: 1}, nil
+}
+
+func (s *stubSheetsService) RecordDownload(ctx context.Context, event *google.DownloadEvent) error {
+	s.downloads = append(s.downloads, *event)
+	return nil
+}
+
+func (s *stubSheetsService) DownloadCounts(ctx context.Context, from, to time.Time) ([]google.DownloadCount, error) {
+	counts := []google.DownloadCount{}
+	for _, event := range s.downloads {
+		day := event.At.In(s.Location()).Format("2006-01-02")
+		if n := len(counts); n > 0 && counts[n-1].Day == day && counts[n-1].Artifact == event.Artifact {
+			counts[n-1].Count++
+			continue
+		}
+		counts = append(counts, google.DownloadCount{Day: day, Artifact: event.Artifact, Count: 1})
+	}
+	return counts, nil
+}
+
+func (s *stubSheetsService) AddEventCounts(ctx context.Context, counts []google.EventCount) error {
+	if s.eventsErr != nil {
+		return s.eventsErr
+	}
+	s.events = append(s.events, counts...)
+	return nil
+}
+
+func (s *stubSheetsService) EventCounts(ctx context.Context, from, to time.Time) ([]google.EventCount, error) {
+	return s.events, nil
+}
+
+func (s *stubSheetsService) Location() *time.Location {
+	if s.location == nil {
+		return time.UTC
+	}
+	return s.location
+}
+
+func setupAdmin(t *testing.T) *stubSheetsService {
+	t.Helper()
+
+	stub := &stubSheetsService{items: []*google.FeedbackData{{
+		ID:          "abc123",
+		SubmittedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Helpfulness: "not-helpful",
+		Source:      "landing-page",
+		Status:      google.TriageStatusNew,
+	}}}
+	SetSheetsService(stub)
+	t.Cleanup(func() { SetSheetsService(nil) })
+
+	return stub
+}
+
+// adminRequest builds a request carrying the principal the auth middleware
+// would attach.
+func adminRequest(method, target, body string, scopes ...string) *http.Request {
+	if len(scopes) == 0 {
+		scopes = []string{auth.ScopeFeedbackRead, auth.ScopeFeedbackTriage}
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	principal := &auth.Principal{Subject: "test", Method: auth.MethodAPIKey, Scopes: scopes}
+	return req.WithContext(auth.WithPrincipal(req.Context(), principal))
+}
+
+func TestHandleAdminFeedback_RequiresPrincipal(t *testing.T) {
+	setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleAdminFeedback(w, httptest.NewRequest("GET", "/", nil), "")
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a principal, got %d", w.Code)
+	}
+}
+
+func TestHandleAdminFeedback_Scopes(t *testing.T) {
+	setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("GET", "/", "", auth.ScopeFeedbackTriage), "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without feedback:read, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("PATCH", "/", `{"status":"triaged"}`, auth.ScopeFeedbackRead), "abc123")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for triage without feedback:triage, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("GET", "/", "", auth.ScopeAll), "abc123")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected wildcard scope to allow reads, got %d", w.Code)
+	}
+}
+
+func TestHandleAdminFeedback_List(t *testing.T) {
+	stub := setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("GET", "/?source=landing-page&status=new&from=2025-01-01&limit=10", ""), "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response AdminFeedbackListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Total != 1 || response.Items[0].ID != "abc123" || response.Items[0].SubmittedAt != "2025-01-02T03:04:05Z" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	q := stub.lastList
+	if q.Source != "landing-page" || q.Status != "new" || q.Limit != 10 || !q.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected query: %+v", q)
+	}
+}
+
+func TestHandleAdminFeedback_ListValidation(t *testing.T) {
+	setupAdmin(t)
+
+	for _, target := range []string{"/?from=yesterday", "/?limit=0", "/?status=closed"} {
+		w := httptest.NewRecorder()
+		HandleAdminFeedback(w, adminRequest("GET", target, ""), "")
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestHandleAdminFeedback_GetAndTriage(t *testing.T) {
+	setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("GET", "/", ""), "missing")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("PATCH", "/", `{"status":"actioned","note":"Fixed"}`), "abc123")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response AdminFeedbackResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Feedback.Status != "actioned" || response.Feedback.Notes != "Fixed" {
+		t.Errorf("Unexpected feedback: %+v", response.Feedback)
+	}
+
+	w = httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("PATCH", "/", `{"status":"closed"}`), "abc123")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid status, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("DELETE", "/", ""), "abc123")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestHandleAdminFeedback_StorageUnavailable(t *testing.T) {
+	setupAdmin(t)
+	SetSheetsService(nil)
+
+	w := httptest.NewRecorder()
+	HandleAdminFeedback(w, adminRequest("GET", "/", ""), "")
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+)
+
+const (
+	// countsTTL is how long download and event counts are served before
+	// the sheet is read again.
+	countsTTL = 5 * time.Minute
+	// defaultCountDays and maxCountDays bound the days counted.
+	defaultCountDays = 30
+	maxCountDays     = 365
+)
+
+// countsCache keeps the last counts read from a sheet. It always holds the
+// last maxCountDays days, so every ?days window is served from one read.
+type countsCache[T any] struct {
+	mu        sync.Mutex
+	counts    []T
+	expiresAt time.Time
+}
+
+// get returns the cached counts, calling read once they have expired. When
+// read fails the previous counts are served, if there are any.
+func (c *countsCache[T]) get(ctx context.Context, what string, read func(ctx context.Context) ([]T, error)) ([]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.counts != nil && now.Before(c.expiresAt) {
+		return c.counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	counts, err := read(ctx)
+	switch {
+	case err == nil:
+		if counts == nil {
+			counts = []T{}
+		}
+		c.counts = counts
+		c.expiresAt = now.Add(countsTTL)
+	case c.counts != nil:
+		logging.Warningf("Failed to refresh %s, serving cached copy: %v", what, err)
+	default:
+		return nil, err
+	}
+	return c.counts, nil
+}
+
+func (c *countsCache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
+	c.expiresAt = time.Time{}
+}
+
+// parseCountDays parses the ?days parameter of the counts endpoints, which
+// defaults to defaultCountDays.
+func parseCountDays(value string) (int, error) {
+	if value == "" {
+		return defaultCountDays, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > maxCountDays {
+		return 0, fmt.Errorf("days must be a number from 1 to %d", maxCountDays)
+	}
+	return days, nil
+}
+
+// countWindow returns the first and last day, inclusive, of the days days
+// up to now in loc, formatted like the days stored in the sheet.
+func countWindow(now time.Time, loc *time.Location, days int) (string, string) {
+	today := now.In(loc)
+	return today.AddDate(0, 0, 1-days).Format("2006-01-02"), today.Format("2006-01-02")
+}
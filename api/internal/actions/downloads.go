@@ -0,0 +1,212 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// downloadArtifact is a file offered for download from the landing page.
+type downloadArtifact struct {
+	// extension selects the repository from github.ReleaseRepositories.
+	extension bool
+	// assetSuffix picks the release asset to download. Without it the
+	// release page is opened instead.
+	assetSuffix string
+}
+
+// downloadArtifacts lists the artifacts served by the download action.
+var downloadArtifacts = map[string]downloadArtifact{
+	"extension": {extension: true, assetSuffix: ".zip"},
+	"vega":      {},
+}
+
+// countryPattern matches ISO 3166 country codes. Geolocation headers use
+// "ZZ" or "XX" when the country is unknown.
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// DownloadCountsResponse holds download counts per artifact and day.
+type DownloadCountsResponse struct {
+	Success bool                   `json:"success"`
+	From    string                 `json:"from"`
+	To      string                 `json:"to"`
+	Counts  []google.DownloadCount `json:"counts"`
+	Totals  map[string]int         `json:"totals"`
+}
+
+var cachedDownloadCounts countsCache[google.DownloadCount]
+
+// HandleDownload redirects to the current file of an artifact and records
+// an anonymous download event once the redirect is sent. Crawlers are
+// redirected without being counted.
+func HandleDownload(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		logging.Errorf("Invalid method %s for download endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	artifact, ok := downloadArtifacts[name]
+	if !ok {
+		http.Error(w, "Unknown artifact", http.StatusNotFound)
+		return
+	}
+
+	target, version := resolveDownload(r.Context(), artifact)
+
+	// Every download must reach this handler to be counted.
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+	if r.Method == http.MethodHead || isCrawler(r) {
+		return
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	event := &google.DownloadEvent{
+		Artifact: name,
+		Version:  version,
+		Referrer: referrerHost(r),
+		Country:  countryBucket(r),
+		At:       time.Now(),
+	}
+	recordDownload(event)
+}
+
+// resolveDownload returns the URL of the artifact in the latest release and
+// its version. When the release cannot be looked up it falls back to the
+// latest release page on GitHub, with an empty version.
+func resolveDownload(ctx context.Context, artifact downloadArtifact) (string, string) {
+	vegaRepository, extensionRepository := github.ReleaseRepositories()
+	repository := vegaRepository
+	if artifact.extension {
+		repository = extensionRepository
+	}
+	fallback := "https://github.com/" + repository + "/releases/latest"
+
+	initReleaseCache()
+	if releaseCache == nil {
+		return fallback, ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	release, err := releaseCache.Latest(ctx, repository)
+	if err != nil {
+		logging.Warningf("Failed to resolve download from %s, using the releases page: %v", repository, err)
+		return fallback, ""
+	}
+	if artifact.assetSuffix == "" {
+		return release.URL, release.Version
+	}
+	for _, asset := range release.Assets {
+		if strings.HasSuffix(asset.Name, artifact.assetSuffix) {
+			return asset.URL, release.Version
+		}
+	}
+	logging.Warningf("Release %s of %s has no %s asset, using the release page", release.Version, repository, artifact.assetSuffix)
+	return release.URL, release.Version
+}
+
+// recordDownload stores a download event. Failures are logged and never
+// affect the download.
+func recordDownload(event *google.DownloadEvent) {
+	initSheetsService()
+	if sheetsService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sheetsService.RecordDownload(ctx, event); err != nil {
+		logging.Errorf("Failed to record %s download: %v", event.Artifact, err)
+	}
+}
+
+// referrerHost reduces the Referer header to its host, or "direct" when
+// there is none.
+func referrerHost(r *http.Request) string {
+	referrer, err := url.Parse(r.Referer())
+	if err != nil || referrer.Hostname() == "" {
+		return "direct"
+	}
+	return strings.ToLower(referrer.Hostname())
+}
+
+// countryBucket returns the country code set by the Google or Cloudflare
+// frontend, or "unknown".
+func countryBucket(r *http.Request) string {
+	for _, header := range []string{"X-Appengine-Country", "CF-IPCountry"} {
+		country := strings.ToUpper(strings.TrimSpace(r.Header.Get(header)))
+		if countryPattern.MatchString(country) && country != "ZZ" && country != "XX" {
+			return country
+		}
+	}
+	return "unknown"
+}
+
+// HandleDownloadCounts serves download counts per artifact and day for the
+// last ?days days, optionally for one ?artifact. Days are those of the
+// service's time zone, the one downloads are recorded in.
+func HandleDownloadCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logging.Errorf("Invalid method %s for download counts endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, err := parseCountDays(r.URL.Query().Get("days"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	artifact := r.URL.Query().Get("artifact")
+	if _, ok := downloadArtifacts[artifact]; artifact != "" && !ok {
+		http.Error(w, "Unknown artifact", http.StatusBadRequest)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Download counts requested but Google Sheets service is not available")
+		http.Error(w, "Download counts not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	all, err := cachedDownloadCounts.get(r.Context(), "download counts", func(ctx context.Context) ([]google.DownloadCount, error) {
+		return sheetsService.DownloadCounts(ctx, time.Now().AddDate(0, 0, -maxCountDays), time.Time{})
+	})
+	if err != nil {
+		logging.Errorf("Failed to count downloads: %v", err)
+		http.Error(w, "Failed to count downloads", http.StatusBadGateway)
+		return
+	}
+
+	from, to := countWindow(time.Now(), sheetsService.Location(), days)
+	response := DownloadCountsResponse{
+		Success: true,
+		From:    from,
+		To:      to,
+		Counts:  []google.DownloadCount{},
+		Totals:  map[string]int{},
+	}
+	for _, count := range all {
+		if count.Day < from || count.Day > to || (artifact != "" && count.Artifact != artifact) {
+			continue
+		}
+		response.Counts = append(response.Counts, count)
+		response.Totals[count.Artifact] += count.Count
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, response)
+}
@@ -0,0 +1,156 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github/githubtest"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+func TestHandleDownload_RedirectsAndRecords(t *testing.T) {
+	stub := setupAdmin(t)
+	srv := setupReleases(t)
+	srv.AddRelease(github.DefaultExtensionRepository, githubtest.Release{
+		TagName: "v0.4.0",
+		Assets:  []string{"checksums.txt", "vega-ai-extension-v0.4.0.zip"},
+	})
+
+	req := httptest.NewRequest("GET", "/download/extension", nil)
+	req.Header.Set("Referer", "https://Vega.Benidevo.com/#get-started")
+	req.Header.Set("X-Appengine-Country", "ng")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	w := httptest.NewRecorder()
+	HandleDownload(w, req, "extension")
+
+	if w.Code != http.StatusFound || !strings.HasSuffix(w.Header().Get("Location"), "/releases/download/v0.4.0/vega-ai-extension-v0.4.0.zip") {
+		t.Fatalf("expected a redirect to the zip, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Error("expected the redirect not to be cached")
+	}
+
+	if len(stub.downloads) != 1 {
+		t.Fatalf("expected one recorded download, got %+v", stub.downloads)
+	}
+	event := stub.downloads[0]
+	if event.Artifact != "extension" || event.Version != "v0.4.0" || event.Referrer != "vega.benidevo.com" || event.Country != "NG" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestHandleDownload_FallsBackToReleasesPage(t *testing.T) {
+	stub := setupAdmin(t)
+	setupReleases(t)
+
+	req := httptest.NewRequest("GET", "/download/vega", nil)
+	req.Header.Set("CF-IPCountry", "XX")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	w := httptest.NewRecorder()
+	HandleDownload(w, req, "vega")
+
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://github.com/"+github.DefaultVegaRepository+"/releases/latest" {
+		t.Fatalf("expected a redirect to the releases page, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if len(stub.downloads) != 1 || stub.downloads[0].Referrer != "direct" || stub.downloads[0].Country != "unknown" || stub.downloads[0].Version != "" {
+		t.Errorf("unexpected events %+v", stub.downloads)
+	}
+
+	w = httptest.NewRecorder()
+	HandleDownload(w, httptest.NewRequest("GET", "/download/other", nil), "other")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown artifact, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleDownload(w, httptest.NewRequest("HEAD", "/download/vega", nil), "vega")
+	if w.Code != http.StatusFound || len(stub.downloads) != 1 {
+		t.Errorf("expected HEAD to redirect without counting, got %d and %d events", w.Code, len(stub.downloads))
+	}
+}
+
+func TestHandleDownload_SkipsCrawlers(t *testing.T) {
+	stub := setupAdmin(t)
+	setupReleases(t)
+
+	for _, agent := range []string{"", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"} {
+		req := httptest.NewRequest("GET", "/download/vega", nil)
+		req.Header.Set("User-Agent", agent)
+		w := httptest.NewRecorder()
+		HandleDownload(w, req, "vega")
+
+		if w.Code != http.StatusFound {
+			t.Errorf("expected crawlers to be redirected, got %d", w.Code)
+		}
+	}
+	if len(stub.downloads) != 0 {
+		t.Errorf("expected crawler downloads not to be counted, got %+v", stub.downloads)
+	}
+}
+
+func TestHandleDownloadCounts(t *testing.T) {
+	stub := setupAdmin(t)
+	cachedDownloadCounts.reset()
+	t.Cleanup(cachedDownloadCounts.reset)
+
+	today := time.Now().UTC()
+	stub.downloads = []google.DownloadEvent{
+		{Artifact: "extension", At: today.AddDate(0, 0, -40)},
+		{Artifact: "extension", At: today.AddDate(0, 0, -1)},
+		{Artifact: "extension", At: today.AddDate(0, 0, -1)},
+		{Artifact: "vega", At: today},
+	}
+
+	get := func(target string) (*httptest.ResponseRecorder, DownloadCountsResponse) {
+		w := httptest.NewRecorder()
+		HandleDownloadCounts(w, httptest.NewRequest("GET", target, nil))
+		var response DownloadCountsResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := get("/?action=downloads")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(response.Counts) != 2 || response.Totals["extension"] != 2 || response.Totals["vega"] != 1 {
+		t.Errorf("expected the last 30 days, got %+v", response)
+	}
+
+	if _, response := get("/?action=downloads&days=60&artifact=extension"); response.Totals["extension"] != 3 || response.Totals["vega"] != 0 {
+		t.Errorf("expected extension downloads over 60 days, got %+v", response)
+	}
+
+	for _, target := range []string{"/?days=0", "/?days=abc", "/?artifact=other"} {
+		if w, _ := get(target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestHandleDownloadCounts_UsesServiceTimeZone(t *testing.T) {
+	stub := setupAdmin(t)
+	stub.location = time.FixedZone("UTC+14", 14*60*60)
+	cachedDownloadCounts.reset()
+	t.Cleanup(cachedDownloadCounts.reset)
+
+	now := time.Now()
+	stub.downloads = []google.DownloadEvent{{Artifact: "vega", At: now}}
+
+	w := httptest.NewRecorder()
+	HandleDownloadCounts(w, httptest.NewRequest("GET", "/?action=downloads&days=1", nil))
+	var response DownloadCountsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	today := now.In(stub.location).Format("2006-01-02")
+	if response.From != today || response.To != today || response.Totals["vega"] != 1 {
+		t.Errorf("expected today's download in the service time zone (%s), got %+v", today, response)
+	}
+}
@@ -0,0 +1,317 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+const (
+	// maxEventBody caps event beacons, which only name an event.
+	maxEventBody = 1 << 10
+	// maxEventVisitors bounds the visitor hashes kept for the current day.
+	// Beyond it events are still counted but visitors are not.
+	maxEventVisitors = 100_000
+	// eventWriteInterval is how often an instance writes the event counts
+	// it has collected to the sheet.
+	eventWriteInterval = time.Minute
+	// maxPendingEventCounts bounds the day and event counts waiting to be
+	// written. Beyond it new counts are dropped.
+	maxPendingEventCounts = 1_000
+)
+
+// pageEvents lists the events accepted from the landing page.
+var pageEvents = map[string]bool{
+	"pageview":            true,
+	"copy-docker-command": true,
+	"open-faq":            true,
+	"expand-feedback":     true,
+}
+
+// botPattern matches user agents of crawlers, whose beacons and downloads
+// are not counted.
+var botPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|headless|lighthouse`)
+
+// isCrawler reports whether a request comes from a crawler or another client
+// without a user agent.
+func isCrawler(r *http.Request) bool {
+	return r.UserAgent() == "" || botPattern.MatchString(r.UserAgent())
+}
+
+// EventCountsResponse holds page event counts per day.
+type EventCountsResponse struct {
+	Success bool                `json:"success"`
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Counts  []google.EventCount `json:"counts"`
+	// Totals adds up counts per event. Visitors are left out because they
+	// are only distinct within a day.
+	Totals map[string]int `json:"totals"`
+}
+
+// eventRequest is the body of an event beacon.
+type eventRequest struct {
+	Event string `json:"event"`
+}
+
+// eventVisitors remembers the daily visitor hashes this instance has seen
+// for each event, so that an event is only counted as a new visitor once.
+// The set is dropped when the UTC day changes. It is not shared between
+// instances, nor kept across restarts.
+type eventVisitors struct {
+	mu   sync.Mutex
+	day  string
+	seen map[string]struct{}
+}
+
+var pageEventVisitors eventVisitors
+
+// firstVisit records that a visitor caused an event on the UTC day of now. It
+// reports whether this is the first time this instance has seen them cause
+// that event on that day.
+func (v *eventVisitors) firstVisit(now time.Time, event, visitor string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	day := now.UTC().Format("2006-01-02")
+	if v.day != day {
+		v.day = day
+		v.seen = map[string]struct{}{}
+	}
+	key := event + "\x00" + visitor
+	if _, ok := v.seen[key]; ok || len(v.seen) >= maxEventVisitors {
+		return false
+	}
+	v.seen[key] = struct{}{}
+	return true
+}
+
+func (v *eventVisitors) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.day, v.seen = "", nil
+}
+
+// eventBatch collects the event counts of this instance between writes, so
+// that beacons reach the sheet in one append a minute rather than one each.
+// Counts are added up per day and event. Counts not yet written are lost
+// when the instance stops.
+type eventBatch struct {
+	mu        sync.Mutex
+	pending   map[[2]string]google.EventCount
+	writing   bool
+	writtenAt time.Time
+}
+
+var pendingEventCounts eventBatch
+
+// add adds a count to the batch. Once eventWriteInterval has passed since
+// the last write, and no other request is writing, it takes the pending
+// counts for the caller to write, and to pass to done afterwards.
+func (b *eventBatch) add(now time.Time, count google.EventCount) []google.EventCount {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.merge(count) {
+		logging.Warningf("Event counts are not being written, dropping a %s count", count.Event)
+	}
+	if b.writing || len(b.pending) == 0 || now.Sub(b.writtenAt) < eventWriteInterval {
+		return nil
+	}
+
+	counts := make([]google.EventCount, 0, len(b.pending))
+	for _, pending := range b.pending {
+		counts = append(counts, pending)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Day != counts[j].Day {
+			return counts[i].Day < counts[j].Day
+		}
+		return counts[i].Event < counts[j].Event
+	})
+	b.pending = nil
+	b.writing = true
+	b.writtenAt = now
+	return counts
+}
+
+// done ends the write of counts taken by add. When the write failed the
+// counts are put back, to be written with the next batch.
+func (b *eventBatch) done(counts []google.EventCount, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.writing = false
+	if err == nil {
+		return
+	}
+	dropped := 0
+	for _, count := range counts {
+		if !b.merge(count) {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		logging.Warningf("Event counts are not being written, dropped %d counts", dropped)
+	}
+}
+
+// merge adds a count to the pending counts of its day and event. It reports
+// false, dropping the count, when maxPendingEventCounts days and events are
+// already waiting.
+func (b *eventBatch) merge(count google.EventCount) bool {
+	key := [2]string{count.Day, count.Event}
+	pending, ok := b.pending[key]
+	if !ok && len(b.pending) >= maxPendingEventCounts {
+		return false
+	}
+	if b.pending == nil {
+		b.pending = map[[2]string]google.EventCount{}
+	}
+	pending.Day, pending.Event = count.Day, count.Event
+	pending.Count += count.Count
+	pending.Visitors += count.Visitors
+	b.pending[key] = pending
+	return true
+}
+
+func (b *eventBatch) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending, b.writing, b.writtenAt = nil, false, time.Time{}
+}
+
+var cachedEventCounts countsCache[google.EventCount]
+
+// HandleEvents counts a pageview or named event sent with
+// navigator.sendBeacon. Visitors are told apart by a hash of their address
+// and user agent that rotates daily; no cookies are set and nothing about
+// the visitor is stored, only daily totals per event. Requests asking not to
+// be tracked, and crawlers, are accepted without being counted.
+//
+// Counts are collected by each instance and written to the sheet at most
+// once every eventWriteInterval, by the request that finds the interval has
+// passed, after it is answered. Counts collected since the last write are
+// lost when the instance stops. Visitors are an approximate figure: each
+// instance only remembers the hashes it has seen today, so a visitor served
+// by several instances, or before and after a restart, is counted more than
+// once.
+func HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for events endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// sendBeacon posts strings as text/plain, so the body is read as JSON
+	// whatever its content type.
+	r.Body = http.MaxBytesReader(w, r.Body, maxEventBody)
+	var request eventRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !pageEvents[request.Event] {
+		http.Error(w, "Unknown event", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNoContent)
+
+	if r.Header.Get("DNT") == "1" || r.Header.Get("Sec-GPC") == "1" || isCrawler(r) {
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		return
+	}
+
+	now := time.Now()
+	count := google.EventCount{Day: now.UTC().Format("2006-01-02"), Event: request.Event, Count: 1}
+	if pageEventVisitors.firstVisit(now, request.Event, logging.DailyHash(logging.ClientIP(r), r.UserAgent())) {
+		count.Visitors = 1
+	}
+	counts := pendingEventCounts.add(now, count)
+	if len(counts) == 0 {
+		return
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	// The beacon may hang up once answered, which would cancel the request
+	// context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := sheetsService.AddEventCounts(ctx, counts)
+	pendingEventCounts.done(counts, err)
+	if err != nil {
+		logging.Errorf("Failed to store event counts: %v", err)
+	}
+}
+
+// HandleEventCounts serves page event counts per day for the last ?days
+// days, optionally for one ?event.
+func HandleEventCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logging.Errorf("Invalid method %s for event counts endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, err := parseCountDays(r.URL.Query().Get("days"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event := r.URL.Query().Get("event")
+	if event != "" && !pageEvents[event] {
+		http.Error(w, "Unknown event", http.StatusBadRequest)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Event counts requested but Google Sheets service is not available")
+		http.Error(w, "Event counts not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	all, err := cachedEventCounts.get(r.Context(), "event counts", func(ctx context.Context) ([]google.EventCount, error) {
+		return sheetsService.EventCounts(ctx, time.Now().AddDate(0, 0, -maxCountDays), time.Time{})
+	})
+	if err != nil {
+		logging.Errorf("Failed to read event counts: %v", err)
+		http.Error(w, "Failed to read event counts", http.StatusBadGateway)
+		return
+	}
+
+	// Event days are UTC days.
+	from, to := countWindow(time.Now(), time.UTC, days)
+	response := EventCountsResponse{
+		Success: true,
+		From:    from,
+		To:      to,
+		Counts:  []google.EventCount{},
+		Totals:  map[string]int{},
+	}
+	for _, count := range all {
+		if count.Day < from || count.Day > to || (event != "" && count.Event != event) {
+			continue
+		}
+		response.Counts = append(response.Counts, count)
+		response.Totals[count.Event] += count.Count
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, response)
+}
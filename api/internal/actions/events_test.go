@@ -0,0 +1,199 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+func setupEvents(t *testing.T) *stubSheetsService {
+	t.Helper()
+	stub := setupAdmin(t)
+	pageEventVisitors.reset()
+	pendingEventCounts.reset()
+	cachedEventCounts.reset()
+	t.Cleanup(pageEventVisitors.reset)
+	t.Cleanup(pendingEventCounts.reset)
+	t.Cleanup(cachedEventCounts.reset)
+	return stub
+}
+
+func sendEvent(body, ip, userAgent string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/?action=events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+	req.Header.Set("X-Forwarded-For", ip)
+	req.Header.Set("User-Agent", userAgent)
+	w := httptest.NewRecorder()
+	HandleEvents(w, req)
+	return w
+}
+
+// expireEventWrite lets the next event write the pending counts.
+func expireEventWrite() {
+	pendingEventCounts.mu.Lock()
+	defer pendingEventCounts.mu.Unlock()
+	pendingEventCounts.writtenAt = time.Now().Add(-eventWriteInterval)
+}
+
+func TestHandleEvents_WritesCountsInBatches(t *testing.T) {
+	stub := setupEvents(t)
+
+	for _, ip := range []string{"203.0.113.7", "203.0.113.7", "198.51.100.2"} {
+		w := sendEvent(`{"event":"pageview"}`, ip, "Mozilla/5.0")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Set-Cookie") != "" {
+			t.Fatal("expected no cookies")
+		}
+	}
+	sendEvent(`{"event":"copy-docker-command"}`, "203.0.113.7", "Mozilla/5.0")
+
+	today := time.Now().UTC().Format("2006-01-02")
+	first := google.EventCount{Day: today, Event: "pageview", Count: 1, Visitors: 1}
+	if len(stub.events) != 1 || stub.events[0] != first {
+		t.Fatalf("expected only the first event to be written before the interval passed, got %+v", stub.events)
+	}
+
+	expireEventWrite()
+	sendEvent(`{"event":"open-faq"}`, "203.0.113.7", "Mozilla/5.0")
+
+	want := []google.EventCount{
+		first,
+		{Day: today, Event: "copy-docker-command", Count: 1, Visitors: 1},
+		{Day: today, Event: "open-faq", Count: 1, Visitors: 1},
+		{Day: today, Event: "pageview", Count: 2, Visitors: 1},
+	}
+	if len(stub.events) != len(want) {
+		t.Fatalf("expected one count per day and event, got %+v", stub.events)
+	}
+	for i := range want {
+		if stub.events[i] != want[i] {
+			t.Errorf("count %d: expected %+v, got %+v", i, want[i], stub.events[i])
+		}
+	}
+}
+
+func TestHandleEvents_NotCounted(t *testing.T) {
+	stub := setupEvents(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"do not track", "POST", `{"event":"pageview"}`, map[string]string{"DNT": "1"}, http.StatusNoContent},
+		{"global privacy control", "POST", `{"event":"pageview"}`, map[string]string{"Sec-GPC": "1"}, http.StatusNoContent},
+		{"crawler", "POST", `{"event":"pageview"}`, map[string]string{"User-Agent": "Mozilla/5.0 (compatible; Googlebot/2.1)"}, http.StatusNoContent},
+		{"unknown event", "POST", `{"event":"scroll"}`, nil, http.StatusBadRequest},
+		{"invalid JSON", "POST", `event=pageview`, nil, http.StatusBadRequest},
+		{"too large", "POST", `{"event":"pageview","pad":"` + strings.Repeat("x", maxEventBody) + `"}`, nil, http.StatusBadRequest},
+		{"method", "GET", "", nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			HandleEvents(w, req)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+
+	if len(stub.events) != 0 {
+		t.Errorf("expected nothing to be counted, got %+v", stub.events)
+	}
+}
+
+func TestHandleEvents_FailedWriteIsRetried(t *testing.T) {
+	stub := setupEvents(t)
+	stub.eventsErr = errors.New("quota exceeded")
+
+	if w := sendEvent(`{"event":"open-faq"}`, "203.0.113.7", "Mozilla/5.0"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", w.Code)
+	}
+
+	stub.eventsErr = nil
+	expireEventWrite()
+	sendEvent(`{"event":"open-faq"}`, "203.0.113.7", "Mozilla/5.0")
+	if len(stub.events) != 1 || stub.events[0].Count != 2 || stub.events[0].Visitors != 1 {
+		t.Errorf("expected both events to be written together, got %+v", stub.events)
+	}
+}
+
+func TestEventBatch_DropsCountsBeyondCap(t *testing.T) {
+	now := time.Now()
+	batch := eventBatch{writtenAt: now}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= maxPendingEventCounts; i++ {
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		if counts := batch.add(now, google.EventCount{Day: day, Event: "pageview", Count: 1}); counts != nil {
+			t.Fatalf("expected nothing to be written before the interval passed, got %d counts", len(counts))
+		}
+	}
+	if len(batch.pending) != maxPendingEventCounts {
+		t.Errorf("expected %d pending counts, got %d", maxPendingEventCounts, len(batch.pending))
+	}
+
+	batch.add(now, google.EventCount{Day: "2025-01-01", Event: "pageview", Count: 1})
+	if batch.pending[[2]string{"2025-01-01", "pageview"}].Count != 2 {
+		t.Error("expected counts of a pending day and event to be added up beyond the cap")
+	}
+}
+
+func TestHandleEventCounts(t *testing.T) {
+	stub := setupEvents(t)
+	today := time.Now().UTC()
+	stub.events = []google.EventCount{
+		{Day: today.AddDate(0, 0, -40).Format("2006-01-02"), Event: "pageview", Count: 9, Visitors: 9},
+		{Day: today.AddDate(0, 0, -1).Format("2006-01-02"), Event: "pageview", Count: 4, Visitors: 3},
+		{Day: today.AddDate(0, 0, -1).Format("2006-01-02"), Event: "open-faq", Count: 2, Visitors: 2},
+	}
+	// The first event of an instance is written as soon as it is sent.
+	sendEvent(`{"event":"pageview"}`, "203.0.113.7", "Mozilla/5.0")
+
+	w := httptest.NewRecorder()
+	HandleEventCounts(w, httptest.NewRequest("GET", "/?action=events/counts", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response EventCountsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(response.Counts) != 3 || response.Totals["pageview"] != 5 || response.Totals["open-faq"] != 2 {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	w = httptest.NewRecorder()
+	HandleEventCounts(w, httptest.NewRequest("GET", "/?days=90&event=pageview", nil))
+	response = EventCountsResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(response.Counts) != 3 || response.Totals["pageview"] != 14 || len(response.Totals) != 1 {
+		t.Errorf("unexpected filtered response %+v", response)
+	}
+
+	for _, query := range []string{"?days=0", "?days=366", "?event=scroll"} {
+		w = httptest.NewRecorder()
+		HandleEventCounts(w, httptest.NewRequest("GET", "/"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package actions
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// Export formats accepted by the format query parameter.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// exportTimeout bounds a whole export, which may read many pages.
+const exportTimeout = 60 * time.Second
+
+// ExportRecord is one exported submission. Fields follow the sheet column
+// order so that every format lists them consistently.
+type ExportRecord struct {
+	Timestamp          string `json:"timestamp"`
+	Helpfulness        string `json:"helpfulness"`
+	SetupDifficulty    int    `json:"setupDifficulty"`
+	DocsQuality        string `json:"docsQuality"`
+	SetupIssues        string `json:"setupIssues"`
+	AdditionalFeedback string `json:"additionalFeedback"`
+	Email              string `json:"email"`
+	Source             string `json:"source"`
+	SubmissionID       string `json:"submissionId"`
+	OverflowRef        string `json:"overflowRef"`
+	ClientTimestamp    string `json:"clientTimestamp"`
+	ClockSkewSeconds   *int64 `json:"clockSkewSeconds"`
+	TriageStatus       string `json:"triageStatus"`
+	InternalNotes      string `json:"internalNotes"`
+
+	ContactConsent       bool   `json:"contactConsent"`
+	QuoteConsent         bool   `json:"quoteConsent"`
+	PrivacyPolicyVersion string `json:"privacyPolicyVersion"`
+	ConsentTimestamp     string `json:"consentTimestamp"`
+
+	EmailHash  string `json:"emailHash"`
+	Redactions string `json:"redactions"`
+}
+
+// exportWriter writes records in one export format.
+type exportWriter interface {
+	begin() error
+	write(f *google.FeedbackData) error
+	end() error
+}
+
+// HandleExport streams stored feedback as CSV (the default), JSON or NDJSON.
+// The from, to and source query parameters filter the export like the admin
+// list. Callers need the feedback:export scope.
+func HandleExport(w http.ResponseWriter, r *http.Request) {
+	if !requirePrincipalScope(w, r, auth.ScopeFeedbackExport) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		logging.Errorf("Invalid method %s for export endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	format := strings.ToLower(params.Get("format"))
+	if format == "" {
+		format = ExportFormatCSV
+	}
+
+	query := &google.FeedbackQuery{Source: params.Get("source"), Limit: google.MaxPageSize}
+	var err error
+	if query.From, err = parseDateParam(params.Get("from")); err != nil {
+		http.Error(w, "Invalid 'from' date", http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseDateParam(params.Get("to")); err != nil {
+		http.Error(w, "Invalid 'to' date", http.StatusBadRequest)
+		return
+	}
+	// Pin the upper bound so that submissions arriving mid-export do not
+	// shift the pages being read.
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+
+	var out exportWriter
+	var contentType string
+	switch format {
+	case ExportFormatCSV:
+		out, contentType = &csvExportWriter{w: csv.NewWriter(w)}, "text/csv; charset=utf-8"
+	case ExportFormatJSON:
+		out, contentType = &jsonExportWriter{w: w}, "application/json"
+	case ExportFormatNDJSON:
+		out, contentType = &jsonExportWriter{w: w, lines: true}, "application/x-ndjson"
+	default:
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Export requested but Google Sheets service is not available")
+		http.Error(w, "Feedback storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+	defer cancel()
+
+	// The first page is read before any output so that storage failures can
+	// still be reported with a proper status code.
+	page, err := sheetsService.ListFeedback(ctx, query)
+	if err != nil {
+		logging.Errorf("Failed to export feedback: %v", err)
+		http.Error(w, "Failed to export feedback", http.StatusBadGateway)
+		return
+	}
+
+	filename := fmt.Sprintf("vega-feedback-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	count, err := streamExport(ctx, w, out, query, page)
+	if err != nil {
+		// Headers are already sent, so the truncated body is all the client
+		// gets; the log records why.
+		logging.Errorf("Feedback export aborted after %d records: %v", count, err)
+		return
+	}
+
+	logging.Infof("Exported %d feedback records as %s", count, format)
+}
+
+func streamExport(ctx context.Context, w http.ResponseWriter, out exportWriter, query *google.FeedbackQuery, page *google.FeedbackPage) (int, error) {
+	flusher, _ := w.(http.Flusher)
+
+	if err := out.begin(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		for _, item := range page.Items {
+			if err := out.write(item); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+
+		var err error
+		if page, err = sheetsService.ListFeedback(ctx, query); err != nil {
+			return count, err
+		}
+	}
+
+	return count, out.end()
+}
+
+// csvExportWriter writes the sheet headers followed by one row per record.
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) begin() error {
+	return c.w.Write(google.FeedbackHeaders())
+}
+
+func (c *csvExportWriter) write(f *google.FeedbackData) error {
+	record := f.Record()
+	for i, cell := range record {
+		record[i] = escapeFormula(cell)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonExportWriter writes a JSON array, or newline delimited JSON when lines
+// is set.
+type jsonExportWriter struct {
+	w     io.Writer
+	lines bool
+	wrote bool
+}
+
+func (j *jsonExportWriter) begin() error {
+	if j.lines {
+		return nil
+	}
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonExportWriter) write(f *google.FeedbackData) error {
+	data, err := json.Marshal(toExportRecord(f))
+	if err != nil {
+		return fmt.Errorf("failed to encode record %s: %w", f.ID, err)
+	}
+
+	switch {
+	case j.lines:
+		data = append(data, '\n')
+	case j.wrote:
+		data = append([]byte(",\n"), data...)
+	default:
+		data = append([]byte("\n"), data...)
+	}
+	j.wrote = true
+
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) end() error {
+	if j.lines {
+		return nil
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+func toExportRecord(f *google.FeedbackData) ExportRecord {
+	record := ExportRecord{
+		Timestamp:          formatTime(f.SubmittedAt),
+		Helpfulness:        f.Helpfulness,
+		SetupDifficulty:    f.SetupDifficulty,
+		DocsQuality:        f.DocsQuality,
+		SetupIssues:        f.SetupIssues,
+		AdditionalFeedback: f.AdditionalFeedback,
+		Email:              f.Email,
+		Source:             f.Source,
+		SubmissionID:       f.ID,
+		OverflowRef:        f.OverflowRef,
+		ClientTimestamp:    formatTime(f.ClientSubmittedAt),
+		TriageStatus:       f.Status,
+		InternalNotes:      f.Notes,
+
+		ContactConsent:       f.ContactConsent,
+		QuoteConsent:         f.QuoteConsent,
+		PrivacyPolicyVersion: f.PrivacyPolicyVersion,
+		ConsentTimestamp:     formatTime(f.ConsentedAt),
+
+		EmailHash:  f.EmailHash,
+		Redactions: f.Redactions,
+	}
+	if !f.ClientSubmittedAt.IsZero() {
+		seconds := int64(f.ClockSkew.Round(time.Second) / time.Second)
+		record.ClockSkewSeconds = &seconds
+	}
+	return record
+}
+
+// escapeFormula neutralises cells that spreadsheet applications would
+// evaluate as formulas by prefixing them with a single quote. Plain numbers,
+// such as a negative clock skew, are left alone.
+func escapeFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			return cell
+		}
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,183 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+func setupExport(t *testing.T) *stubSheetsService {
+	t.Helper()
+
+	stub := setupAdmin(t)
+	stub.items = append(stub.items, &google.FeedbackData{
+		ID:                 "def456",
+		SubmittedAt:        time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		ClientSubmittedAt:  time.Date(2025, 1, 2, 23, 59, 48, 0, time.UTC),
+		ClockSkew:          -12 * time.Second,
+		Helpfulness:        "very-helpful",
+		AdditionalFeedback: `=HYPERLINK("http://evil.example","click")`,
+		Email:              "@attacker",
+		Source:             "+extension",
+		Status:             google.TriageStatusNew,
+	})
+	return stub
+}
+
+func TestHandleExport_CSV(t *testing.T) {
+	stub := setupExport(t)
+
+	req := adminRequest("GET", "/?action=export&source=landing-page&from=2025-01-01&to=2025-02-01", "", auth.ScopeFeedbackExport)
+	w := httptest.NewRecorder()
+	HandleExport(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") {
+		t.Errorf("expected attachment disposition, got %q", cd)
+	}
+
+	if stub.lastList.Source != "landing-page" || stub.lastList.From.IsZero() || stub.lastList.To.IsZero() {
+		t.Errorf("filters not passed through: %+v", stub.lastList)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], google.FeedbackHeaders()) {
+		t.Errorf("header does not match sheet headers: %v", rows[0])
+	}
+
+	row := rows[2]
+	if got := row[5]; got != `'=HYPERLINK("http://evil.example","click")` {
+		t.Errorf("formula not escaped: %q", got)
+	}
+	if row[6] != "'@attacker" || row[7] != "'+extension" {
+		t.Errorf("expected escaped email and source, got %q and %q", row[6], row[7])
+	}
+	if row[11] != "-12" {
+		t.Errorf("expected numeric clock skew to stay unescaped, got %q", row[11])
+	}
+}
+
+func TestHandleExport_JSONFormats(t *testing.T) {
+	setupExport(t)
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		HandleExport(w, adminRequest("GET", "/?format=json", "", auth.ScopeFeedbackExport))
+
+		var records []ExportRecord
+		if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, w.Body.String())
+		}
+		if len(records) != 2 || records[1].SubmissionID != "def456" {
+			t.Fatalf("unexpected records: %+v", records)
+		}
+		if records[1].ClockSkewSeconds == nil || *records[1].ClockSkewSeconds != -12 {
+			t.Errorf("unexpected clock skew: %v", records[1].ClockSkewSeconds)
+		}
+		if records[1].AdditionalFeedback[0] != '=' {
+			t.Error("JSON values should not be formula escaped")
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		HandleExport(w, adminRequest("GET", "/?format=ndjson", "", auth.ScopeFeedbackExport))
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+
+		var ids []string
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var record ExportRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+			}
+			ids = append(ids, record.SubmissionID)
+		}
+		if !reflect.DeepEqual(ids, []string{"abc123", "def456"}) {
+			t.Errorf("unexpected IDs %v", ids)
+		}
+	})
+}
+
+func TestHandleExport_Validation(t *testing.T) {
+	setupExport(t)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"missing scope", adminRequest("GET", "/?action=export", "", auth.ScopeFeedbackRead), http.StatusForbidden},
+		{"method", adminRequest("POST", "/?action=export", "", auth.ScopeFeedbackExport), http.StatusMethodNotAllowed},
+		{"format", adminRequest("GET", "/?format=xlsx", "", auth.ScopeFeedbackExport), http.StatusBadRequest},
+		{"date", adminRequest("GET", "/?from=yesterday", "", auth.ScopeFeedbackExport), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HandleExport(w, tt.req)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestExportRecord_FollowsSheetColumns(t *testing.T) {
+	recordType := reflect.TypeOf(ExportRecord{})
+	headers := google.FeedbackHeaders()
+	if recordType.NumField() != len(headers) {
+		t.Fatalf("expected %d fields, got %d", len(headers), recordType.NumField())
+	}
+	for i, header := range headers {
+		field := strings.ReplaceAll(header, " ", "")
+		if recordType.Field(i).Name != field {
+			t.Errorf("field %d: expected %s, got %s", i, field, recordType.Field(i).Name)
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"plain":       "plain",
+		"=1+1":        "'=1+1",
+		"+cmd":        "'+cmd",
+		"-cmd":        "'-cmd",
+		"@SUM(A1)":    "'@SUM(A1)",
+		"\t=1":        "'\t=1",
+		"-42":         "-42",
+		"+3.5":        "+3.5",
+		"a=b":         "a=b",
+		"2025-01-01Z": "2025-01-01Z",
+	}
+	for in, want := range tests {
+		if got := escapeFormula(in); got != want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
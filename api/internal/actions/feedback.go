@@ -3,16 +3,25 @@ package actions
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/benidevo/vega-ai-landing-page/api/internal/guidance"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/redact"
 	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
 )
 
+// followUpTimeout bounds all work done for a submission after the response
+// is sent, so that a stalled webhook or mail server does not hold the
+// instance. It stays well under the 300 second function timeout set in
+// .github/workflows/deploy.yml, after which the instance may be stopped
+// mid-request.
+const followUpTimeout = 30 * time.Second
+
 // FeedbackRequest represents the structure of feedback submitted by users.
 type FeedbackRequest struct {
 	Helpfulness        string `json:"helpfulness"`
@@ -22,17 +31,37 @@ type FeedbackRequest struct {
 	AdditionalFeedback string `json:"additionalFeedback"`
 	Email              string `json:"email"`
 	Source             string `json:"source"`
+	// ClientTimestamp is the RFC 3339 time at which the client submitted
+	// the form, used alongside the server receive time.
+	ClientTimestamp string `json:"clientTimestamp"`
+	// ConsentContact allows maintainers to contact the submitter at Email.
+	// It is required whenever an email is given.
+	ConsentContact bool `json:"consentContact"`
+	// ConsentQuote allows the feedback to be quoted publicly.
+	ConsentQuote bool `json:"consentQuote"`
+	// PrivacyPolicyVersion is the privacy policy version shown with the form.
+	PrivacyPolicyVersion string `json:"privacyPolicyVersion"`
 }
 
 // FeedbackResponse represents the standard response structure for feedback-related API endpoints.
 type FeedbackResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	// Suggestions are troubleshooting tips for the problems a submission
+	// reported, shown by the form straight away.
+	Suggestions []guidance.Suggestion `json:"suggestions,omitempty"`
 }
 
 var (
 	sheetsService google.SheetsService
 	sheetsOnce    sync.Once
+
+	// redactor masks secrets and personal data pasted into free text.
+	// Register further detectors on it to extend redaction.
+	redactor = redact.Default()
+
+	knowledgeBase     *guidance.KnowledgeBase
+	knowledgeBaseOnce sync.Once
 )
 
 // initSheetsService initializes the Google Sheets service once
@@ -41,18 +70,47 @@ func initSheetsService() {
 		ctx := context.Background()
 		service, err := google.NewGoogleSheetsServiceFromEnv(ctx)
 		if err != nil {
-			log.Printf("WARNING: Google Sheets not configured: %v", err)
+			logging.Warningf("Google Sheets not configured: %v", err)
 			return
 		}
 
 		sheetsService = service
-		log.Printf("INFO: Google Sheets service initialized successfully")
+		logging.Infof("Google Sheets service initialized successfully")
 	})
 }
 
+// SetSheetsService replaces the Google Sheets service used by the handlers and
+// skips environment based initialization. It is intended for tests that run
+// against a fake Sheets server; passing nil disables storage.
+func SetSheetsService(service google.SheetsService) {
+	sheetsOnce.Do(func() {})
+	sheetsService = service
+	cachedStats.reset()
+}
+
+// initKnowledgeBase loads the troubleshooting knowledge base once, falling
+// back to the built-in one when KNOWLEDGE_BASE_FILE cannot be loaded.
+func initKnowledgeBase() {
+	knowledgeBaseOnce.Do(func() {
+		kb, err := guidance.FromEnv()
+		if err != nil {
+			logging.Warningf("Using the built-in knowledge base: %v", err)
+			kb = guidance.Default()
+		}
+		knowledgeBase = kb
+	})
+}
+
+// SetKnowledgeBase replaces the knowledge base used for suggestions. It is
+// intended for tests; passing nil disables suggestions.
+func SetKnowledgeBase(kb *guidance.KnowledgeBase) {
+	knowledgeBaseOnce.Do(func() {})
+	knowledgeBase = kb
+}
+
 func HandleFeedback(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		log.Printf("ERROR: Invalid method %s for feedback endpoint", r.Method)
+		logging.Errorf("Invalid method %s for feedback endpoint", r.Method)
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -64,13 +122,13 @@ func HandleFeedback(w http.ResponseWriter, r *http.Request) {
 
 	if strings.Contains(contentType, "application/json") {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			log.Printf("ERROR: Failed to decode JSON request: %v", err)
+			logging.Errorf("Failed to decode JSON request: %v", err)
 			http.Error(w, "Invalid JSON", http.StatusBadRequest)
 			return
 		}
 	} else {
 		if err := r.ParseForm(); err != nil {
-			log.Printf("ERROR: Failed to parse form data: %v", err)
+			logging.Errorf("Failed to parse form data: %v", err)
 			http.Error(w, "Invalid form data", http.StatusBadRequest)
 			return
 		}
@@ -93,21 +151,46 @@ func HandleFeedback(w http.ResponseWriter, r *http.Request) {
 			AdditionalFeedback: r.FormValue("additionalFeedback"),
 			Email:              r.FormValue("email"),
 			Source:             r.FormValue("source"),
+			ClientTimestamp:    r.FormValue("clientTimestamp"),
+			// Checkboxes are only sent when ticked.
+			ConsentContact:       formBool(r.FormValue("consentContact")),
+			ConsentQuote:         formBool(r.FormValue("consentQuote")),
+			PrivacyPolicyVersion: r.FormValue("privacyPolicyVersion"),
 		}
 	}
 
 	if req.Helpfulness == "" {
-		log.Printf("ERROR: Missing required field 'helpfulness' in feedback request")
+		logging.Errorf("Missing required field 'helpfulness' in feedback request")
 		http.Error(w, "Helpfulness is required", http.StatusBadRequest)
 		return
 	}
 
+	if strings.TrimSpace(req.Email) != "" && !req.ConsentContact {
+		logging.Errorf("Feedback included an email without contact consent")
+		http.Error(w, "Consent to be contacted is required when providing an email", http.StatusBadRequest)
+		return
+	}
+
 	if req.Source == "" {
 		req.Source = "landing-page"
 	}
 
-	log.Printf("INFO: Processing feedback from source:  %s", req.Source)
+	// Redact before anything else sees the free text, logs included.
+	findings := redact.Findings{}
+	for _, field := range []*string{&req.SetupIssues, &req.AdditionalFeedback} {
+		redacted, fired := redactor.Redact(*field)
+		*field = redacted
+		findings.Add(fired)
+	}
+	if len(findings) > 0 {
+		logging.Warning("Redacted sensitive data from feedback", logging.Fields{"rules": strings.Join(findings.Rules(), ",")})
+	}
 
+	logging.Info("Processing feedback", logging.Fields{"source": req.Source})
+
+	// stored is set once the submission is saved, and is then announced to
+	// webhook targets, by email and on GitHub.
+	var stored *google.FeedbackData
 	if sheetsService != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -119,16 +202,33 @@ func HandleFeedback(w http.ResponseWriter, r *http.Request) {
 			AdditionalFeedback: req.AdditionalFeedback,
 			Email:              req.Email,
 			Source:             req.Source,
+
+			ContactConsent:       req.ConsentContact,
+			QuoteConsent:         req.ConsentQuote,
+			PrivacyPolicyVersion: req.PrivacyPolicyVersion,
+			Redactions:           findings.String(),
+		}
+		if req.ClientTimestamp != "" {
+			// The client clock is advisory, so an unparsable value is
+			// dropped rather than rejecting the feedback.
+			clientTime, err := time.Parse(time.RFC3339, req.ClientTimestamp)
+			if err != nil {
+				logging.Warningf("Ignoring invalid client timestamp: %v", err)
+			} else {
+				feedbackData.ClientSubmittedAt = clientTime
+			}
 		}
 		if err := sheetsService.AppendFeedback(ctx, feedbackData); err != nil {
-			log.Printf("ERROR: Failed to store feedback in Google Sheets: %v", err)
+			logging.Errorf("Failed to store feedback in Google Sheets: %v", err)
 			// continue processing
+		} else {
+			stored = feedbackData
 		}
 	} else {
-		log.Printf("WARNING: Google Sheets service not available, feedback not stored in sheets")
+		logging.Warningf("Google Sheets service not available, feedback not stored in sheets")
 	}
 
-	log.Printf("INFO: Feedback processed successfully")
+	logging.Infof("Feedback processed successfully")
 
 	w.Header().Set("Content-Type", "application/json")
 	response := FeedbackResponse{
@@ -136,9 +236,61 @@ func HandleFeedback(w http.ResponseWriter, r *http.Request) {
 		Message: "Thank you for your feedback! Your insights will help us improve Vega AI for everyone.",
 	}
 
+	initKnowledgeBase()
+	if knowledgeBase != nil {
+		response.Suggestions = knowledgeBase.Suggest(&guidance.Query{
+			SetupIssues:     splitSetupIssues(req.SetupIssues),
+			Helpfulness:     req.Helpfulness,
+			DocsQuality:     req.DocsQuality,
+			SetupDifficulty: req.SetupDifficulty,
+		})
+	}
+
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("ERROR: Failed to encode response: %v", err)
+		logging.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+
+	// Follow-ups run once the response is flushed, so that slow or failing
+	// webhook targets and mail relays never delay the submitter.
+	if stored != nil {
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		runFollowUps(stored, logging.ClientIP(r))
+	}
+}
+
+// runFollowUps notifies webhooks, sends emails and reports setup issues for
+// a stored submission. They run side by side under one deadline, so that a
+// slow step cannot push the others past the function timeout. client is the
+// submitter's address, used to throttle acknowledgement emails.
+func runFollowUps(feedback *google.FeedbackData, client string) {
+	ctx, cancel := context.WithTimeout(context.Background(), followUpTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, followUp := range []func(){
+		func() { notifyWebhooks(ctx, feedback) },
+		func() { sendFeedbackEmails(ctx, feedback, client) },
+		func() { reportSetupIssues(ctx, feedback) },
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			followUp()
+		}()
+	}
+	wg.Wait()
+}
+
+// formBool interprets a checkbox or boolean form value.
+func formBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "yes":
+		return true
+	}
+	parsed, err := strconv.ParseBool(value)
+	return err == nil && parsed
 }
@@ -0,0 +1,190 @@
+package actions
+
+import (
+	"context"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	mailer "github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+const (
+	// ackAddressInterval is how often one address can be sent an
+	// acknowledgement. Anyone can enter any address in the form, so this
+	// keeps the form from being used to flood an inbox.
+	ackAddressInterval = 24 * time.Hour
+	// ackClientInterval is how often one client can cause an
+	// acknowledgement to be sent, whatever addresses it enters.
+	ackClientInterval = 5 * time.Minute
+)
+
+// feedbackAcks throttles acknowledgements of feedback.
+var feedbackAcks = mailThrottle{addressInterval: ackAddressInterval, clientInterval: ackClientInterval}
+
+// contactAddress returns the address a submitter can be emailed at, or ""
+// when they did not agree to be contacted or the address is not a plain
+// valid address.
+func contactAddress(feedback *google.FeedbackData) string {
+	if !feedback.CanContact() {
+		return ""
+	}
+	address := strings.TrimSpace(feedback.Email)
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return ""
+	}
+	return address
+}
+
+// feedbackEmailData is the data available to the feedback email templates.
+// Free text has already been through redaction.
+type feedbackEmailData struct {
+	ID                 string
+	SubmittedAt        string
+	Helpfulness        string
+	SetupDifficulty    int
+	DocsQuality        string
+	SetupIssues        string
+	AdditionalFeedback string
+	Source             string
+	// Contact is the submitter's address, set only when they agreed to be
+	// contacted.
+	Contact string
+}
+
+// sendFeedbackEmails notifies the maintainers listed in MAIL_NOTIFY_TO of a
+// stored submission and, when the submitter agreed to be contacted, thanks
+// them. Acknowledgements are only sent to valid addresses and are throttled
+// per address and per client. Failures are logged and never affect the
+// submission.
+func sendFeedbackEmails(ctx context.Context, feedback *google.FeedbackData, client string) {
+	initEmailSender()
+	if emailSender == nil {
+		return
+	}
+
+	data := &feedbackEmailData{
+		ID:                 feedback.ID,
+		SubmittedAt:        feedback.SubmittedAt.UTC().Format(time.RFC1123),
+		Helpfulness:        feedback.Helpfulness,
+		SetupDifficulty:    feedback.SetupDifficulty,
+		DocsQuality:        feedback.DocsQuality,
+		SetupIssues:        feedback.SetupIssues,
+		AdditionalFeedback: feedback.AdditionalFeedback,
+		Source:             feedback.Source,
+	}
+	data.Contact = contactAddress(feedback)
+	if data.Contact == "" && feedback.CanContact() {
+		logging.Warning("Not contacting submitter with an invalid email", logging.Fields{"submission_id": feedback.ID})
+	}
+
+	for _, recipient := range notifyRecipients() {
+		msg, err := feedbackNotification.Render(recipient, data)
+		if err != nil {
+			logging.Errorf("Failed to render feedback notification: %v", err)
+			return
+		}
+		// Maintainers reply straight to submitters who can be contacted.
+		msg.ReplyTo = data.Contact
+		if err := emailSender.Send(ctx, msg); err != nil {
+			logging.Error("Failed to send feedback notification", logging.Fields{"submission_id": feedback.ID})
+		}
+	}
+
+	if data.Contact == "" {
+		return
+	}
+	if !feedbackAcks.allow(time.Now(), data.Contact, client) {
+		logging.Warning("Feedback acknowledgement throttled", logging.Fields{"submission_id": feedback.ID})
+		return
+	}
+	msg, err := feedbackAcknowledgement.Render(data.Contact, data)
+	if err != nil {
+		logging.Errorf("Failed to render feedback acknowledgement: %v", err)
+		return
+	}
+	if err := emailSender.Send(ctx, msg); err != nil {
+		logging.Error("Failed to send feedback acknowledgement", logging.Fields{"submission_id": feedback.ID})
+		return
+	}
+	logging.Info("Sent feedback acknowledgement", logging.Fields{"submission_id": feedback.ID})
+}
+
+// notifyRecipients parses the comma separated addresses in MAIL_NOTIFY_TO.
+func notifyRecipients() []string {
+	var recipients []string
+	for _, address := range strings.Split(os.Getenv("MAIL_NOTIFY_TO"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients
+}
+
+var feedbackNotification = mailer.MustTemplate("notification",
+	`[Vega AI feedback] {{.Helpfulness}}, setup difficulty {{.SetupDifficulty}}/10`,
+	`New feedback was submitted from {{.Source}} on {{.SubmittedAt}}.
+
+Helpfulness:      {{.Helpfulness}}
+Setup difficulty: {{.SetupDifficulty}}/10
+Docs quality:     {{or .DocsQuality "not answered"}}
+Setup issues:     {{or .SetupIssues "none"}}
+{{if .AdditionalFeedback}}
+Comments:
+
+{{.AdditionalFeedback}}
+{{end}}
+{{if .Contact}}The submitter agreed to be contacted; reply to this email to reach them.
+{{else}}The submitter did not leave a way to contact them.
+{{end}}
+Submission {{.ID}}
+`,
+	`<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: system-ui, sans-serif; line-height: 1.5; color: #1f2937;">
+<p>New feedback was submitted from <strong>{{.Source}}</strong> on {{.SubmittedAt}}.</p>
+<table cellpadding="4">
+<tr><th align="left">Helpfulness</th><td>{{.Helpfulness}}</td></tr>
+<tr><th align="left">Setup difficulty</th><td>{{.SetupDifficulty}}/10</td></tr>
+<tr><th align="left">Docs quality</th><td>{{or .DocsQuality "not answered"}}</td></tr>
+<tr><th align="left">Setup issues</th><td>{{or .SetupIssues "none"}}</td></tr>
+</table>
+{{if .AdditionalFeedback}}<blockquote style="white-space: pre-wrap; border-left: 3px solid #d1d5db; margin: 1em 0; padding-left: 1em;">{{.AdditionalFeedback}}</blockquote>{{end}}
+<p>{{if .Contact}}The submitter agreed to be contacted; reply to this email to reach them.{{else}}The submitter did not leave a way to contact them.{{end}}</p>
+<p style="color: #6b7280; font-size: 0.875em;">Submission {{.ID}}</p>
+</body>
+</html>
+`)
+
+var feedbackAcknowledgement = mailer.MustTemplate("acknowledgement",
+	`Thanks for your feedback on Vega AI`,
+	`Hello,
+
+Thank you for taking the time to tell us about your experience with Vega AI.
+Every response is read by the maintainers, and feedback like yours decides
+what we fix and document next.
+
+You agreed that we may contact you about it, so we may follow up at this
+address with questions or when something you raised is addressed.
+
+You can ask for a copy of your feedback, or for your email address and
+comments to be erased, at any time from the privacy section of the Vega AI
+site.
+
+The Vega AI maintainers
+`,
+	`<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: system-ui, sans-serif; line-height: 1.5; color: #1f2937;">
+<p>Hello,</p>
+<p>Thank you for taking the time to tell us about your experience with Vega AI. Every response is read by the maintainers, and feedback like yours decides what we fix and document next.</p>
+<p>You agreed that we may contact you about it, so we may follow up at this address with questions or when something you raised is addressed.</p>
+<p>You can ask for a copy of your feedback, or for your email address and comments to be erased, at any time from the privacy section of the Vega AI site.</p>
+<p>The Vega AI maintainers</p>
+</body>
+</html>
+`)
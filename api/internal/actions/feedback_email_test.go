@@ -0,0 +1,203 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	mailer "github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/mail/smtptest"
+)
+
+// setupSMTPSink routes handler email through a real SMTP mailer into a
+// local sink.
+func setupSMTPSink(t *testing.T) *smtptest.Server {
+	t.Helper()
+
+	sink := smtptest.NewServer()
+	t.Cleanup(sink.Close)
+
+	m, err := mailer.NewSMTPMailer(&mailer.SMTPConfig{Host: sink.Host(), Port: sink.Port(), From: "Vega AI <noreply@vega.example>"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetMailer(m)
+	feedbackAcks.reset()
+	t.Cleanup(func() {
+		SetMailer(nil)
+		feedbackAcks.reset()
+	})
+	return sink
+}
+
+func submitFeedbackForm(t *testing.T, form url.Values) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	HandleFeedback(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestHandleFeedback_SendsNotificationAndAcknowledgement(t *testing.T) {
+	setupAdmin(t)
+	sink := setupSMTPSink(t)
+	t.Setenv("MAIL_NOTIFY_TO", "ops@vega.example, lead@vega.example")
+
+	submitFeedbackForm(t, url.Values{
+		"helpfulness":        {"not-helpful"},
+		"setupDifficulty":    {"8"},
+		"setupIssues":        {"port-conflicts"},
+		"additionalFeedback": {"Port <8765> is taken"},
+		"email":              {"me@example.com"},
+		"consentContact":     {"true"},
+	})
+
+	messages := sink.Messages()
+	var recipients []string
+	parsed := map[string]*mail.Message{}
+	for _, msg := range messages {
+		recipients = append(recipients, msg.To...)
+		m, err := mail.ReadMessage(strings.NewReader(msg.Data))
+		if err != nil {
+			t.Fatalf("invalid message: %v", err)
+		}
+		parsed[msg.To[0]] = m
+	}
+	sort.Strings(recipients)
+	if strings.Join(recipients, ",") != "lead@vega.example,me@example.com,ops@vega.example" {
+		t.Fatalf("Unexpected recipients %v", recipients)
+	}
+
+	notification := parsed["ops@vega.example"]
+	if notification.Header.Get("Reply-To") != "me@example.com" {
+		t.Errorf("Expected maintainers to reply to the submitter, got %q", notification.Header.Get("Reply-To"))
+	}
+	if subject := notification.Header.Get("Subject"); !strings.Contains(subject, "not-helpful") {
+		t.Errorf("Unexpected notification subject %q", subject)
+	}
+	if !strings.HasPrefix(notification.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Expected text and HTML parts, got %q", notification.Header.Get("Content-Type"))
+	}
+
+	if ack := parsed["me@example.com"]; ack.Header.Get("Reply-To") != "" {
+		t.Error("Expected no Reply-To on the acknowledgement")
+	}
+}
+
+func TestHandleFeedback_NoAcknowledgementWithoutConsent(t *testing.T) {
+	setupAdmin(t)
+	sink := setupSMTPSink(t)
+	t.Setenv("MAIL_NOTIFY_TO", "ops@vega.example")
+
+	submitFeedbackForm(t, url.Values{"helpfulness": {"very-helpful"}})
+
+	messages := sink.Messages()
+	if len(messages) != 1 || messages[0].To[0] != "ops@vega.example" {
+		t.Fatalf("Expected only the maintainer notification, got %d messages", len(messages))
+	}
+	msg, _ := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	if msg.Header.Get("Reply-To") != "" {
+		t.Error("Expected no Reply-To without a contactable submitter")
+	}
+}
+
+func TestHandleFeedback_NoAcknowledgementToInvalidEmail(t *testing.T) {
+	setupAdmin(t)
+	sink := setupSMTPSink(t)
+	t.Setenv("MAIL_NOTIFY_TO", "ops@vega.example")
+
+	for _, email := range []string{"not-an-email", "Victim <victim@example.com>", "a@example.com, b@example.com"} {
+		submitFeedbackForm(t, url.Values{
+			"helpfulness":    {"very-helpful"},
+			"email":          {email},
+			"consentContact": {"true"},
+		})
+	}
+
+	for _, msg := range sink.Messages() {
+		if msg.To[0] != "ops@vega.example" {
+			t.Errorf("Expected no acknowledgement to an invalid email, got one to %v", msg.To)
+		}
+		parsed, _ := mail.ReadMessage(strings.NewReader(msg.Data))
+		if parsed.Header.Get("Reply-To") != "" {
+			t.Errorf("Expected no Reply-To for an invalid email, got %q", parsed.Header.Get("Reply-To"))
+		}
+	}
+}
+
+func TestHandleFeedback_ThrottlesAcknowledgements(t *testing.T) {
+	setupAdmin(t)
+	sink := setupSMTPSink(t)
+
+	submit := func(email, client string) {
+		form := url.Values{"helpfulness": {"very-helpful"}, "email": {email}, "consentContact": {"true"}}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = client + ":1234"
+		HandleFeedback(httptest.NewRecorder(), req)
+	}
+
+	submit("me@example.com", "192.0.2.1")
+	// The same address from another client, and another address from the
+	// same client, are both held back.
+	submit("ME@example.com", "192.0.2.2")
+	submit("you@example.com", "192.0.2.1")
+	submit("them@example.com", "192.0.2.3")
+
+	var recipients []string
+	for _, msg := range sink.Messages() {
+		recipients = append(recipients, msg.To...)
+	}
+	sort.Strings(recipients)
+	if strings.Join(recipients, ",") != "me@example.com,them@example.com" {
+		t.Errorf("Unexpected acknowledgements to %v", recipients)
+	}
+}
+
+func TestAckThrottle_Expires(t *testing.T) {
+	throttle := mailThrottle{addressInterval: ackAddressInterval, clientInterval: ackClientInterval}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !throttle.allow(now, "me@example.com", "client") {
+		t.Fatal("Expected the first acknowledgement to be allowed")
+	}
+	if !throttle.allow(now.Add(ackClientInterval), "other@example.com", "other") {
+		t.Error("Expected another address and client to be allowed")
+	}
+	if !throttle.allow(now.Add(ackClientInterval), "third@example.com", "client") {
+		t.Error("Expected the client to be allowed again after its interval")
+	}
+	if throttle.allow(now.Add(ackAddressInterval-time.Second), "me@example.com", "new") {
+		t.Error("Expected the address to be held back within its interval")
+	}
+	if !throttle.allow(now.Add(ackAddressInterval), "me@example.com", "newer") {
+		t.Error("Expected the address to be allowed again after its interval")
+	}
+}
+
+func TestFeedbackNotification_EscapesHTML(t *testing.T) {
+	msg, err := feedbackNotification.Render("ops@vega.example", &feedbackEmailData{
+		ID:                 "abc123",
+		Helpfulness:        "not-helpful",
+		AdditionalFeedback: `<img src=x onerror="alert(1)">`,
+		Source:             "landing-page",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(msg.HTML, "<img") {
+		t.Errorf("Expected feedback to be escaped in HTML, got %q", msg.HTML)
+	}
+	if !strings.Contains(msg.Text, `<img src=x onerror="alert(1)">`) || !strings.Contains(msg.Text, "did not leave a way to contact them") {
+		t.Errorf("Unexpected text %q", msg.Text)
+	}
+}
@@ -3,8 +3,11 @@ package actions
 import (
 	"bytes"
 	"encoding/json"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"strings"
 	"testing"
 )
@@ -18,6 +21,7 @@ func TestHandleFeedback_Success(t *testing.T) {
 		AdditionalFeedback: "Great tool!",
 		Email:              "test@example.com",
 		Source:             "test",
+		ConsentContact:     true,
 	}
 
 	body, _ := json.Marshal(req)
@@ -133,3 +137,129 @@ func TestFeedbackRequest_JSONTags(t *testing.T) {
 		t.Error("SetupDifficulty not preserved")
 	}
 }
+
+func TestHandleFeedback_EmailRequiresContactConsent(t *testing.T) {
+	body := `{"helpfulness":"very-helpful","email":"test@example.com","consentQuote":true}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleFeedback_ConsentStored(t *testing.T) {
+	stub := setupAdmin(t)
+
+	form := url.Values{
+		"helpfulness":          {"very-helpful"},
+		"email":                {"test@example.com"},
+		"consentContact":       {"true"},
+		"consentQuote":         {"on"},
+		"privacyPolicyVersion": {"2025-06-01"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	stored := stub.items[len(stub.items)-1]
+	if !stored.ContactConsent || !stored.QuoteConsent || stored.PrivacyPolicyVersion != "2025-06-01" {
+		t.Errorf("Expected consent to be stored, got %+v", stored)
+	}
+	if !stored.CanContact() {
+		t.Error("Expected consenting submitter to be contactable")
+	}
+}
+
+func TestHandleFeedback_RedactsFreeText(t *testing.T) {
+	stub := setupAdmin(t)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	key := "AIza" + strings.Repeat("k", 35)
+	form := url.Values{
+		"helpfulness":        {"not-helpful"},
+		"setupIssues":        {"gemini-api-key", "ADMIN_PASSWORD=hunter2"},
+		"additionalFeedback": {"My key " + key + " fails, reach me at +1 415 555 0100 or me@example.com"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	stored := stub.items[len(stub.items)-1]
+	if stored.SetupIssues != "gemini-api-key, ADMIN_PASSWORD=[redacted:admin-password]" {
+		t.Errorf("Unexpected setup issues %q", stored.SetupIssues)
+	}
+	want := "My key [redacted:gemini-api-key] fails, reach me at [redacted:phone] or [redacted:email]"
+	if stored.AdditionalFeedback != want {
+		t.Errorf("Expected %q, got %q", want, stored.AdditionalFeedback)
+	}
+	if stored.Redactions != "admin-password=1, email=1, gemini-api-key=1, phone=1" {
+		t.Errorf("Unexpected redactions %q", stored.Redactions)
+	}
+
+	for _, secret := range []string{key, "hunter2", "me@example.com", "555"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("Expected %q not to be logged", secret)
+		}
+	}
+}
+
+func TestHandleFeedback_Suggestions(t *testing.T) {
+	SetSheetsService(nil)
+
+	form := url.Values{
+		"helpfulness":     {"not-helpful"},
+		"setupDifficulty": {"9"},
+		"setupIssues":     {"port-conflicts", "gemini-api-key"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	var response FeedbackResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	var ids []string
+	for _, suggestion := range response.Suggestions {
+		ids = append(ids, suggestion.ID)
+	}
+	if strings.Join(ids, ",") != "port-conflicts,gemini-api-key,get-help" {
+		t.Errorf("Unexpected suggestions %v", ids)
+	}
+	if len(response.Suggestions[0].Steps) == 0 || len(response.Suggestions[0].Links) == 0 {
+		t.Errorf("Expected steps and links, got %+v", response.Suggestions[0])
+	}
+}
+
+func TestHandleFeedback_NoSuggestionsWhenAllIsWell(t *testing.T) {
+	SetSheetsService(nil)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"helpfulness":"very-helpful","setupDifficulty":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	if strings.Contains(w.Body.String(), "suggestions") {
+		t.Errorf("Expected no suggestions, got %s", w.Body.String())
+	}
+}
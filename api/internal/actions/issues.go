@@ -0,0 +1,95 @@
+package actions
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+var (
+	issueReporter     *github.Reporter
+	issueReporterOnce sync.Once
+)
+
+// initIssueReporter configures GitHub issue reporting from the environment
+// once.
+func initIssueReporter() {
+	issueReporterOnce.Do(func() {
+		reporter, err := github.NewReporterFromEnv()
+		if err != nil {
+			logging.Warningf("GitHub issue reporting not configured: %v", err)
+			return
+		}
+		issueReporter = reporter
+	})
+}
+
+// SetIssueReporter replaces the GitHub issue reporter used by the handlers.
+// It is intended for tests; passing nil disables issue reporting.
+func SetIssueReporter(reporter *github.Reporter) {
+	issueReporterOnce.Do(func() {})
+	issueReporter = reporter
+}
+
+// reportSetupIssues files the setup problems of a stored submission as
+// anonymized comments on the matching GitHub issues.
+func reportSetupIssues(ctx context.Context, feedback *google.FeedbackData) {
+	initIssueReporter()
+	if issueReporter == nil {
+		return
+	}
+
+	report := &github.Report{
+		Categories:      splitSetupIssues(feedback.SetupIssues),
+		SubmittedAt:     feedback.SubmittedAt,
+		Helpfulness:     knownOption(google.HelpfulnessOptions, feedback.Helpfulness),
+		SetupDifficulty: feedback.SetupDifficulty,
+		DocsQuality:     knownOption(google.DocsQualityOptions, feedback.DocsQuality),
+		Source:          feedback.Source,
+	}
+	if len(report.Categories) == 0 {
+		return
+	}
+	// Issues are public, so comments are only quoted with consent.
+	if feedback.QuoteConsent {
+		report.Comment = feedback.AdditionalFeedback
+	}
+
+	outcomes, err := issueReporter.Report(ctx, report)
+	for _, outcome := range outcomes {
+		logging.Infof("Reported %s from submission %s on GitHub issue #%d", outcome.Category, feedback.ID, outcome.Issue)
+	}
+	if err != nil {
+		if github.IsRateLimited(err) {
+			logging.Warning("GitHub issue reporting rate limited", logging.Fields{"submission_id": feedback.ID})
+			return
+		}
+		logging.Errorf("Failed to report setup issues for submission %s: %v", feedback.ID, err)
+	}
+}
+
+// knownOption returns value when it is one of the form's options, "" when
+// it was not answered, and "other" otherwise, so that arbitrary input is not
+// published on GitHub.
+func knownOption(options []string, value string) string {
+	if value == "" || slices.Contains(options, value) {
+		return value
+	}
+	return "other"
+}
+
+// splitSetupIssues splits the comma separated setup issues of a submission.
+func splitSetupIssues(value string) []string {
+	var issues []string
+	for _, issue := range strings.Split(value, ",") {
+		if issue = strings.TrimSpace(issue); issue != "" {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github/githubtest"
+)
+
+func setupIssueReporter(t *testing.T) *githubtest.Server {
+	t.Helper()
+
+	srv := githubtest.NewServer("token")
+	t.Cleanup(srv.Close)
+
+	client, err := github.NewClient(&github.Config{BaseURL: srv.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetIssueReporter(github.NewReporter(client, 0))
+	t.Cleanup(func() { SetIssueReporter(nil) })
+	return srv
+}
+
+func TestHandleFeedback_ReportsSetupIssues(t *testing.T) {
+	setupAdmin(t)
+	srv := setupIssueReporter(t)
+
+	submitFeedbackForm(t, url.Values{
+		"helpfulness":        {"not-helpful"},
+		"setupIssues":        {"port-conflicts", "other"},
+		"additionalFeedback": {"Port 8765 clashes with my proxy"},
+		"email":              {"me@example.com"},
+		"consentContact":     {"true"},
+	})
+	submitFeedbackForm(t, url.Values{
+		"helpfulness":        {"somewhat-helpful"},
+		"setupIssues":        {"port-conflicts"},
+		"additionalFeedback": {"Quote me: change the default port"},
+		"consentQuote":       {"true"},
+	})
+
+	issues := srv.Issues(github.DefaultRepository)
+	if len(issues) != 1 || issues[0].Title != "Setup feedback: Port conflicts" {
+		t.Fatalf("Expected a single port conflicts issue, got %+v", issues)
+	}
+	comments := issues[0].Comments
+	if len(comments) != 2 {
+		t.Fatalf("Expected a comment per submission, got %d", len(comments))
+	}
+	if strings.Contains(comments[0], "8765") || strings.Contains(comments[0], "me@example.com") {
+		t.Errorf("Expected no free text or email without quote consent, got %q", comments[0])
+	}
+	if !strings.Contains(comments[1], "```text\nQuote me: change the default port\n```") {
+		t.Errorf("Expected consented feedback to be quoted, got %q", comments[1])
+	}
+}
+
+func TestHandleFeedback_ReportsUnknownAnswersAsOther(t *testing.T) {
+	setupAdmin(t)
+	srv := setupIssueReporter(t)
+
+	submitFeedbackForm(t, url.Values{
+		"helpfulness": {"@benidevo look"},
+		"docsQuality": {"[link](https://evil.example)"},
+		"setupIssues": {"port-conflicts"},
+	})
+
+	comment := srv.Issues(github.DefaultRepository)[0].Comments[0]
+	if !strings.Contains(comment, "| other | 5/10 | other |") {
+		t.Errorf("Expected unknown answers to be reported as other, got %q", comment)
+	}
+}
+
+func TestHandleFeedback_NoIssueWithoutSetupIssues(t *testing.T) {
+	setupAdmin(t)
+	srv := setupIssueReporter(t)
+
+	submitFeedbackForm(t, url.Values{"helpfulness": {"very-helpful"}, "setupIssues": {"other"}})
+
+	if srv.Requests() != 0 {
+		t.Errorf("Expected no GitHub requests, got %d", srv.Requests())
+	}
+}
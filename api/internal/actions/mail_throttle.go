@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// maxMailThrottleKeys bounds the addresses and clients a mailThrottle
+// remembers. Beyond it no email is sent until entries expire.
+const maxMailThrottleKeys = 10_000
+
+// mailThrottle limits emails that anyone can cause to be sent to an address
+// they enter, so that the forms cannot be used to flood inboxes or to spend
+// the sender's reputation. It remembers when an email was last sent per
+// address hash and per client, and optionally caps all emails sent per
+// hour. It is kept in memory, so the limits apply per instance.
+type mailThrottle struct {
+	addressInterval time.Duration
+	clientInterval  time.Duration
+	// maxPerHour caps the emails sent in any hour, whatever the addresses
+	// and clients. Zero means no cap.
+	maxPerHour int
+
+	mu    sync.Mutex
+	sent  map[string]time.Time
+	sends []time.Time
+}
+
+// allow reports whether an email can be sent to address for client, and if
+// so records it as sent.
+func (t *mailThrottle) allow(now time.Time, address, client string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, expires := range t.sent {
+		if !now.Before(expires) {
+			delete(t.sent, key)
+		}
+	}
+	if t.sent == nil {
+		t.sent = map[string]time.Time{}
+	}
+	for len(t.sends) > 0 && now.Sub(t.sends[0]) >= time.Hour {
+		t.sends = t.sends[1:]
+	}
+
+	sum := sha256.Sum256([]byte(google.NormalizeEmail(address)))
+	addressKey := "address:" + hex.EncodeToString(sum[:])
+	clientKey := "client:" + client
+	if _, ok := t.sent[addressKey]; ok {
+		return false
+	}
+	if _, ok := t.sent[clientKey]; ok && client != "" {
+		return false
+	}
+	if len(t.sent) >= maxMailThrottleKeys-1 {
+		return false
+	}
+	if t.maxPerHour > 0 && len(t.sends) >= t.maxPerHour {
+		return false
+	}
+
+	t.sent[addressKey] = now.Add(t.addressInterval)
+	if client != "" {
+		t.sent[clientKey] = now.Add(t.clientInterval)
+	}
+	if t.maxPerHour > 0 {
+		t.sends = append(t.sends, now)
+	}
+	return true
+}
+
+func (t *mailThrottle) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent = nil
+	t.sends = nil
+}
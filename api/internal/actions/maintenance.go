@@ -0,0 +1,152 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+// maintenanceTimeout bounds a maintenance run, which may rewrite every row.
+// It stays under the 300 second function timeout set in
+// .github/workflows/deploy.yml, so that a run is cancelled, and its failure
+// reported, before the platform stops the instance mid-write.
+const maintenanceTimeout = 4 * time.Minute
+
+// RotateKeysResponse reports the outcome of a key rotation run.
+type RotateKeysResponse struct {
+	Success bool                      `json:"success"`
+	Result  *google.KeyRotationResult `json:"result"`
+}
+
+// HandleRotateKeys re-encrypts stored emails with the current field
+// protection keys. It is meant to be called by a scheduler after a new key
+// is made primary, and needs the maintenance scope.
+func HandleRotateKeys(w http.ResponseWriter, r *http.Request) {
+	if !requirePrincipalScope(w, r, auth.ScopeMaintenance) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for key rotation endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Key rotation requested but Google Sheets service is not available")
+		http.Error(w, "Feedback storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), maintenanceTimeout)
+	defer cancel()
+
+	result, err := sheetsService.RotateFieldKeys(ctx)
+	if err != nil {
+		logging.Errorf("Failed to rotate field keys: %v", err)
+		http.Error(w, "Failed to rotate keys", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, RotateKeysResponse{Success: true, Result: result})
+}
+
+// CompactCountsResponse reports the outcome of a compaction run.
+type CompactCountsResponse struct {
+	Success bool                     `json:"success"`
+	Result  *google.CompactionResult `json:"result"`
+}
+
+// HandleCompactCounts merges the download and event counts of past days into
+// one row per day and key. It is meant to be called daily by a scheduler,
+// never by two callers at once, and needs the maintenance scope.
+func HandleCompactCounts(w http.ResponseWriter, r *http.Request) {
+	if !requirePrincipalScope(w, r, auth.ScopeMaintenance) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for compact counts endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Count compaction requested but Google Sheets service is not available")
+		http.Error(w, "Count storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), maintenanceTimeout)
+	defer cancel()
+
+	result, err := sheetsService.CompactCounts(ctx)
+	if err != nil {
+		logging.Errorf("Failed to compact counts: %v", err)
+		http.Error(w, "Failed to compact counts", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, CompactCountsResponse{Success: true, Result: result})
+}
+
+// RetentionResponse reports the outcome of a retention run.
+type RetentionResponse struct {
+	Success bool                    `json:"success"`
+	Report  *google.RetentionReport `json:"report"`
+}
+
+// HandleRetention enforces the retention rules configured through
+// RETENTION_EMAIL_DAYS, RETENTION_ROW_DAYS, RETENTION_PENDING_DAYS and
+// RETENTION_LOG_DAYS. With dryRun=true it only reports what would be removed.
+// It is meant to be called by a scheduler and needs the maintenance scope.
+func HandleRetention(w http.ResponseWriter, r *http.Request) {
+	if !requirePrincipalScope(w, r, auth.ScopeMaintenance) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for retention endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dryRun := formBool(r.URL.Query().Get("dryRun"))
+
+	policy, err := google.RetentionPolicyFromEnv()
+	if err != nil {
+		logging.Errorf("Invalid retention configuration: %v", err)
+		http.Error(w, "Invalid retention configuration", http.StatusInternalServerError)
+		return
+	}
+	if policy.IsZero() {
+		logging.Errorf("Retention requested but no retention rules are configured")
+		http.Error(w, "Retention rules not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Retention requested but Google Sheets service is not available")
+		http.Error(w, "Feedback storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), maintenanceTimeout)
+	defer cancel()
+
+	report, err := sheetsService.ApplyRetention(ctx, policy, dryRun)
+	if err != nil {
+		logging.Errorf("Failed to apply retention: %v", err)
+		http.Error(w, "Failed to apply retention", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, RetentionResponse{Success: true, Report: report})
+}
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+)
+
+func TestHandleRotateKeys(t *testing.T) {
+	stub := setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleRotateKeys(w, adminRequest("POST", "/", "", auth.ScopeMaintenance))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if stub.rotations != 1 {
+		t.Errorf("expected one rotation, got %d", stub.rotations)
+	}
+
+	var response RotateKeysResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Success || response.Result.Scanned != 1 {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestHandleCompactCounts(t *testing.T) {
+	stub := setupAdmin(t)
+
+	w := httptest.NewRecorder()
+	HandleCompactCounts(w, adminRequest("POST", "/", "", auth.ScopeMaintenance))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response CompactCountsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Success || response.Result.DownloadRows != 1 || stub.compactions != 1 {
+		t.Errorf("unexpected response %+v after %d runs", response, stub.compactions)
+	}
+
+	for _, req := range []*http.Request{
+		adminRequest("POST", "/", ""),
+		adminRequest("GET", "/", "", auth.ScopeMaintenance),
+	} {
+		w := httptest.NewRecorder()
+		HandleCompactCounts(w, req)
+		if w.Code == http.StatusOK {
+			t.Errorf("expected %s without the right scope or method to be rejected", req.Method)
+		}
+	}
+	if stub.compactions != 1 {
+		t.Errorf("expected rejected requests not to compact, got %d runs", stub.compactions)
+	}
+}
+
+func TestHandleRotateKeys_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"missing scope", adminRequest("POST", "/", ""), http.StatusForbidden},
+		{"wrong method", adminRequest("GET", "/", "", auth.ScopeMaintenance), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := setupAdmin(t)
+
+			w := httptest.NewRecorder()
+			HandleRotateKeys(w, tt.req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if stub.rotations != 0 {
+				t.Error("expected no rotation")
+			}
+		})
+	}
+}
+
+func TestHandleRetention(t *testing.T) {
+	t.Setenv("RETENTION_EMAIL_DAYS", "180")
+	t.Setenv("RETENTION_ROW_DAYS", "730")
+
+	tests := []struct {
+		name   string
+		target string
+		dryRun bool
+	}{
+		{"purge", "/", false},
+		{"dry run", "/?dryRun=true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := setupAdmin(t)
+
+			w := httptest.NewRecorder()
+			HandleRetention(w, adminRequest("POST", tt.target, "", auth.ScopeMaintenance))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if len(stub.retention) != 1 || stub.retention[0] != tt.dryRun {
+				t.Errorf("expected one run with dry run %v, got %v", tt.dryRun, stub.retention)
+			}
+
+			var response RetentionResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Report.DryRun != tt.dryRun || len(response.Report.RowsDeleted) != 1 {
+				t.Errorf("unexpected report %+v", response.Report)
+			}
+		})
+	}
+}
+
+func TestHandleRetention_Rejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		emailDays string
+		req       *http.Request
+		status    int
+	}{
+		{"missing scope", "180", adminRequest("POST", "/", ""), http.StatusForbidden},
+		{"wrong method", "180", adminRequest("GET", "/", "", auth.ScopeMaintenance), http.StatusMethodNotAllowed},
+		{"no rules", "", adminRequest("POST", "/", "", auth.ScopeMaintenance), http.StatusServiceUnavailable},
+		{"invalid rules", "soon", adminRequest("POST", "/", "", auth.ScopeMaintenance), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RETENTION_EMAIL_DAYS", tt.emailDays)
+			t.Setenv("RETENTION_ROW_DAYS", "")
+			stub := setupAdmin(t)
+
+			w := httptest.NewRecorder()
+			HandleRetention(w, tt.req)
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if len(stub.retention) != 0 {
+				t.Error("expected retention not to run")
+			}
+		})
+	}
+}
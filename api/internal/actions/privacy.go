@@ -0,0 +1,464 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	mailer "github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/tokens"
+)
+
+// privacyTokenTTL is how long a verification link stays valid.
+const privacyTokenTTL = 24 * time.Hour
+
+// PrivacyRequest starts a data subject request with Email, or confirms one
+// with the Token from the verification link.
+type PrivacyRequest struct {
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+// PrivacyExportResponse returns everything stored for a verified address.
+type PrivacyExportResponse struct {
+	Success     bool            `json:"success"`
+	RequestID   string          `json:"requestId"`
+	Submissions []AdminFeedback `json:"submissions"`
+	// Subscription is set when the address subscribed to announcements.
+	Subscription *PrivacySubscription `json:"subscription,omitempty"`
+}
+
+// PrivacySubscription is the subscriber entry stored for an address.
+type PrivacySubscription struct {
+	ID                   string   `json:"id"`
+	Email                string   `json:"email"`
+	Topics               []string `json:"topics"`
+	Status               string   `json:"status"`
+	CreatedAt            string   `json:"createdAt,omitempty"`
+	RequestedAt          string   `json:"requestedAt,omitempty"`
+	ConfirmedAt          string   `json:"confirmedAt,omitempty"`
+	UnsubscribedAt       string   `json:"unsubscribedAt,omitempty"`
+	PrivacyPolicyVersion string   `json:"privacyPolicyVersion,omitempty"`
+}
+
+// PrivacyEraseResponse reports a completed erasure.
+type PrivacyEraseResponse struct {
+	Success   bool   `json:"success"`
+	RequestID string `json:"requestId"`
+	Erased    int    `json:"erased"`
+	// SubscriptionErased is set when a subscriber entry was deleted.
+	SubscriptionErased bool   `json:"subscriptionErased"`
+	Message            string `json:"message"`
+}
+
+var (
+	emailSender     mailer.Mailer
+	emailSenderOnce sync.Once
+
+	signer     *tokens.Signer
+	signerOnce sync.Once
+)
+
+// initEmailSender configures outgoing email from the environment once.
+func initEmailSender() {
+	emailSenderOnce.Do(func() {
+		m, err := mailer.NewMailerFromEnv()
+		if err != nil {
+			logging.Warningf("Email not configured: %v", err)
+			return
+		}
+		emailSender = m
+	})
+}
+
+// SetMailer replaces the mailer used by the handlers. It is intended for
+// tests; passing nil disables email.
+func SetMailer(m mailer.Mailer) {
+	emailSenderOnce.Do(func() {})
+	emailSender = m
+}
+
+// initSigner loads the link signing secret from SIGNING_SECRET once.
+func initSigner() {
+	signerOnce.Do(func() {
+		s, err := tokens.NewSigner([]byte(os.Getenv("SIGNING_SECRET")))
+		if err != nil {
+			logging.Warningf("Signed links not configured: %v", err)
+			return
+		}
+		signer = s
+	})
+}
+
+// SetSigner replaces the token signer used by the handlers. It is intended
+// for tests; passing nil disables signed links.
+func SetSigner(s *tokens.Signer) {
+	signerOnce.Do(func() {})
+	signer = s
+}
+
+// HandlePrivacyExport lets someone download the feedback stored under their
+// email address once they have confirmed they own it.
+func HandlePrivacyExport(w http.ResponseWriter, r *http.Request) {
+	handlePrivacy(w, r, google.PrivacyRequestExport)
+}
+
+// HandlePrivacyErase lets someone remove their email address and free text
+// from stored feedback once they have confirmed they own the address.
+// Comments already quoted in public GitHub issues, with the submitter's
+// consent, are not removed from GitHub; the confirmation page says so.
+func HandlePrivacyErase(w http.ResponseWriter, r *http.Request) {
+	handlePrivacy(w, r, google.PrivacyRequestErase)
+}
+
+// handlePrivacy runs both steps of a data subject request. A POST with an
+// email sends a verification link; following the link with its token
+// completes the request. Erasure asks for confirmation on GET so that link
+// scanners cannot trigger it.
+func handlePrivacy(w http.ResponseWriter, r *http.Request, requestType string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for privacy endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := PrivacyRequest{Token: r.URL.Query().Get("token")}
+	isForm := false
+	if r.Method == http.MethodPost {
+		var body PrivacyRequest
+		if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				logging.Errorf("Failed to decode privacy request: %v", err)
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+		} else {
+			if err := r.ParseForm(); err != nil {
+				logging.Errorf("Failed to parse privacy form data: %v", err)
+				http.Error(w, "Invalid form data", http.StatusBadRequest)
+				return
+			}
+			body = PrivacyRequest{Email: r.PostFormValue("email"), Token: r.PostFormValue("token")}
+			isForm = true
+		}
+		req.Email = body.Email
+		if body.Token != "" {
+			req.Token = body.Token
+		}
+	}
+
+	initSheetsService()
+	initSigner()
+	if sheetsService == nil || signer == nil {
+		logging.Errorf("Privacy request received but storage or signing is not configured")
+		http.Error(w, "Privacy requests not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if req.Token == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+		startPrivacyRequest(ctx, w, r, requestType, req.Email)
+		return
+	}
+
+	claims, err := signer.Verify(req.Token, privacyPurpose(requestType))
+	if errors.Is(err, tokens.ErrExpiredToken) {
+		http.Error(w, "This link has expired, please make a new request", http.StatusGone)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Rejected privacy token: %v", err)
+		http.Error(w, "Invalid link", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case requestType == google.PrivacyRequestExport:
+		completePrivacyExport(ctx, w, claims)
+	case r.Method == http.MethodGet:
+		renderPrivacyPage(w, confirmErasePage, map[string]string{"Action": r.URL.RequestURI(), "Token": req.Token})
+	default:
+		completePrivacyErase(ctx, w, claims, isForm)
+	}
+}
+
+// startPrivacyRequest emails a verification link when feedback or a
+// subscriber is stored for the address. The response is the same either way
+// so that it does not reveal whether an address is known.
+func startPrivacyRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, requestType, email string) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Address != strings.TrimSpace(email) {
+		http.Error(w, "A valid email address is required", http.StatusBadRequest)
+		return
+	}
+
+	initEmailSender()
+	if emailSender == nil {
+		logging.Errorf("Privacy request received but email is not configured")
+		http.Error(w, "Privacy requests not available", http.StatusServiceUnavailable)
+		return
+	}
+	if _, err := publicAPIURL(); err != nil {
+		logging.Errorf("Privacy request received but links cannot be built: %v", err)
+		http.Error(w, "Privacy requests not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	matches, err := sheetsService.FindFeedbackByEmail(ctx, address.Address)
+	if err != nil {
+		logging.Errorf("Failed to look up feedback for privacy request: %v", err)
+		http.Error(w, "Failed to process request", http.StatusBadGateway)
+		return
+	}
+	subscriber, err := findSubscription(ctx, address.Address)
+	if err != nil {
+		logging.Errorf("Failed to look up subscriber for privacy request: %v", err)
+		http.Error(w, "Failed to process request", http.StatusBadGateway)
+		return
+	}
+
+	token, claims, err := signer.Sign(privacyPurpose(requestType), google.NormalizeEmail(address.Address), privacyTokenTTL)
+	if err != nil {
+		logging.Errorf("Failed to sign privacy token: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(matches) > 0 || subscriber != nil {
+		link, err := signedLink(privacyPurpose(requestType), token)
+		if err != nil {
+			logging.Errorf("Failed to build privacy link for request %s: %v", claims.ID, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		msg := &mailer.Message{
+			To:      address.Address,
+			Subject: "Confirm your Vega AI data request",
+			Text:    privacyEmailText(requestType, link),
+		}
+		if err := emailSender.Send(ctx, msg); err != nil {
+			logging.Errorf("Failed to send privacy verification email for request %s: %v", claims.ID, err)
+		}
+	}
+
+	recordPrivacyRequest(ctx, &google.PrivacyRecord{
+		RequestID:   claims.ID,
+		Type:        requestType,
+		Stage:       google.PrivacyStageRequested,
+		Email:       address.Address,
+		Submissions: len(matches),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, FeedbackResponse{
+		Success: true,
+		Message: "If we hold feedback or a subscription for this address, we have emailed it a verification link. The link expires in 24 hours.",
+	})
+}
+
+func completePrivacyExport(ctx context.Context, w http.ResponseWriter, claims *tokens.Claims) {
+	matches, err := sheetsService.FindFeedbackByEmail(ctx, claims.Subject)
+	if err != nil {
+		logging.Errorf("Failed to export feedback for privacy request %s: %v", claims.ID, err)
+		http.Error(w, "Failed to export feedback", http.StatusBadGateway)
+		return
+	}
+	subscriber, err := findSubscription(ctx, claims.Subject)
+	if err != nil {
+		logging.Errorf("Failed to export subscriber for privacy request %s: %v", claims.ID, err)
+		http.Error(w, "Failed to export feedback", http.StatusBadGateway)
+		return
+	}
+
+	recordPrivacyRequest(ctx, &google.PrivacyRecord{
+		RequestID:   claims.ID,
+		Type:        google.PrivacyRequestExport,
+		Stage:       google.PrivacyStageCompleted,
+		Email:       claims.Subject,
+		Submissions: len(matches),
+	})
+
+	response := PrivacyExportResponse{
+		Success:     true,
+		RequestID:   claims.ID,
+		Submissions: make([]AdminFeedback, 0, len(matches)),
+	}
+	for _, item := range matches {
+		response.Submissions = append(response.Submissions, toAdminFeedback(item))
+	}
+	if subscriber != nil {
+		response.Subscription = &PrivacySubscription{
+			ID:                   subscriber.ID,
+			Email:                subscriber.Email,
+			Topics:               subscriber.Topics,
+			Status:               subscriber.Status,
+			CreatedAt:            formatTime(subscriber.CreatedAt),
+			RequestedAt:          formatTime(subscriber.RequestedAt),
+			ConfirmedAt:          formatTime(subscriber.ConfirmedAt),
+			UnsubscribedAt:       formatTime(subscriber.UnsubscribedAt),
+			PrivacyPolicyVersion: subscriber.PrivacyPolicyVersion,
+		}
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="vega-feedback-data.json"`)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, response)
+}
+
+func completePrivacyErase(ctx context.Context, w http.ResponseWriter, claims *tokens.Claims, isForm bool) {
+	erased, err := sheetsService.EraseFeedbackByEmail(ctx, claims.Subject)
+	if err != nil {
+		logging.Errorf("Failed to erase feedback for privacy request %s: %v", claims.ID, err)
+		http.Error(w, "Failed to erase feedback", http.StatusBadGateway)
+		return
+	}
+	unsubscribed, err := sheetsService.EraseSubscriber(ctx, claims.Subject)
+	if err != nil {
+		logging.Errorf("Failed to erase subscriber for privacy request %s: %v", claims.ID, err)
+		http.Error(w, "Failed to erase feedback", http.StatusBadGateway)
+		return
+	}
+
+	recordPrivacyRequest(ctx, &google.PrivacyRecord{
+		RequestID:   claims.ID,
+		Type:        google.PrivacyRequestErase,
+		Stage:       google.PrivacyStageCompleted,
+		Email:       claims.Subject,
+		Submissions: erased,
+	})
+
+	message := fmt.Sprintf("Your email address and comments were removed from %d feedback submissions.", erased)
+	if unsubscribed {
+		message += " Your subscription to announcements was deleted."
+	}
+	if isForm {
+		renderPrivacyPage(w, erasedPage, map[string]string{"Message": message})
+		return
+	}
+	writeJSON(w, PrivacyEraseResponse{Success: true, RequestID: claims.ID, Erased: erased, SubscriptionErased: unsubscribed, Message: message})
+}
+
+// findSubscription returns the subscriber stored for an address, or nil
+// when there is none.
+func findSubscription(ctx context.Context, email string) (*google.Subscriber, error) {
+	subscriber, err := sheetsService.FindSubscriber(ctx, email)
+	if errors.Is(err, google.ErrSubscriberNotFound) {
+		return nil, nil
+	}
+	return subscriber, err
+}
+
+// recordPrivacyRequest logs a data subject request. Failing to record it
+// does not fail the request itself.
+func recordPrivacyRequest(ctx context.Context, record *google.PrivacyRecord) {
+	if err := sheetsService.RecordPrivacyRequest(ctx, record); err != nil {
+		logging.Errorf("Failed to record privacy request %s: %v", record.RequestID, err)
+		return
+	}
+	logging.Infof("Privacy %s request %s %s", record.Type, record.RequestID, record.Stage)
+}
+
+func privacyPurpose(requestType string) string {
+	return "privacy/" + requestType
+}
+
+// publicAPIURL returns PUBLIC_API_URL, the base URL of the API used in
+// emailed links. Links are never built from request headers such as Host,
+// which the caller controls and could point at their own server.
+func publicAPIURL() (string, error) {
+	base := os.Getenv("PUBLIC_API_URL")
+	if base == "" {
+		return "", fmt.Errorf("PUBLIC_API_URL is not set")
+	}
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.RawQuery != "" {
+		return "", fmt.Errorf("invalid PUBLIC_API_URL %q", base)
+	}
+	return base, nil
+}
+
+// signedLink builds a link to an action carrying a signed token.
+func signedLink(action, token string) (string, error) {
+	base, err := publicAPIURL()
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{"action": {action}, "token": {token}}
+	return base + "?" + query.Encode(), nil
+}
+
+func privacyEmailText(requestType, link string) string {
+	what := "download a copy of"
+	if requestType == google.PrivacyRequestErase {
+		what = "erase your email address and comments from"
+	}
+	return fmt.Sprintf(`Hello,
+
+Someone, hopefully you, asked to %s the feedback you sent about Vega AI
+and your subscription to its announcements, if any.
+
+To confirm, open this link within 24 hours:
+
+%s
+
+If you did not ask for this, ignore this email and nothing will change.
+`, what, link)
+}
+
+var (
+	confirmErasePage = template.Must(template.New("confirm").Parse(privacyPageHead + `
+<h1>Erase your feedback data</h1>
+<p>This removes your email address and written comments from the feedback you sent about Vega AI, and deletes any subscription to announcements. Anonymous ratings are kept. Comments you agreed to have quoted in public GitHub issues stay on GitHub; ask the maintainers to remove them there. This cannot be undone.</p>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Erase my data</button>
+</form>
+</body>
+</html>
+`))
+
+	erasedPage = template.Must(template.New("erased").Parse(privacyPageHead + `
+<h1>Done</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+)
+
+const privacyPageHead = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Vega AI feedback data</title>
+</head>
+<body style="font-family: sans-serif; max-width: 36rem; margin: 4rem auto; padding: 0 1rem; line-height: 1.5">`
+
+func renderPrivacyPage(w http.ResponseWriter, page *template.Template, data map[string]string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	if err := page.Execute(w, data); err != nil {
+		logging.Errorf("Failed to render privacy page: %v", err)
+	}
+}
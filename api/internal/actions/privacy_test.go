@@ -0,0 +1,274 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	mailer "github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/tokens"
+)
+
+// recordingMailer keeps sent messages instead of delivering them.
+type recordingMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func setupPrivacy(t *testing.T) (*stubSheetsService, *recordingMailer, *tokens.Signer) {
+	t.Helper()
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com/")
+
+	stub := setupAdmin(t)
+	subscribeConfirmations.reset()
+	t.Cleanup(subscribeConfirmations.reset)
+	stub.items = append(stub.items, &google.FeedbackData{
+		ID:                 "mine",
+		Helpfulness:        "very-helpful",
+		AdditionalFeedback: "Loved it",
+		Email:              "Me@Example.com",
+	})
+
+	m := &recordingMailer{}
+	SetMailer(m)
+	t.Cleanup(func() { SetMailer(nil) })
+
+	s, err := tokens.NewSigner([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	SetSigner(s)
+	t.Cleanup(func() { SetSigner(nil) })
+
+	return stub, m, s
+}
+
+var tokenPattern = regexp.MustCompile(`https://api\.example\.com/\?\S+`)
+
+// linkFromEmail returns the verification link in the last sent email.
+func linkFromEmail(t *testing.T, m *recordingMailer) *url.URL {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("expected a verification email")
+	}
+	link, err := url.Parse(tokenPattern.FindString(m.sent[len(m.sent)-1].Text))
+	if err != nil {
+		t.Fatalf("invalid link: %v", err)
+	}
+	return link
+}
+
+func postPrivacy(handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestHandlePrivacyExport_Flow(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+
+	w := postPrivacy(HandlePrivacyExport, `{"email":"me@example.com"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if m.sent[0].To != "me@example.com" {
+		t.Errorf("expected email to the requester, got %q", m.sent[0].To)
+	}
+
+	link := linkFromEmail(t, m)
+	if link.Query().Get("action") != "privacy/export" {
+		t.Errorf("unexpected link %s", link)
+	}
+
+	w = httptest.NewRecorder()
+	HandlePrivacyExport(w, httptest.NewRequest("GET", link.RequestURI(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response PrivacyExportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(response.Submissions) != 1 || response.Submissions[0].ID != "mine" {
+		t.Errorf("unexpected submissions %+v", response.Submissions)
+	}
+
+	if len(stub.privacyRecords) != 2 {
+		t.Fatalf("expected requested and completed records, got %+v", stub.privacyRecords)
+	}
+	for _, record := range stub.privacyRecords {
+		if record.RequestID != response.RequestID || record.Email != "me@example.com" {
+			t.Errorf("unexpected record %+v", record)
+		}
+	}
+	if stub.privacyRecords[1].Stage != google.PrivacyStageCompleted || stub.privacyRecords[1].Submissions != 1 {
+		t.Errorf("unexpected completion record %+v", stub.privacyRecords[1])
+	}
+}
+
+func TestHandlePrivacyErase_Flow(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+
+	postPrivacy(HandlePrivacyErase, `{"email":"me@example.com"}`)
+	link := linkFromEmail(t, m)
+
+	// Following the link only asks for confirmation.
+	w := httptest.NewRecorder()
+	HandlePrivacyErase(w, httptest.NewRequest("GET", link.RequestURI(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<form method="post"`) {
+		t.Fatalf("expected confirmation page, got %d: %s", w.Code, w.Body.String())
+	}
+	if stub.items[1].Email == "" {
+		t.Fatal("expected GET not to erase anything")
+	}
+
+	form := url.Values{"token": {link.Query().Get("token")}}
+	req := httptest.NewRequest("POST", "/?action=privacy/erase", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	HandlePrivacyErase(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "removed from 1 feedback") {
+		t.Fatalf("expected erasure confirmation, got %d: %s", w.Code, w.Body.String())
+	}
+	if stub.items[1].Email != "" || stub.items[1].AdditionalFeedback != google.ErasedMarker {
+		t.Errorf("expected submission to be erased, got %+v", stub.items[1])
+	}
+}
+
+func TestHandlePrivacy_CoversSubscription(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+	if err := stub.SaveSubscriber(context.Background(), &google.Subscriber{
+		Email:  "sub@example.com",
+		Topics: []string{google.TopicReleases},
+		Status: google.SubscriberConfirmed,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An address that only subscribed is still sent a link.
+	postPrivacy(HandlePrivacyExport, `{"email":"sub@example.com"}`)
+	link := linkFromEmail(t, m)
+	w := httptest.NewRecorder()
+	HandlePrivacyExport(w, httptest.NewRequest("GET", link.RequestURI(), nil))
+
+	var export PrivacyExportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(export.Submissions) != 0 || export.Subscription == nil || export.Subscription.Email != "sub@example.com" ||
+		export.Subscription.Status != google.SubscriberConfirmed {
+		t.Fatalf("expected the subscription to be exported, got %+v", export)
+	}
+
+	postPrivacy(HandlePrivacyErase, `{"email":"sub@example.com"}`)
+	link = linkFromEmail(t, m)
+	w = postPrivacy(HandlePrivacyErase, `{"token":"`+link.Query().Get("token")+`"}`)
+
+	var erase PrivacyEraseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &erase); err != nil {
+		t.Fatalf("invalid response: %v, %s", err, w.Body.String())
+	}
+	if !erase.SubscriptionErased || !strings.Contains(erase.Message, "subscription") {
+		t.Errorf("expected the subscription to be erased, got %+v", erase)
+	}
+	if _, err := stub.FindSubscriber(context.Background(), "sub@example.com"); err == nil {
+		t.Error("expected the subscriber to be deleted")
+	}
+}
+
+func TestHandlePrivacy_UnknownEmailSendsNothing(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+
+	w := postPrivacy(HandlePrivacyErase, `{"email":"stranger@example.com"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected the same response as for known addresses, got %d", w.Code)
+	}
+	if len(m.sent) != 0 {
+		t.Error("expected no email for an address without feedback")
+	}
+	if len(stub.privacyRecords) != 1 || stub.privacyRecords[0].Submissions != 0 {
+		t.Errorf("expected the request to be recorded, got %+v", stub.privacyRecords)
+	}
+}
+
+func TestHandlePrivacy_RejectsBadRequests(t *testing.T) {
+	_, _, s := setupPrivacy(t)
+
+	exportToken, _, _ := s.Sign("privacy/export", "me@example.com", time.Hour)
+	expired, _, _ := s.Sign("privacy/erase", "me@example.com", -time.Minute)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+		want    int
+	}{
+		{"invalid email", HandlePrivacyExport, "POST", "/", `{"email":"not an email"}`, http.StatusBadRequest},
+		{"display name", HandlePrivacyExport, "POST", "/", `{"email":"Me <me@example.com>"}`, http.StatusBadRequest},
+		{"GET without token", HandlePrivacyExport, "GET", "/", "", http.StatusBadRequest},
+		{"tampered token", HandlePrivacyExport, "GET", "/?token=" + exportToken + "x", "", http.StatusBadRequest},
+		{"token for another action", HandlePrivacyErase, "POST", "/", `{"token":"` + exportToken + `"}`, http.StatusBadRequest},
+		{"expired token", HandlePrivacyErase, "POST", "/", `{"token":"` + expired + `"}`, http.StatusGone},
+		{"method", HandlePrivacyErase, "DELETE", "/", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			tt.handler(w, req)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlePrivacyExport_RequiresPublicURL(t *testing.T) {
+	_, m, _ := setupPrivacy(t)
+	t.Setenv("PUBLIC_API_URL", "")
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"email":"me@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Host = "attacker.example"
+	w := httptest.NewRecorder()
+	HandlePrivacyExport(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+	if len(m.sent) != 0 {
+		t.Errorf("expected no email without a configured link base, got %+v", m.sent)
+	}
+}
+
+func TestHandlePrivacyExport_IgnoresHost(t *testing.T) {
+	_, m, _ := setupPrivacy(t)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"email":"me@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Host = "attacker.example"
+	HandlePrivacyExport(httptest.NewRecorder(), req)
+
+	if len(m.sent) != 1 || strings.Contains(m.sent[0].Text, "attacker.example") || linkFromEmail(t, m).Host != "api.example.com" {
+		t.Errorf("expected the link to use PUBLIC_API_URL, got %+v", m.sent)
+	}
+}
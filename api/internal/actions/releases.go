@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+)
+
+// ReleasesResponse holds the latest Vega and extension releases. A release
+// that could not be looked up is left out.
+type ReleasesResponse struct {
+	Success   bool                   `json:"success"`
+	Vega      *github.ReleaseSummary `json:"vega,omitempty"`
+	Extension *github.ReleaseSummary `json:"extension,omitempty"`
+}
+
+var (
+	releaseCache     *github.ReleaseCache
+	releaseCacheOnce sync.Once
+)
+
+// initReleaseCache configures release lookups from the environment once.
+func initReleaseCache() {
+	releaseCacheOnce.Do(func() {
+		cache, err := github.NewReleaseCacheFromEnv()
+		if err != nil {
+			logging.Warningf("Release lookups not configured: %v", err)
+			return
+		}
+		releaseCache = cache
+	})
+}
+
+// SetReleaseCache replaces the release cache used by the handlers. It is
+// intended for tests; passing nil disables release lookups.
+func SetReleaseCache(cache *github.ReleaseCache) {
+	releaseCacheOnce.Do(func() {})
+	releaseCache = cache
+}
+
+// HandleReleases returns the version, date, notes summary and download
+// links of the latest Vega and browser extension releases.
+func HandleReleases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logging.Errorf("Invalid method %s for releases endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	initReleaseCache()
+	if releaseCache == nil {
+		http.Error(w, "Releases not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	vegaRepository, extensionRepository := github.ReleaseRepositories()
+	response := ReleasesResponse{Success: true}
+	var wg sync.WaitGroup
+	for repository, target := range map[string]**github.ReleaseSummary{
+		vegaRepository:      &response.Vega,
+		extensionRepository: &response.Extension,
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := releaseCache.Latest(ctx, repository)
+			if err != nil {
+				logging.Errorf("Failed to look up the latest release of %s: %v", repository, err)
+				return
+			}
+			*target = release
+		}()
+	}
+	wg.Wait()
+
+	if response.Vega == nil && response.Extension == nil {
+		http.Error(w, "Failed to look up releases", http.StatusBadGateway)
+		return
+	}
+
+	// Browsers and CDNs may reuse the answer briefly; the cache behind it
+	// already bounds calls to GitHub.
+	w.Header().Set("Cache-Control", "public, max-age=300, stale-while-revalidate=3600")
+	writeJSON(w, response)
+}
@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github/githubtest"
+)
+
+func setupReleases(t *testing.T) *githubtest.Server {
+	t.Helper()
+	t.Setenv("GITHUB_VEGA_REPOSITORY", "")
+	t.Setenv("GITHUB_EXTENSION_REPOSITORY", "")
+
+	srv := githubtest.NewServer("token")
+	t.Cleanup(srv.Close)
+
+	client, err := github.NewReadOnlyClient(&github.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache, err := github.NewReleaseCache(&github.ReleaseCacheConfig{Client: client})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetReleaseCache(cache)
+	t.Cleanup(func() { SetReleaseCache(nil) })
+	return srv
+}
+
+func TestHandleReleases(t *testing.T) {
+	srv := setupReleases(t)
+	srv.AddRelease(github.DefaultVegaRepository, githubtest.Release{
+		TagName:     "v2.0.0",
+		Body:        "Multi-user support.",
+		PublishedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	w := httptest.NewRecorder()
+	HandleReleases(w, httptest.NewRequest("GET", "/?action=releases", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response ReleasesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if response.Vega == nil || response.Vega.Version != "v2.0.0" || response.Vega.Notes != "Multi-user support." {
+		t.Errorf("unexpected Vega release %+v", response.Vega)
+	}
+	if response.Extension != nil {
+		t.Errorf("expected the missing extension release to be left out, got %+v", response.Extension)
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Error("expected a Cache-Control header")
+	}
+
+	// Repeated requests are answered from the cache.
+	requests := srv.Requests()
+	HandleReleases(httptest.NewRecorder(), httptest.NewRequest("GET", "/?action=releases", nil))
+	if srv.Requests() != requests+1 {
+		t.Errorf("expected only the uncached extension lookup to reach GitHub, got %d requests", srv.Requests()-requests)
+	}
+}
+
+func TestHandleReleases_Errors(t *testing.T) {
+	setupReleases(t)
+
+	w := httptest.NewRecorder()
+	HandleReleases(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502 without releases, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleReleases(w, httptest.NewRequest("POST", "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/setup"
+)
+
+// maxSetupBody caps setup request bodies.
+const maxSetupBody = 64 << 10
+
+// SetupGenerateResponse holds the generated setup files.
+type SetupGenerateResponse struct {
+	Success bool `json:"success"`
+	*setup.Result
+}
+
+// HandleSetupGenerate turns the choices made in the setup form into a
+// config file, docker run command and docker-compose file. Requests may
+// carry the Gemini API key and admin password; they are only written into
+// the response, which is never cached, and are never stored or logged.
+func HandleSetupGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for setup generate endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSetupBody)
+
+	var options setup.Options
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		// Decoding errors can quote the input, so they are not logged.
+		if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+			logging.Errorf("Failed to decode setup generate request")
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			logging.Errorf("Failed to parse setup generate form data")
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if options, err = setupOptionsFromForm(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := setup.Generate(options)
+	var fieldErr *setup.FieldError
+	if errors.As(err, &fieldErr) {
+		http.Error(w, fieldErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to generate setup: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logging.Info("Generated setup files", logging.Fields{"count": len(result.Placeholders)})
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, SetupGenerateResponse{Success: true, Result: result})
+}
+
+// SetupValidateResponse holds the diagnostics for a config file.
+type SetupValidateResponse struct {
+	Success bool `json:"success"`
+	*setup.Validation
+}
+
+// setupValidateRequest is the JSON form of a validate request.
+type setupValidateRequest struct {
+	Config string `json:"config"`
+}
+
+// HandleSetupValidate checks the contents of a Vega config file and returns
+// line-level diagnostics. The file may be posted as plain text, as JSON
+// {"config": "..."} or as the config form field. It is not stored or
+// logged, and secret values are masked in the echoed config.
+func HandleSetupValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for setup validate endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSetupBody)
+
+	var content string
+	switch contentType := r.Header.Get("Content-Type"); {
+	case strings.Contains(contentType, "application/json"):
+		var request setupValidateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			logging.Errorf("Failed to decode setup validate request")
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		content = request.Config
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"), strings.Contains(contentType, "multipart/form-data"):
+		if err := r.ParseForm(); err != nil {
+			logging.Errorf("Failed to parse setup validate form data")
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		content = r.PostFormValue("config")
+	default:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logging.Errorf("Failed to read setup validate request")
+			http.Error(w, "Config file too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		content = string(body)
+	}
+
+	validation, err := setup.ValidateConfig(content)
+	var fieldErr *setup.FieldError
+	if errors.As(err, &fieldErr) {
+		http.Error(w, fieldErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to validate config: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	status := "valid"
+	if !validation.Valid {
+		status = "invalid"
+	}
+	logging.Info("Validated config file", logging.Fields{"status": status, "count": len(validation.Diagnostics)})
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, SetupValidateResponse{Success: true, Validation: validation})
+}
+
+func setupOptionsFromForm(r *http.Request) (setup.Options, error) {
+	options := setup.Options{
+		VolumeName:    r.PostFormValue("volumeName"),
+		ContainerName: r.PostFormValue("containerName"),
+		ImageTag:      r.PostFormValue("imageTag"),
+		RestartPolicy: r.PostFormValue("restartPolicy"),
+		AdminUsername: r.PostFormValue("adminUsername"),
+		Timezone:      r.PostFormValue("timezone"),
+		GeminiAPIKey:  r.PostFormValue("geminiApiKey"),
+		AdminPassword: r.PostFormValue("adminPassword"),
+	}
+	if value := r.PostFormValue("port"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return options, &setup.FieldError{Field: "port", Message: "must be a number from 1 to 65535"}
+		}
+		options.Port = port
+	}
+	if value := r.PostFormValue("createAdminUser"); value != "" {
+		create, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, &setup.FieldError{Field: "createAdminUser", Message: "must be true or false"}
+		}
+		options.CreateAdminUser = &create
+	}
+	return options, nil
+}
@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHandleSetupGenerate(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	key := "AIza" + strings.Repeat("s", 35)
+	body := `{"port":9000,"adminUsername":"vega_admin","geminiApiKey":"` + key + `","adminPassword":"correct-horse"}`
+	req := httptest.NewRequest("POST", "/?action=setup/generate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	HandleSetupGenerate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Success      bool     `json:"success"`
+		Config       string   `json:"config"`
+		DockerRun    string   `json:"dockerRun"`
+		Compose      string   `json:"compose"`
+		Placeholders []string `json:"placeholders"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if !response.Success || !strings.Contains(response.Config, "GEMINI_API_KEY="+key+"\n") || !strings.Contains(response.Config, "ADMIN_USERNAME=vega_admin\n") {
+		t.Errorf("unexpected config %q", response.Config)
+	}
+	if !strings.Contains(response.DockerRun, "-p 9000:8765") || response.Compose == "" || len(response.Placeholders) != 0 {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Error("expected the response not to be cached")
+	}
+	if strings.Contains(logs.String(), key) || strings.Contains(logs.String(), "correct-horse") {
+		t.Errorf("expected secrets to stay out of the logs, got %s", logs.String())
+	}
+}
+
+func TestHandleSetupGenerate_Form(t *testing.T) {
+	form := url.Values{"createAdminUser": {"false"}, "restartPolicy": {"always"}}
+	w := postForm(HandleSetupGenerate, form)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "ADMIN_USERNAME") || !strings.Contains(w.Body.String(), "--restart always") {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleSetupGenerate_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"method", "GET", "", http.StatusMethodNotAllowed},
+		{"JSON", "POST", `{"port":`, http.StatusBadRequest},
+		{"port", "POST", `{"port":0.5}`, http.StatusBadRequest},
+		{"volume", "POST", `{"volumeName":"../etc"}`, http.StatusBadRequest},
+		{"password", "POST", `{"adminPassword":"abc"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			HandleSetupGenerate(w, req)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "abc") {
+				t.Error("expected the error not to echo the password")
+			}
+		})
+	}
+}
+
+func TestHandleSetupValidate(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	config := "GEMINI_API_KEY=AIza" + strings.Repeat("k", 35) + "\r\nADMIN_PASSWORD=\"correct-horse\"\r\n"
+	tests := map[string]*http.Request{
+		"text": httptest.NewRequest("POST", "/?action=setup/validate", strings.NewReader(config)),
+		"json": httptest.NewRequest("POST", "/?action=setup/validate", strings.NewReader(`{"config":`+strconv.Quote(config)+`}`)),
+		"form": httptest.NewRequest("POST", "/?action=setup/validate", strings.NewReader(url.Values{"config": {config}}.Encode())),
+	}
+	tests["json"].Header.Set("Content-Type", "application/json")
+	tests["form"].Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HandleSetupValidate(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response struct {
+				Success     bool             `json:"success"`
+				Valid       bool             `json:"valid"`
+				Diagnostics []map[string]any `json:"diagnostics"`
+				Config      string           `json:"config"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if !response.Success || !response.Valid || len(response.Diagnostics) != 2 {
+				t.Errorf("unexpected response %s", w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "correct-horse") || strings.Contains(w.Body.String(), "kkkk") {
+				t.Errorf("expected secrets to be masked, got %s", w.Body.String())
+			}
+			if w.Header().Get("Cache-Control") != "no-store" {
+				t.Error("expected the response not to be cached")
+			}
+		})
+	}
+	if strings.Contains(logs.String(), "correct-horse") {
+		t.Errorf("expected the config to stay out of the logs, got %s", logs.String())
+	}
+}
+
+func TestHandleSetupValidate_Invalid(t *testing.T) {
+	w := httptest.NewRecorder()
+	HandleSetupValidate(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSetupValidate(w, httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("#\n", maxSetupBody))))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleSetupValidate(w, httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("#\n", 600))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,303 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+const (
+	// defaultStatsCacheTTL is how long computed statistics are served before
+	// the sheet is read again.
+	defaultStatsCacheTTL = 15 * time.Minute
+	// defaultStatsMinBucket is the smallest count published for any bucket.
+	defaultStatsMinBucket = 5
+	// topSetupIssues is how many setup issues are listed.
+	topSetupIssues = 5
+)
+
+// StatsBucket is the size of one answer group.
+type StatsBucket struct {
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// SetupIssueStat counts how often a setup issue was reported.
+type SetupIssueStat struct {
+	Issue   string  `json:"issue"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// DifficultyStats summarises the reported setup difficulty from 1 to 10.
+type DifficultyStats struct {
+	Responses int      `json:"responses"`
+	Mean      *float64 `json:"mean,omitempty"`
+	Median    *float64 `json:"median,omitempty"`
+}
+
+// StatsResponse holds public aggregates over stored feedback. Buckets with
+// fewer than MinBucketSize responses are left out so that individual
+// responses cannot be singled out. When any bucket is left out, the total is
+// rounded up to a multiple of MinBucketSize, and percentages are of that
+// rounded total, so the hidden counts cannot be worked out by subtraction.
+type StatsResponse struct {
+	Success        bool                   `json:"success"`
+	GeneratedAt    string                 `json:"generatedAt"`
+	MinBucketSize  int                    `json:"minBucketSize"`
+	TotalResponses int                    `json:"totalResponses"`
+	TotalRounded   bool                   `json:"totalRounded,omitempty"`
+	HelpfulPercent *float64               `json:"helpfulPercent,omitempty"`
+	Helpfulness    map[string]StatsBucket `json:"helpfulness"`
+	DocsQuality    map[string]StatsBucket `json:"docsQuality"`
+	Difficulty     DifficultyStats        `json:"setupDifficulty"`
+	TopSetupIssues []SetupIssueStat       `json:"topSetupIssues"`
+}
+
+// statsCache keeps the last computed statistics.
+type statsCache struct {
+	mu        sync.Mutex
+	stats     *StatsResponse
+	expiresAt time.Time
+}
+
+var cachedStats statsCache
+
+func (c *statsCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = nil
+	c.expiresAt = time.Time{}
+}
+
+// HandleStats serves cached aggregate statistics for the landing page.
+func HandleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logging.Errorf("Invalid method %s for stats endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	initSheetsService()
+	if sheetsService == nil {
+		logging.Errorf("Stats requested but Google Sheets service is not available")
+		http.Error(w, "Feedback storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ttl := statsCacheTTL()
+
+	cachedStats.mu.Lock()
+	defer cachedStats.mu.Unlock()
+
+	now := time.Now()
+	if cachedStats.stats == nil || !now.Before(cachedStats.expiresAt) {
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		stats, err := computeStats(ctx, sheetsService, statsMinBucket(), now)
+		switch {
+		case err == nil:
+			cachedStats.stats = stats
+			cachedStats.expiresAt = now.Add(ttl)
+		case cachedStats.stats != nil:
+			// Stale numbers are better than none on a public page.
+			logging.Warningf("Failed to refresh stats, serving cached copy: %v", err)
+		default:
+			logging.Errorf("Failed to compute stats: %v", err)
+			http.Error(w, "Failed to compute stats", http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ttl.Seconds())))
+	writeJSON(w, cachedStats.stats)
+}
+
+// computeStats reads the answers of every stored submission and aggregates
+// them.
+func computeStats(ctx context.Context, service google.SheetsService, minBucket int, now time.Time) (*StatsResponse, error) {
+	answers, err := service.FeedbackAnswers(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateStats(answers, minBucket, now), nil
+}
+
+func aggregateStats(answers []google.FeedbackAnswers, minBucket int, now time.Time) *StatsResponse {
+	stats := &StatsResponse{
+		Success:        true,
+		GeneratedAt:    now.UTC().Format(time.RFC3339),
+		MinBucketSize:  minBucket,
+		TotalResponses: len(answers),
+		Helpfulness:    map[string]StatsBucket{},
+		DocsQuality:    map[string]StatsBucket{},
+		TopSetupIssues: []SetupIssueStat{},
+	}
+
+	helpfulness := map[string]int{}
+	docsQuality := map[string]int{}
+	issues := map[string]int{}
+	var difficulties []int
+
+	for _, a := range answers {
+		helpfulness[a.Helpfulness]++
+		if a.DocsQuality != "" {
+			docsQuality[a.DocsQuality]++
+		}
+		if a.SetupDifficulty >= 1 && a.SetupDifficulty <= 10 {
+			difficulties = append(difficulties, a.SetupDifficulty)
+		}
+		// Only the form's fixed options are counted, so free text typed
+		// into the field is never published.
+		for _, issue := range strings.Split(a.SetupIssues, ",") {
+			if issue = strings.TrimSpace(issue); slices.Contains(google.SetupIssueOptions, issue) {
+				issues[issue]++
+			}
+		}
+	}
+
+	if stats.TotalResponses < minBucket {
+		// Every bucket is suppressed.
+		stats.TotalRounded = stats.TotalResponses > 0
+		stats.TotalResponses = roundUp(stats.TotalResponses, minBucket)
+		return stats
+	}
+
+	// Every response has a helpfulness answer, so any response missing from
+	// the published helpfulness buckets was suppressed.
+	published := 0
+	for _, option := range google.HelpfulnessOptions {
+		if helpfulness[option] >= minBucket {
+			published += helpfulness[option]
+		}
+	}
+	withheld := published != stats.TotalResponses
+	for _, counts := range []map[string]int{docsQuality, issues} {
+		for _, count := range counts {
+			withheld = withheld || suppressed(count, minBucket)
+		}
+	}
+	if withheld {
+		stats.TotalResponses, stats.TotalRounded = roundUp(stats.TotalResponses, minBucket), true
+	}
+
+	bucket := func(count int) StatsBucket {
+		return StatsBucket{Count: count, Percent: percent(count, stats.TotalResponses)}
+	}
+
+	for _, option := range google.HelpfulnessOptions {
+		if helpfulness[option] >= minBucket {
+			stats.Helpfulness[option] = bucket(helpfulness[option])
+		}
+	}
+	// The helpful share would give away a suppressed bucket behind it, so it
+	// is only published when neither bucket is suppressed.
+	if !suppressed(helpfulness["very-helpful"], minBucket) && !suppressed(helpfulness["somewhat-helpful"], minBucket) {
+		helpful := percent(helpfulness["very-helpful"]+helpfulness["somewhat-helpful"], stats.TotalResponses)
+		stats.HelpfulPercent = &helpful
+	}
+
+	for _, option := range google.DocsQualityOptions {
+		if docsQuality[option] >= minBucket {
+			stats.DocsQuality[option] = bucket(docsQuality[option])
+		}
+	}
+
+	if len(difficulties) >= minBucket {
+		stats.Difficulty.Responses = len(difficulties)
+		mean, median := meanAndMedian(difficulties)
+		stats.Difficulty.Mean = &mean
+		stats.Difficulty.Median = &median
+	}
+
+	for issue, count := range issues {
+		if count >= minBucket {
+			stats.TopSetupIssues = append(stats.TopSetupIssues, SetupIssueStat{
+				Issue:   issue,
+				Count:   count,
+				Percent: percent(count, stats.TotalResponses),
+			})
+		}
+	}
+	sort.Slice(stats.TopSetupIssues, func(i, j int) bool {
+		a, b := stats.TopSetupIssues[i], stats.TopSetupIssues[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Issue < b.Issue
+	})
+	if len(stats.TopSetupIssues) > topSetupIssues {
+		stats.TopSetupIssues = stats.TopSetupIssues[:topSetupIssues]
+	}
+
+	return stats
+}
+
+// roundUp rounds n up to a multiple of step.
+func roundUp(n, step int) int {
+	return (n + step - 1) / step * step
+}
+
+// suppressed reports whether a count is too small to publish. Empty buckets
+// single nobody out and are not suppressed.
+func suppressed(count, minBucket int) bool {
+	return count > 0 && count < minBucket
+}
+
+func meanAndMedian(values []int) (float64, float64) {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	sum := 0
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := float64(sum) / float64(len(sorted))
+
+	mid := len(sorted) / 2
+	median := float64(sorted[mid])
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+
+	return round1(mean), median
+}
+
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round1(float64(count) * 100 / float64(total))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// statsCacheTTL reads STATS_CACHE_TTL, a Go duration such as "10m".
+func statsCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("STATS_CACHE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultStatsCacheTTL
+}
+
+// statsMinBucket reads STATS_MIN_BUCKET_SIZE.
+func statsMinBucket() int {
+	if size, err := strconv.Atoi(os.Getenv("STATS_MIN_BUCKET_SIZE")); err == nil && size > 0 {
+		return size
+	}
+	return defaultStatsMinBucket
+}
@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+func answersFixture(helpfulness []string, difficulties []int, issues []string) []google.FeedbackAnswers {
+	items := make([]google.FeedbackAnswers, len(helpfulness))
+	for i := range items {
+		items[i] = google.FeedbackAnswers{Helpfulness: helpfulness[i]}
+		if i < len(difficulties) {
+			items[i].SetupDifficulty = difficulties[i]
+		}
+		if i < len(issues) {
+			items[i].SetupIssues = issues[i]
+		}
+		items[i].DocsQuality = "yes-clear"
+	}
+	return items
+}
+
+func TestAggregateStats(t *testing.T) {
+	helpfulness := []string{"very-helpful", "very-helpful", "very-helpful", "somewhat-helpful", "somewhat-helpful", "not-helpful"}
+	difficulties := []int{2, 4, 3, 9, 1, 0}
+	issues := []string{
+		"docker-installation, port-conflicts",
+		"docker-installation",
+		"docker-installation, my secret setup",
+		"port-conflicts",
+		"",
+		"",
+	}
+
+	stats := aggregateStats(answersFixture(helpfulness, difficulties, issues), 2, time.Now())
+
+	if stats.TotalResponses != 6 || !stats.TotalRounded {
+		t.Errorf("expected 6 responses rounded to a multiple of 2, got %d", stats.TotalResponses)
+	}
+	if stats.HelpfulPercent == nil || *stats.HelpfulPercent != 83.3 {
+		t.Errorf("expected 83.3%% helpful, got %v", stats.HelpfulPercent)
+	}
+	if _, ok := stats.Helpfulness["not-helpful"]; ok {
+		t.Error("expected single not-helpful response to be suppressed")
+	}
+	if got := stats.Helpfulness["very-helpful"]; got.Count != 3 || got.Percent != 50 {
+		t.Errorf("unexpected very-helpful bucket %+v", got)
+	}
+	if stats.DocsQuality["yes-clear"].Count != 6 {
+		t.Errorf("unexpected docs quality %+v", stats.DocsQuality)
+	}
+
+	if stats.Difficulty.Responses != 5 || *stats.Difficulty.Mean != 3.8 || *stats.Difficulty.Median != 3 {
+		t.Errorf("unexpected difficulty %+v", stats.Difficulty)
+	}
+
+	want := []SetupIssueStat{{"docker-installation", 3, 50}, {"port-conflicts", 2, 33.3}}
+	if len(stats.TopSetupIssues) != len(want) {
+		t.Fatalf("expected %v, got %v", want, stats.TopSetupIssues)
+	}
+	for i := range want {
+		if stats.TopSetupIssues[i] != want[i] {
+			t.Errorf("issue %d: expected %+v, got %+v", i, want[i], stats.TopSetupIssues[i])
+		}
+	}
+}
+
+func TestAggregateStats_BelowMinimum(t *testing.T) {
+	stats := aggregateStats(answersFixture([]string{"very-helpful", "not-helpful"}, []int{3, 8}, nil), 5, time.Now())
+
+	if stats.HelpfulPercent != nil || len(stats.Helpfulness) != 0 || stats.Difficulty.Mean != nil {
+		t.Errorf("expected all aggregates to be suppressed, got %+v", stats)
+	}
+	if stats.TotalResponses != 5 || !stats.TotalRounded {
+		t.Errorf("expected the total to be rounded up, got %d", stats.TotalResponses)
+	}
+}
+
+func TestAggregateStats_TotalHidesSuppressedBuckets(t *testing.T) {
+	helpfulness := []string{"very-helpful", "very-helpful", "very-helpful", "very-helpful", "very-helpful", "very-helpful", "not-helpful"}
+	stats := aggregateStats(answersFixture(helpfulness, nil, nil), 5, time.Now())
+
+	// The exact total would give away the single not-helpful response.
+	if stats.TotalResponses != 10 || !stats.TotalRounded {
+		t.Errorf("expected the total to be rounded up to 10, got %d", stats.TotalResponses)
+	}
+	if got := stats.Helpfulness["very-helpful"]; got.Count != 6 || got.Percent != 60 {
+		t.Errorf("expected the percentage of the rounded total, got %+v", got)
+	}
+
+	stats = aggregateStats(answersFixture(helpfulness[:6], nil, nil), 5, time.Now())
+	if stats.TotalResponses != 6 || stats.TotalRounded {
+		t.Errorf("expected the exact total when nothing is suppressed, got %d", stats.TotalResponses)
+	}
+}
+
+func TestAggregateStats_DerivedValuesFollowSuppression(t *testing.T) {
+	helpfulness := []string{"very-helpful", "very-helpful", "very-helpful", "somewhat-helpful", "not-helpful", "not-helpful"}
+	stats := aggregateStats(answersFixture(helpfulness, []int{4}, nil), 3, time.Now())
+
+	if _, ok := stats.Helpfulness["somewhat-helpful"]; ok {
+		t.Fatal("expected the single somewhat-helpful response to be suppressed")
+	}
+	if stats.HelpfulPercent != nil {
+		t.Errorf("expected the helpful share to be withheld, got %v", *stats.HelpfulPercent)
+	}
+	if stats.Difficulty.Responses != 0 || stats.Difficulty.Mean != nil {
+		t.Errorf("expected difficulty to be suppressed, got %+v", stats.Difficulty)
+	}
+
+	stats = aggregateStats(answersFixture([]string{"very-helpful", "very-helpful", "very-helpful", "very-helpful", "very-helpful", "very-helpful", "not-helpful", "not-helpful"}, nil, nil), 2, time.Now())
+	if stats.HelpfulPercent == nil || *stats.HelpfulPercent != 75 {
+		t.Errorf("expected an empty bucket not to withhold the helpful share, got %v", stats.HelpfulPercent)
+	}
+}
+
+type countingSheetsService struct {
+	stubSheetsService
+	answers []google.FeedbackAnswers
+	reads   int
+	err     error
+}
+
+func (c *countingSheetsService) FeedbackAnswers(ctx context.Context, to time.Time) ([]google.FeedbackAnswers, error) {
+	c.reads++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.answers, nil
+}
+
+func TestHandleStats_Cached(t *testing.T) {
+	t.Setenv("STATS_MIN_BUCKET_SIZE", "1")
+
+	service := &countingSheetsService{}
+	service.answers = answersFixture([]string{"very-helpful", "not-helpful"}, []int{3, 8}, nil)
+	SetSheetsService(service)
+	t.Cleanup(func() { SetSheetsService(nil) })
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		HandleStats(w, httptest.NewRequest("GET", "/stats", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var stats StatsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if stats.TotalResponses != 2 || *stats.Difficulty.Mean != 5.5 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+		if w.Header().Get("Cache-Control") == "" {
+			t.Error("expected Cache-Control header")
+		}
+	}
+
+	if service.reads != 1 {
+		t.Errorf("expected stats to be computed once, got %d reads", service.reads)
+	}
+
+	// An expired cache is still served when storage fails.
+	cachedStats.expiresAt = time.Time{}
+	service.err = errors.New("quota exceeded")
+	w := httptest.NewRecorder()
+	HandleStats(w, httptest.NewRequest("GET", "/stats", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected stale stats to be served, got %d", w.Code)
+	}
+}
+
+func TestHandleStats_Errors(t *testing.T) {
+	service := &countingSheetsService{err: errors.New("unavailable")}
+	SetSheetsService(service)
+	t.Cleanup(func() { SetSheetsService(nil) })
+
+	w := httptest.NewRecorder()
+	HandleStats(w, httptest.NewRequest("GET", "/stats", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleStats(w, httptest.NewRequest("POST", "/stats", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+}
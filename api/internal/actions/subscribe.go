@@ -0,0 +1,473 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	mailer "github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/tokens"
+)
+
+// Token purposes of subscription links. They match the actions the links
+// point to.
+const (
+	subscribeConfirmPurpose = "subscribe/confirm"
+	unsubscribePurpose      = "unsubscribe"
+)
+
+const (
+	// subscribeTokenTTL is how long a confirmation link stays valid.
+	subscribeTokenTTL = 48 * time.Hour
+	// unsubscribeTokenTTL is how long the unsubscribe link in a welcome
+	// email stays valid.
+	unsubscribeTokenTTL = 365 * 24 * time.Hour
+	// subscribeResendInterval stops repeated requests for a pending address
+	// from sending more than one confirmation email in that time. Anyone can
+	// enter any address, so it is long enough that the form cannot be used
+	// to flood an inbox.
+	subscribeResendInterval = 24 * time.Hour
+	// subscribeClientInterval is how often one client can cause a
+	// confirmation email to be sent, whatever addresses it enters.
+	subscribeClientInterval = 10 * time.Minute
+	// maxSubscribeEmailsPerHour caps the confirmation emails an instance
+	// sends in an hour, so that requests from many clients cannot spend the
+	// sender's reputation either.
+	maxSubscribeEmailsPerHour = 30
+)
+
+// subscribeConfirmations throttles confirmation emails per address, per
+// client and overall.
+var subscribeConfirmations = mailThrottle{
+	addressInterval: subscribeResendInterval,
+	clientInterval:  subscribeClientInterval,
+	maxPerHour:      maxSubscribeEmailsPerHour,
+}
+
+// SubscribeRequest asks for release announcements to be sent to Email.
+type SubscribeRequest struct {
+	Email string `json:"email"`
+	// Topics defaults to releases only.
+	Topics []string `json:"topics"`
+	// PrivacyPolicyVersion is the privacy policy version shown with the form.
+	PrivacyPolicyVersion string `json:"privacyPolicyVersion"`
+}
+
+// subscribeAccepted is the response to every valid subscribe request, so
+// that it does not reveal whether an address is already subscribed.
+const subscribeAccepted = "Please check your inbox: unless this address is already subscribed, we have emailed it a link to confirm your subscription. The link expires in 48 hours."
+
+// HandleSubscribe starts a double opt-in subscription. New and unsubscribed
+// addresses are stored as pending and sent a confirmation link; pending
+// addresses get their topics updated and the link sent again once a day.
+// Confirmed subscribers are left unchanged. Confirmation emails are
+// throttled per address, per client and overall.
+func HandleSubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for subscribe endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SubscribeRequest
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logging.Errorf("Failed to decode subscribe request: %v", err)
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			logging.Errorf("Failed to parse subscribe form data: %v", err)
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		req = SubscribeRequest{
+			Email:                r.PostFormValue("email"),
+			Topics:               r.PostForm["topics"],
+			PrivacyPolicyVersion: r.PostFormValue("privacyPolicyVersion"),
+		}
+	}
+
+	email := strings.TrimSpace(req.Email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		http.Error(w, "A valid email address is required", http.StatusBadRequest)
+		return
+	}
+
+	topics, ok := parseTopics(req.Topics)
+	if !ok {
+		http.Error(w, "Unknown topic, choose from: "+strings.Join(google.Topics, ", "), http.StatusBadRequest)
+		return
+	}
+
+	initSheetsService()
+	initSigner()
+	initEmailSender()
+	if sheetsService == nil || signer == nil || emailSender == nil {
+		logging.Errorf("Subscribe request received but storage, signing or email is not configured")
+		http.Error(w, "Subscriptions not available", http.StatusServiceUnavailable)
+		return
+	}
+	if _, err := publicAPIURL(); err != nil {
+		logging.Errorf("Subscribe request received but links cannot be built: %v", err)
+		http.Error(w, "Subscriptions not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	subscriber, err := sheetsService.FindSubscriber(ctx, email)
+	if errors.Is(err, google.ErrSubscriberNotFound) {
+		subscriber, err = &google.Subscriber{}, nil
+	}
+	if err != nil {
+		logging.Errorf("Failed to look up subscriber: %v", err)
+		http.Error(w, "Failed to process request", http.StatusBadGateway)
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case subscriber.Status == google.SubscriberConfirmed:
+		logging.Info("Ignored subscribe request", logging.Fields{"status": subscriber.Status})
+	case subscriber.Status == google.SubscriberPending && now.Sub(subscriber.RequestedAt) < subscribeResendInterval:
+		logging.Info("Ignored repeated subscribe request", logging.Fields{"status": subscriber.Status})
+	case !subscribeConfirmations.allow(now, email, logging.ClientIP(r)):
+		// The response stays the same, so that throttling does not reveal
+		// anything about the address.
+		logging.Warning("Subscribe request throttled", logging.Fields{"status": subscriber.Status})
+	default:
+		subscriber.Email = email
+		subscriber.Topics = topics
+		subscriber.Status = google.SubscriberPending
+		subscriber.RequestedAt = now
+		subscriber.PrivacyPolicyVersion = req.PrivacyPolicyVersion
+		if err := sheetsService.SaveSubscriber(ctx, subscriber); err != nil {
+			logging.Errorf("Failed to store subscriber: %v", err)
+			http.Error(w, "Failed to process request", http.StatusBadGateway)
+			return
+		}
+		sendSubscriptionEmail(ctx, subscribeConfirmation, subscribeConfirmPurpose, subscribeTokenTTL, subscriber)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, FeedbackResponse{Success: true, Message: subscribeAccepted})
+}
+
+// HandleSubscribeConfirm completes a subscription from the link in the
+// confirmation email. GET asks for confirmation so that link scanners cannot
+// subscribe an address on its owner's behalf.
+func HandleSubscribeConfirm(w http.ResponseWriter, r *http.Request) {
+	token, isForm, ok := subscriptionToken(w, r)
+	if !ok {
+		return
+	}
+	claims, ok := verifySubscriptionToken(w, token, subscribeConfirmPurpose)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		renderPrivacyPage(w, confirmSubscriptionPage, map[string]string{"Action": r.URL.RequestURI(), "Token": token})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	subscriber, err := sheetsService.FindSubscriber(ctx, claims.Subject)
+	if errors.Is(err, google.ErrSubscriberNotFound) || (err == nil && subscriber.Status == google.SubscriberUnsubscribed) {
+		http.Error(w, "This link is no longer valid, please subscribe again", http.StatusGone)
+		return
+	}
+	if err != nil {
+		logging.Errorf("Failed to look up subscriber: %v", err)
+		http.Error(w, "Failed to confirm subscription", http.StatusBadGateway)
+		return
+	}
+
+	if subscriber.Status == google.SubscriberPending {
+		subscriber.Status = google.SubscriberConfirmed
+		subscriber.ConfirmedAt = time.Now()
+		if err := sheetsService.SaveSubscriber(ctx, subscriber); err != nil {
+			logging.Errorf("Failed to confirm subscriber %s: %v", subscriber.ID, err)
+			http.Error(w, "Failed to confirm subscription", http.StatusBadGateway)
+			return
+		}
+		logging.Infof("Subscriber %s confirmed", subscriber.ID)
+		sendSubscriptionEmail(ctx, subscribeWelcome, unsubscribePurpose, unsubscribeTokenTTL, subscriber)
+	}
+
+	respondSubscription(w, isForm, "Your subscription is confirmed. We will email you about "+topicNames(subscriber.Topics)+".")
+}
+
+// HandleUnsubscribe removes a subscriber from the link in any subscription
+// email. The address is cleared and only its hash is kept, so that the
+// subscriber can start again later without duplicating the entry.
+func HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token, isForm, ok := subscriptionToken(w, r)
+	if !ok {
+		return
+	}
+	claims, ok := verifySubscriptionToken(w, token, unsubscribePurpose)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		renderPrivacyPage(w, confirmUnsubscribePage, map[string]string{"Action": r.URL.RequestURI(), "Token": token})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	subscriber, err := sheetsService.FindSubscriber(ctx, claims.Subject)
+	if err != nil && !errors.Is(err, google.ErrSubscriberNotFound) {
+		logging.Errorf("Failed to look up subscriber: %v", err)
+		http.Error(w, "Failed to unsubscribe", http.StatusBadGateway)
+		return
+	}
+
+	if err == nil && subscriber.Status != google.SubscriberUnsubscribed {
+		subscriber.Status = google.SubscriberUnsubscribed
+		subscriber.Email = ""
+		subscriber.UnsubscribedAt = time.Now()
+		if err := sheetsService.SaveSubscriber(ctx, subscriber); err != nil {
+			logging.Errorf("Failed to unsubscribe subscriber %s: %v", subscriber.ID, err)
+			http.Error(w, "Failed to unsubscribe", http.StatusBadGateway)
+			return
+		}
+		logging.Infof("Subscriber %s unsubscribed", subscriber.ID)
+	}
+
+	respondSubscription(w, isForm, "You have been unsubscribed and your email address was removed. You will not hear from us again.")
+}
+
+// subscriptionToken reads the token of a confirm or unsubscribe link from
+// the query string or a posted form or JSON body.
+func subscriptionToken(w http.ResponseWriter, r *http.Request) (string, bool, bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		logging.Errorf("Invalid method %s for subscription endpoint", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return "", false, false
+	}
+
+	token, isForm := r.URL.Query().Get("token"), false
+	if r.Method == http.MethodPost {
+		if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+			var body struct {
+				Token string `json:"token"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				logging.Errorf("Failed to decode subscription request: %v", err)
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return "", false, false
+			}
+			if body.Token != "" {
+				token = body.Token
+			}
+		} else {
+			if err := r.ParseForm(); err != nil {
+				logging.Errorf("Failed to parse subscription form data: %v", err)
+				http.Error(w, "Invalid form data", http.StatusBadRequest)
+				return "", false, false
+			}
+			if value := r.PostFormValue("token"); value != "" {
+				token = value
+			}
+			isForm = true
+		}
+	}
+
+	if token == "" {
+		http.Error(w, "Token is required", http.StatusBadRequest)
+		return "", false, false
+	}
+	return token, isForm, true
+}
+
+// verifySubscriptionToken checks a link token and that subscriptions are
+// configured, writing the error response when either fails.
+func verifySubscriptionToken(w http.ResponseWriter, token, purpose string) (*tokens.Claims, bool) {
+	initSheetsService()
+	initSigner()
+	if sheetsService == nil || signer == nil {
+		logging.Errorf("Subscription link followed but storage or signing is not configured")
+		http.Error(w, "Subscriptions not available", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	claims, err := signer.Verify(token, purpose)
+	if errors.Is(err, tokens.ErrExpiredToken) {
+		http.Error(w, "This link has expired, please subscribe again", http.StatusGone)
+		return nil, false
+	}
+	if err != nil {
+		logging.Errorf("Rejected subscription token: %v", err)
+		http.Error(w, "Invalid link", http.StatusBadRequest)
+		return nil, false
+	}
+	return claims, true
+}
+
+func respondSubscription(w http.ResponseWriter, isForm bool, message string) {
+	if isForm {
+		renderPrivacyPage(w, subscriptionDonePage, map[string]string{"Message": message})
+		return
+	}
+	writeJSON(w, FeedbackResponse{Success: true, Message: message})
+}
+
+// parseTopics validates requested topics, dropping duplicates. No topics
+// means releases only.
+func parseTopics(requested []string) ([]string, bool) {
+	var topics []string
+	for _, topic := range requested {
+		topic = strings.TrimSpace(topic)
+		if !google.IsValidTopic(topic) {
+			return nil, false
+		}
+		if !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		topics = []string{google.TopicReleases}
+	}
+	return topics, true
+}
+
+// topicNames describes topics for people.
+func topicNames(topics []string) string {
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		switch topic {
+		case google.TopicReleases:
+			names = append(names, "new Vega AI releases")
+		case google.TopicExtensionUpdates:
+			names = append(names, "browser extension updates")
+		}
+	}
+	return strings.Join(names, " and ")
+}
+
+// subscriptionEmailData is the data available to the subscription email
+// templates.
+type subscriptionEmailData struct {
+	Topics string
+	Link   string
+}
+
+// sendSubscriptionEmail emails a subscriber a link signed for purpose.
+// Failures are logged; the subscriber can ask again.
+func sendSubscriptionEmail(ctx context.Context, tmpl *mailer.Template, purpose string, ttl time.Duration, subscriber *google.Subscriber) {
+	token, _, err := signer.Sign(purpose, google.NormalizeEmail(subscriber.Email), ttl)
+	if err != nil {
+		logging.Errorf("Failed to sign subscription token: %v", err)
+		return
+	}
+
+	link, err := signedLink(purpose, token)
+	if err != nil {
+		logging.Errorf("Failed to build subscription link: %v", err)
+		return
+	}
+	data := &subscriptionEmailData{Topics: topicNames(subscriber.Topics), Link: link}
+	msg, err := tmpl.Render(subscriber.Email, data)
+	if err != nil {
+		logging.Errorf("Failed to render subscription email: %v", err)
+		return
+	}
+	if err := emailSender.Send(ctx, msg); err != nil {
+		logging.Errorf("Failed to send subscription email to subscriber %s: %v", subscriber.ID, err)
+	}
+}
+
+var subscribeConfirmation = mailer.MustTemplate("subscribe-confirmation",
+	`Confirm your Vega AI subscription`,
+	`Hello,
+
+Someone, hopefully you, asked to receive emails about {{.Topics}} at this
+address.
+
+To confirm, open this link within 48 hours:
+
+{{.Link}}
+
+If you did not ask for this, ignore this email and you will not hear from us.
+`,
+	`<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: system-ui, sans-serif; line-height: 1.5; color: #1f2937;">
+<p>Hello,</p>
+<p>Someone, hopefully you, asked to receive emails about {{.Topics}} at this address.</p>
+<p><a href="{{.Link}}">Confirm your subscription</a> within 48 hours.</p>
+<p>If you did not ask for this, ignore this email and you will not hear from us.</p>
+</body>
+</html>
+`)
+
+var subscribeWelcome = mailer.MustTemplate("subscribe-welcome",
+	`You are subscribed to Vega AI updates`,
+	`Hello,
+
+Thanks for confirming. We will email you about {{.Topics}}, and nothing else.
+
+You can unsubscribe at any time with this link:
+
+{{.Link}}
+
+The Vega AI maintainers
+`,
+	`<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: system-ui, sans-serif; line-height: 1.5; color: #1f2937;">
+<p>Hello,</p>
+<p>Thanks for confirming. We will email you about {{.Topics}}, and nothing else.</p>
+<p>You can <a href="{{.Link}}">unsubscribe</a> at any time.</p>
+<p>The Vega AI maintainers</p>
+</body>
+</html>
+`)
+
+var (
+	confirmSubscriptionPage = template.Must(template.New("subscribe").Parse(privacyPageHead + `
+<h1>Confirm your subscription</h1>
+<p>We will only email you about the topics you chose, and every email has a link to unsubscribe.</p>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Confirm subscription</button>
+</form>
+</body>
+</html>
+`))
+
+	confirmUnsubscribePage = template.Must(template.New("unsubscribe").Parse(privacyPageHead + `
+<h1>Unsubscribe</h1>
+<p>This stops all Vega AI update emails and removes your email address.</p>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+	subscriptionDonePage = template.Must(template.New("subscription-done").Parse(privacyPageHead + `
+<h1>Done</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+)
@@ -0,0 +1,208 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+)
+
+func postForm(handler http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestHandleSubscribe_DoubleOptIn(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+
+	w := postForm(HandleSubscribe, url.Values{
+		"email":                {"Me@Example.com"},
+		"topics":               {"releases", "extension-updates", "releases"},
+		"privacyPolicyVersion": {"2025-01"},
+	})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	subscriber := stub.subscribers[google.NormalizeEmail("me@example.com")]
+	if subscriber.Status != google.SubscriberPending || strings.Join(subscriber.Topics, ",") != "releases,extension-updates" || subscriber.PrivacyPolicyVersion != "2025-01" {
+		t.Fatalf("unexpected subscriber %+v", subscriber)
+	}
+	if len(m.sent) != 1 || m.sent[0].To != "Me@Example.com" || !strings.Contains(m.sent[0].Text, "browser extension updates") {
+		t.Fatalf("expected a confirmation email, got %+v", m.sent)
+	}
+
+	link := linkFromEmail(t, m)
+	if link.Query().Get("action") != "subscribe/confirm" {
+		t.Errorf("unexpected link %s", link)
+	}
+
+	// Following the link only asks for confirmation.
+	w = httptest.NewRecorder()
+	HandleSubscribeConfirm(w, httptest.NewRequest("GET", link.RequestURI(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<form method="post"`) {
+		t.Fatalf("expected confirmation page, got %d: %s", w.Code, w.Body.String())
+	}
+	if stub.subscribers[subscriber.EmailHash].Status != google.SubscriberPending {
+		t.Fatal("expected GET not to confirm the subscription")
+	}
+
+	w = postForm(HandleSubscribeConfirm, url.Values{"token": {link.Query().Get("token")}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "subscription is confirmed") {
+		t.Fatalf("expected confirmation, got %d: %s", w.Code, w.Body.String())
+	}
+	if confirmed := stub.subscribers[subscriber.EmailHash]; confirmed.Status != google.SubscriberConfirmed || confirmed.ConfirmedAt.IsZero() {
+		t.Fatalf("expected a confirmed subscriber, got %+v", confirmed)
+	}
+
+	if len(m.sent) != 2 || !strings.Contains(m.sent[1].Subject, "subscribed") {
+		t.Fatalf("expected a welcome email, got %+v", m.sent)
+	}
+	unsubscribe := linkFromEmail(t, m)
+	if unsubscribe.Query().Get("action") != "unsubscribe" {
+		t.Fatalf("unexpected unsubscribe link %s", unsubscribe)
+	}
+
+	w = postPrivacy(HandleUnsubscribe, `{"token":"`+unsubscribe.Query().Get("token")+`"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "unsubscribed") {
+		t.Fatalf("expected unsubscription, got %d: %s", w.Code, w.Body.String())
+	}
+	if gone := stub.subscribers[subscriber.EmailHash]; gone.Status != google.SubscriberUnsubscribed || gone.Email != "" {
+		t.Errorf("expected the address to be removed, got %+v", gone)
+	}
+
+	// An old confirmation link cannot undo the unsubscription.
+	w = postPrivacy(HandleSubscribeConfirm, `{"token":"`+link.Query().Get("token")+`"}`)
+	if w.Code != http.StatusGone {
+		t.Errorf("expected status 410, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleSubscribe_SuppressesDuplicates(t *testing.T) {
+	stub, m, _ := setupPrivacy(t)
+	hash := google.NormalizeEmail("me@example.com")
+
+	tests := []struct {
+		name      string
+		status    string
+		requested time.Duration
+		wantEmail bool
+		wantState string
+	}{
+		{"pending, just requested", google.SubscriberPending, -time.Minute, false, google.SubscriberPending},
+		{"pending, requested within a day", google.SubscriberPending, -time.Hour, false, google.SubscriberPending},
+		{"pending, requested days ago", google.SubscriberPending, -25 * time.Hour, true, google.SubscriberPending},
+		{"confirmed", google.SubscriberConfirmed, -time.Hour, false, google.SubscriberConfirmed},
+		{"unsubscribed", google.SubscriberUnsubscribed, -time.Hour, true, google.SubscriberPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub.subscribers = map[string]google.Subscriber{hash: {
+				ID:          "sub-1",
+				EmailHash:   hash,
+				Topics:      []string{google.TopicReleases},
+				Status:      tt.status,
+				RequestedAt: time.Now().Add(tt.requested),
+			}}
+			m.sent = nil
+			subscribeConfirmations.reset()
+
+			w := postPrivacy(HandleSubscribe, `{"email":"me@example.com","topics":["extension-updates"]}`)
+			if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), subscribeAccepted) {
+				t.Fatalf("expected the same response for every address, got %d: %s", w.Code, w.Body.String())
+			}
+			if (len(m.sent) == 1) != tt.wantEmail {
+				t.Errorf("expected email sent %v, got %d emails", tt.wantEmail, len(m.sent))
+			}
+			if len(stub.subscribers) != 1 || stub.subscribers[hash].ID != "sub-1" || stub.subscribers[hash].Status != tt.wantState {
+				t.Errorf("expected the existing entry to be reused, got %+v", stub.subscribers)
+			}
+		})
+	}
+}
+
+func TestHandleSubscribe_ThrottlesConfirmations(t *testing.T) {
+	_, m, _ := setupPrivacy(t)
+
+	subscribe := func(email, client string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/?action=subscribe", strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		HandleSubscribe(w, req)
+		if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), subscribeAccepted) {
+			t.Fatalf("expected the same response when throttled, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	subscribe("a@example.com", "203.0.113.7")
+	subscribe("b@example.com", "203.0.113.7")
+	if len(m.sent) != 1 {
+		t.Fatalf("expected one confirmation per client, got %d", len(m.sent))
+	}
+
+	for i := len(m.sent); i < maxSubscribeEmailsPerHour+5; i++ {
+		subscribe(fmt.Sprintf("user%d@example.com", i), fmt.Sprintf("198.51.100.%d", i))
+	}
+	if len(m.sent) != maxSubscribeEmailsPerHour {
+		t.Errorf("expected at most %d confirmations an hour, got %d", maxSubscribeEmailsPerHour, len(m.sent))
+	}
+}
+
+func TestHandleSubscribe_RejectsBadRequests(t *testing.T) {
+	_, m, s := setupPrivacy(t)
+
+	confirmToken, _, _ := s.Sign("subscribe/confirm", "me@example.com", time.Hour)
+	expired, _, _ := s.Sign("unsubscribe", "me@example.com", -time.Minute)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+		want    int
+	}{
+		{"invalid email", HandleSubscribe, "POST", `{"email":"not an email"}`, http.StatusBadRequest},
+		{"unknown topic", HandleSubscribe, "POST", `{"email":"me@example.com","topics":["everything"]}`, http.StatusBadRequest},
+		{"subscribe with GET", HandleSubscribe, "GET", "", http.StatusMethodNotAllowed},
+		{"missing token", HandleSubscribeConfirm, "POST", `{}`, http.StatusBadRequest},
+		{"token for another action", HandleUnsubscribe, "POST", `{"token":"` + confirmToken + `"}`, http.StatusBadRequest},
+		{"expired token", HandleUnsubscribe, "POST", `{"token":"` + expired + `"}`, http.StatusGone},
+		{"unknown subscriber", HandleSubscribeConfirm, "POST", `{"token":"` + confirmToken + `"}`, http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			tt.handler(w, req)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+	if len(m.sent) != 0 {
+		t.Errorf("expected no emails, got %d", len(m.sent))
+	}
+}
+
+func TestHandleSubscribe_RequiresPublicURL(t *testing.T) {
+	_, m, _ := setupPrivacy(t)
+	t.Setenv("PUBLIC_API_URL", "")
+
+	w := postPrivacy(HandleSubscribe, `{"email":"new@example.com"}`)
+	if w.Code != http.StatusServiceUnavailable || len(m.sent) != 0 {
+		t.Errorf("expected status 503 and no email, got %d and %+v", w.Code, m.sent)
+	}
+}
@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"context"
+	"sync"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/webhook"
+)
+
+var (
+	dispatcher     *webhook.Dispatcher
+	dispatcherOnce sync.Once
+)
+
+// initDispatcher loads webhook targets from the environment once.
+func initDispatcher() {
+	dispatcherOnce.Do(func() {
+		d, err := webhook.NewDispatcherFromEnv(sheetsDeliveryLog{})
+		if err != nil {
+			logging.Warningf("Webhooks not configured: %v", err)
+			return
+		}
+		dispatcher = d
+	})
+}
+
+// SetDispatcher replaces the webhook dispatcher used by the handlers. It is
+// intended for tests; passing nil disables webhooks.
+func SetDispatcher(d *webhook.Dispatcher) {
+	dispatcherOnce.Do(func() {})
+	dispatcher = d
+}
+
+// sheetsDeliveryLog records webhook deliveries in the Sheets delivery log
+// tab, when storage is configured.
+type sheetsDeliveryLog struct{}
+
+func (sheetsDeliveryLog) RecordDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	if sheetsService == nil {
+		return nil
+	}
+	err := sheetsService.RecordDelivery(ctx, &google.DeliveryRecord{
+		SubmissionID: delivery.EventID,
+		Target:       delivery.Target,
+		Attempts:     delivery.Attempts,
+		StatusCode:   delivery.StatusCode,
+		Success:      delivery.Success,
+		Error:        delivery.Error,
+		CompletedAt:  delivery.CompletedAt,
+	})
+	if err != nil {
+		logging.Errorf("Failed to record webhook delivery: %v", err)
+	}
+	return err
+}
+
+// notifyWebhooks sends a stored submission to the configured webhook
+// targets.
+func notifyWebhooks(ctx context.Context, feedback *google.FeedbackData) {
+	initDispatcher()
+	if dispatcher == nil {
+		return
+	}
+
+	deliveries := dispatcher.Dispatch(ctx, &webhook.Event{
+		Type:               webhook.EventFeedbackCreated,
+		ID:                 feedback.ID,
+		SubmittedAt:        feedback.SubmittedAt,
+		Helpfulness:        feedback.Helpfulness,
+		SetupDifficulty:    feedback.SetupDifficulty,
+		DocsQuality:        feedback.DocsQuality,
+		SetupIssues:        splitSetupIssues(feedback.SetupIssues),
+		AdditionalFeedback: feedback.AdditionalFeedback,
+		Source:             feedback.Source,
+	})
+
+	for _, delivery := range deliveries {
+		if !delivery.Success {
+			logging.Warning("Webhook delivery failed", logging.Fields{
+				"submission_id": delivery.EventID,
+				"status":        delivery.StatusCode,
+				"count":         delivery.Attempts,
+			})
+		}
+	}
+}
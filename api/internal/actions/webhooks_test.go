@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/webhook"
+)
+
+func TestHandleFeedback_NotifiesWebhooks(t *testing.T) {
+	stub := setupAdmin(t)
+
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify("secret", r.Header.Get(webhook.HeaderTimestamp), body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		bodies = append(bodies, body)
+	}))
+	defer receiver.Close()
+
+	d, err := webhook.NewDispatcher(&webhook.Config{
+		Targets: []*webhook.Target{{Name: "team", URL: receiver.URL, Secret: "secret"}},
+		Log:     sheetsDeliveryLog{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetDispatcher(d)
+	t.Cleanup(func() { SetDispatcher(nil) })
+
+	form := url.Values{
+		"helpfulness":        {"not-helpful"},
+		"setupIssues":        {"docker-installation", "port-conflicts"},
+		"additionalFeedback": {"Port 8765 is taken, mail me@example.com"},
+		"email":              {"me@example.com"},
+		"consentContact":     {"true"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	HandleFeedback(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Expected one signed delivery, got %d", len(bodies))
+	}
+	if strings.Contains(string(bodies[0]), "me@example.com") {
+		t.Error("Expected the webhook payload not to contain the email")
+	}
+
+	var event webhook.Event
+	if err := json.Unmarshal(bodies[0], &event); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if event.Type != webhook.EventFeedbackCreated || strings.Join(event.SetupIssues, "|") != "docker-installation|port-conflicts" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	if len(stub.deliveries) != 1 || !stub.deliveries[0].Success || stub.deliveries[0].Target != "team" {
+		t.Errorf("Expected the delivery to be logged, got %+v", stub.deliveries)
+	}
+}
+
+func TestHandleFeedback_NoWebhooksWithoutStorage(t *testing.T) {
+	SetSheetsService(nil)
+
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	d, err := webhook.NewDispatcher(&webhook.Config{Targets: []*webhook.Target{{Name: "team", URL: receiver.URL}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetDispatcher(d)
+	t.Cleanup(func() { SetDispatcher(nil) })
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"helpfulness":"very-helpful"}`))
+	req.Header.Set("Content-Type", "application/json")
+	HandleFeedback(httptest.NewRecorder(), req)
+
+	if called {
+		t.Error("Expected no webhook for feedback that was not stored")
+	}
+}
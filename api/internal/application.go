@@ -2,17 +2,57 @@ package internal
 
 import (
 	"github.com/benidevo/vega-ai-landing-page/api/internal/actions"
-	"log"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
 	"net/http"
 	"strings"
+	"sync"
 )
 
+var (
+	authenticator *auth.Authenticator
+	authOnce      sync.Once
+)
+
+// initAuthenticator loads admin credentials from the environment once. A
+// broken configuration denies every authenticated action.
+func initAuthenticator() {
+	authOnce.Do(func() {
+		a, err := auth.NewAuthenticatorFromEnv()
+		if err != nil {
+			logging.Errorf("Invalid auth configuration, admin actions disabled: %v", err)
+			a, _ = auth.NewAuthenticator(&auth.Config{})
+		}
+		authenticator = a
+	})
+}
+
+// SetAuthenticator replaces the authenticator built from the environment. It
+// is intended for tests; passing nil denies every authenticated action.
+func SetAuthenticator(a *auth.Authenticator) {
+	authOnce.Do(func() {})
+	if a == nil {
+		a, _ = auth.NewAuthenticator(&auth.Config{})
+	}
+	authenticator = a
+}
+
+// requireScope wraps a handler so that it only runs for authenticated callers
+// granted scope.
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	initAuthenticator()
+	return authenticator.Require(scope, next)
+}
+
 // Application is an HTTP handler that manages CORS headers and routes requests
 // based on an extracted action from the URL path or query parameters.
 func Application(w http.ResponseWriter, r *http.Request) {
+	// Admin actions read and triage feedback with GET and PATCH and send a
+	// bearer token, so browser-based admin clients need those allowed too.
+	// No cookies are used, so credentials are never allowed.
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PATCH, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -20,13 +60,57 @@ func Application(w http.ResponseWriter, r *http.Request) {
 	}
 
 	action := extractAction(r)
-	log.Printf("INFO: Processing request with action: %s from %s", action, r.RemoteAddr)
+	// Clients are identified by a daily rotating hash, never by address.
+	logging.Info("Processing request", logging.Fields{
+		"action": action,
+		"method": r.Method,
+		"client": logging.ClientHash(r),
+	})
 
-	switch action {
-	case ActionFeedback:
+	switch {
+	case action == ActionFeedback:
 		actions.HandleFeedback(w, r)
+	case action == ActionAdminFeedback || strings.HasPrefix(action, ActionAdminFeedback+"/"):
+		id := strings.TrimPrefix(strings.TrimPrefix(action, ActionAdminFeedback), "/")
+		requireScope(auth.ScopeFeedbackRead, func(w http.ResponseWriter, r *http.Request) {
+			actions.HandleAdminFeedback(w, r, id)
+		})(w, r)
+	case action == ActionStats:
+		actions.HandleStats(w, r)
+	case action == ActionPrivacyExport:
+		actions.HandlePrivacyExport(w, r)
+	case action == ActionPrivacyErase:
+		actions.HandlePrivacyErase(w, r)
+	case action == ActionReleases:
+		actions.HandleReleases(w, r)
+	case strings.HasPrefix(action, ActionDownload+"/"):
+		actions.HandleDownload(w, r, strings.TrimPrefix(action, ActionDownload+"/"))
+	case action == ActionDownloadCounts:
+		actions.HandleDownloadCounts(w, r)
+	case action == ActionEvents:
+		actions.HandleEvents(w, r)
+	case action == ActionEventCounts:
+		actions.HandleEventCounts(w, r)
+	case action == ActionSetupGenerate:
+		actions.HandleSetupGenerate(w, r)
+	case action == ActionSetupValidate:
+		actions.HandleSetupValidate(w, r)
+	case action == ActionSubscribe:
+		actions.HandleSubscribe(w, r)
+	case action == ActionSubscribeConfirm:
+		actions.HandleSubscribeConfirm(w, r)
+	case action == ActionUnsubscribe:
+		actions.HandleUnsubscribe(w, r)
+	case action == ActionExport:
+		requireScope(auth.ScopeFeedbackExport, actions.HandleExport)(w, r)
+	case action == ActionRotateKeys:
+		requireScope(auth.ScopeMaintenance, actions.HandleRotateKeys)(w, r)
+	case action == ActionRetention:
+		requireScope(auth.ScopeMaintenance, actions.HandleRetention)(w, r)
+	case action == ActionCompactCounts:
+		requireScope(auth.ScopeMaintenance, actions.HandleCompactCounts)(w, r)
 	default:
-		log.Printf("ERROR: Unknown action requested: %s", action)
+		logging.Error("Unknown action requested", logging.Fields{"action": action})
 		http.Error(w, "Unknown action", http.StatusBadRequest)
 	}
 }
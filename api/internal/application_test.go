@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"bytes"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 )
@@ -19,8 +22,8 @@ func TestApplication_CORS(t *testing.T) {
 
 	expectedHeaders := map[string]string{
 		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Methods": "POST, OPTIONS",
-		"Access-Control-Allow-Headers": "Content-Type",
+		"Access-Control-Allow-Methods": "GET, HEAD, POST, PATCH, OPTIONS",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type",
 	}
 
 	for header, expected := range expectedHeaders {
@@ -107,3 +110,20 @@ func TestExtractAction_QueryParameterPriority(t *testing.T) {
 		t.Errorf("Expected action 'feedback' from query parameter, got '%s'", action)
 	}
 }
+
+func TestApplication_LogsWithoutClientAddress(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	req := httptest.NewRequest("POST", "/?action=me@example.com", nil)
+	req.RemoteAddr = "203.0.113.9:51234"
+	Application(httptest.NewRecorder(), req)
+
+	if strings.Contains(logs.String(), "203.0.113.9") || strings.Contains(logs.String(), "example.com") {
+		t.Errorf("Expected no client address or email in logs, got %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), "client=") {
+		t.Errorf("Expected a hashed client identifier, got %q", logs.String())
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey is a static credential. Only the SHA-256 hash of the key is kept in
+// configuration, so leaking the config does not leak usable keys.
+type APIKey struct {
+	// ID names the key and becomes the principal subject.
+	ID string `json:"id"`
+	// Hash is the hex encoded SHA-256 of the key, optionally prefixed with
+	// "sha256:".
+	Hash string `json:"hash"`
+	// Scopes granted to callers using this key.
+	Scopes []string `json:"scopes"`
+	// Revoked disables the key without removing it from configuration.
+	Revoked bool `json:"revoked,omitempty"`
+	// ExpiresAt disables the key after the given time, if set.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// HashAPIKey returns the value to store in APIKey.Hash for key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// digest decodes the configured hash.
+func (k *APIKey) digest() ([]byte, error) {
+	raw := strings.TrimPrefix(k.Hash, "sha256:")
+	digest, err := hex.DecodeString(raw)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, fmt.Errorf("API key %q has an invalid SHA-256 hash", k.ID)
+	}
+	return digest, nil
+}
+
+// matchAPIKey returns the configured key matching the presented secret. Every
+// key is compared so that timing does not reveal which one matched.
+func matchAPIKey(keys []APIKey, presented string, now time.Time) (*APIKey, error) {
+	sum := sha256.Sum256([]byte(presented))
+
+	var match *APIKey
+	for i := range keys {
+		digest, err := keys[i].digest()
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare(sum[:], digest) == 1 {
+			match = &keys[i]
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	if match.Revoked {
+		return nil, fmt.Errorf("%w: API key %q is revoked", ErrInvalidCredentials, match.ID)
+	}
+	if match.ExpiresAt != nil && !now.Before(*match.ExpiresAt) {
+		return nil, fmt.Errorf("%w: API key %q expired", ErrInvalidCredentials, match.ID)
+	}
+
+	return match, nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashAPIKey(t *testing.T) {
+	hash := HashAPIKey("secret")
+	if !strings.HasPrefix(hash, "sha256:") || len(hash) != len("sha256:")+64 {
+		t.Errorf("unexpected hash format: %q", hash)
+	}
+	if hash == HashAPIKey("Secret") {
+		t.Error("expected different keys to hash differently")
+	}
+}
+
+func TestMatchAPIKey(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	keys := []APIKey{
+		{ID: "active", Hash: HashAPIKey("active-key"), Scopes: []string{ScopeFeedbackRead}},
+		{ID: "bare-hex", Hash: strings.TrimPrefix(HashAPIKey("bare-key"), "sha256:")},
+		{ID: "revoked", Hash: HashAPIKey("revoked-key"), Revoked: true},
+		{ID: "expired", Hash: HashAPIKey("expired-key"), ExpiresAt: &past},
+		{ID: "expiring", Hash: HashAPIKey("expiring-key"), ExpiresAt: &future},
+		{ID: "broken", Hash: "not-hex"},
+	}
+
+	tests := []struct {
+		presented string
+		wantID    string
+	}{
+		{"active-key", "active"},
+		{"bare-key", "bare-hex"},
+		{"expiring-key", "expiring"},
+		{"revoked-key", ""},
+		{"expired-key", ""},
+		{"unknown", ""},
+		{"not-hex", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.presented, func(t *testing.T) {
+			key, err := matchAPIKey(keys, tt.presented, now)
+			if tt.wantID == "" {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("expected ErrInvalidCredentials, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key.ID != tt.wantID {
+				t.Errorf("expected key %q, got %q", tt.wantID, key.ID)
+			}
+		})
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := &Principal{Scopes: []string{ScopeFeedbackRead}}
+	if !p.HasScope(ScopeFeedbackRead) || p.HasScope(ScopeFeedbackTriage) {
+		t.Error("unexpected scope check result")
+	}
+
+	admin := &Principal{Scopes: []string{ScopeAll}}
+	if !admin.HasScope(ScopeFeedbackTriage) {
+		t.Error("expected wildcard scope to grant everything")
+	}
+
+	var none *Principal
+	if none.HasScope(ScopeFeedbackRead) {
+		t.Error("expected nil principal to have no scopes")
+	}
+}
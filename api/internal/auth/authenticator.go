@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials.
+	ErrNoCredentials = errors.New("no credentials provided")
+	// ErrInvalidCredentials is returned when credentials fail verification.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Config configures an Authenticator.
+type Config struct {
+	// APIKeys are the accepted static keys.
+	APIKeys []APIKey
+	// JWT enables bearer token verification when non-nil.
+	JWT *JWTConfig
+	// HTTPClient fetches remote key sets. Defaults to a client with a
+	// short timeout.
+	HTTPClient *http.Client
+	// Now overrides the current time, for tests.
+	Now func() time.Time
+}
+
+// Authenticator resolves request credentials into a Principal.
+type Authenticator struct {
+	keys []APIKey
+	jwt  *jwtVerifier
+	now  func() time.Time
+}
+
+// NewAuthenticator validates config and builds an Authenticator.
+func NewAuthenticator(config *Config) (*Authenticator, error) {
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	for i := range config.APIKeys {
+		if config.APIKeys[i].ID == "" {
+			return nil, fmt.Errorf("API key %d has no ID", i)
+		}
+		if _, err := config.APIKeys[i].digest(); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &Authenticator{keys: config.APIKeys, now: now}
+
+	if config.JWT != nil {
+		if config.JWT.Audience == "" {
+			return nil, fmt.Errorf("JWT verification requires an audience")
+		}
+
+		var keys keySource
+		switch {
+		case len(config.JWT.KeySet) > 0:
+			parsed, err := ParseJWKS(config.JWT.KeySet)
+			if err != nil {
+				return nil, err
+			}
+			keys = staticKeySource(parsed)
+		case config.JWT.JWKSURL != "":
+			client := config.HTTPClient
+			if client == nil {
+				client = &http.Client{Timeout: 5 * time.Second}
+			}
+			keys = &remoteKeySource{url: config.JWT.JWKSURL, client: client, now: now}
+		default:
+			return nil, fmt.Errorf("JWT verification requires a JWKS URL or a local key set")
+		}
+
+		a.jwt = &jwtVerifier{config: config.JWT, keys: keys, now: now}
+	}
+
+	return a, nil
+}
+
+// NewAuthenticatorFromEnv builds an Authenticator from environment variables:
+//
+//   - AUTH_API_KEYS or AUTH_API_KEYS_FILE: JSON array of APIKey
+//   - ADMIN_API_TOKEN: a legacy admin token granted every scope
+//   - AUTH_JWKS_URL or AUTH_JWKS_FILE: key set for bearer JWTs
+//   - AUTH_JWT_AUDIENCE, AUTH_JWT_ISSUERS (comma separated)
+//   - AUTH_JWT_SUBJECTS: JSON object mapping subjects to scopes
+func NewAuthenticatorFromEnv() (*Authenticator, error) {
+	config := &Config{}
+
+	keysJSON := []byte(os.Getenv("AUTH_API_KEYS"))
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AUTH_API_KEYS_FILE: %w", err)
+		}
+		keysJSON = data
+	}
+	if len(keysJSON) > 0 {
+		if err := json.Unmarshal(keysJSON, &config.APIKeys); err != nil {
+			return nil, fmt.Errorf("invalid API key configuration: %w", err)
+		}
+	}
+
+	if token := os.Getenv("ADMIN_API_TOKEN"); token != "" {
+		config.APIKeys = append(config.APIKeys, APIKey{
+			ID:     "admin-token",
+			Hash:   HashAPIKey(token),
+			Scopes: []string{ScopeAll},
+		})
+	}
+
+	jwksURL := os.Getenv("AUTH_JWKS_URL")
+	jwksFile := os.Getenv("AUTH_JWKS_FILE")
+	if jwksURL != "" || jwksFile != "" {
+		jwt := &JWTConfig{
+			JWKSURL:  jwksURL,
+			Audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		}
+		if jwksFile != "" {
+			data, err := os.ReadFile(jwksFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read AUTH_JWKS_FILE: %w", err)
+			}
+			jwt.KeySet = data
+		}
+		for _, issuer := range strings.Split(os.Getenv("AUTH_JWT_ISSUERS"), ",") {
+			if issuer = strings.TrimSpace(issuer); issuer != "" {
+				jwt.Issuers = append(jwt.Issuers, issuer)
+			}
+		}
+		if subjects := os.Getenv("AUTH_JWT_SUBJECTS"); subjects != "" {
+			if err := json.Unmarshal([]byte(subjects), &jwt.Subjects); err != nil {
+				return nil, fmt.Errorf("invalid AUTH_JWT_SUBJECTS: %w", err)
+			}
+		}
+		config.JWT = jwt
+	}
+
+	return NewAuthenticator(config)
+}
+
+// Authenticate verifies the credentials on r. Bearer tokens that look like a
+// JWT are verified as such; anything else, including X-API-Key, is treated
+// as an API key.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	credential := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		credential = strings.TrimSpace(bearer)
+	}
+	if credential == "" {
+		return nil, ErrNoCredentials
+	}
+
+	if looksLikeJWT(credential) {
+		if a.jwt == nil {
+			return nil, fmt.Errorf("%w: bearer tokens are not accepted", ErrInvalidCredentials)
+		}
+		return a.jwt.verify(r.Context(), credential)
+	}
+
+	key, err := matchAPIKey(a.keys, credential, a.now())
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Subject: key.ID, Method: MethodAPIKey, Scopes: key.Scopes}, nil
+}
+
+// Require wraps next so that it only runs for callers granted scope. The
+// principal is available to next through PrincipalFromContext.
+func (a *Authenticator) Require(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			logging.Errorf("Authentication failed: %v", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="vega-landing-api"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			logging.Errorf("Principal %s lacks scope %s", principal.Subject, scope)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	}
+}
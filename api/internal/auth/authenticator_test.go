@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewAuthenticatorFromEnv(t *testing.T) {
+	t.Setenv("AUTH_API_KEYS", `[{"id":"ci","hash":"`+HashAPIKey("ci-key")+`","scopes":["feedback:read"]}]`)
+	t.Setenv("ADMIN_API_TOKEN", "legacy-token")
+
+	a, err := NewAuthenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, subject := range map[string]string{"ci-key": "ci", "legacy-token": "admin-token"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", key)
+
+		principal, err := a.Authenticate(req)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", key, err)
+		}
+		if principal.Subject != subject {
+			t.Errorf("expected subject %q, got %q", subject, principal.Subject)
+		}
+	}
+}
+
+func TestNewAuthenticatorFromEnv_JWKSFile(t *testing.T) {
+	fixture := newJWKSFixture(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, fixture.set, 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	t.Setenv("AUTH_JWKS_FILE", path)
+	t.Setenv("AUTH_JWT_AUDIENCE", "https://api.example.com")
+	t.Setenv("AUTH_JWT_ISSUERS", "https://accounts.google.com, accounts.google.com")
+	t.Setenv("AUTH_JWT_SUBJECTS", `{"scheduler@project.iam.gserviceaccount.com":["*"]}`)
+
+	a, err := NewAuthenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := a.jwt.config.Issuers; len(got) != 2 || got[1] != "accounts.google.com" {
+		t.Errorf("unexpected issuers: %v", got)
+	}
+}
+
+func TestNewAuthenticator_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{"key without ID", &Config{APIKeys: []APIKey{{Hash: HashAPIKey("x")}}}},
+		{"key with bad hash", &Config{APIKeys: []APIKey{{ID: "x", Hash: "sha256:zz"}}}},
+		{"JWT without audience", &Config{JWT: &JWTConfig{JWKSURL: "https://example.com"}}},
+		{"JWT without keys", &Config{JWT: &JWTConfig{Audience: "aud"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAuthenticator(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Require(t *testing.T) {
+	a, err := NewAuthenticator(&Config{APIKeys: []APIKey{
+		{ID: "reader", Hash: HashAPIKey("read-key"), Scopes: []string{ScopeFeedbackRead}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var seen *Principal
+	handler := func(scope string) http.HandlerFunc {
+		return a.Require(scope, func(w http.ResponseWriter, r *http.Request) {
+			seen, _ = PrincipalFromContext(r.Context())
+		})
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		scope  string
+		want   int
+	}{
+		{"no credentials", "", ScopeFeedbackRead, http.StatusUnauthorized},
+		{"bad key", "Bearer wrong", ScopeFeedbackRead, http.StatusUnauthorized},
+		{"JWT without verifier", "Bearer a.b.c", ScopeFeedbackRead, http.StatusUnauthorized},
+		{"missing scope", "Bearer read-key", ScopeFeedbackTriage, http.StatusForbidden},
+		{"allowed", "Bearer read-key", ScopeFeedbackRead, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler(tt.scope)(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+			if tt.want == http.StatusOK && (seen == nil || seen.Subject != "reader") {
+				t.Errorf("expected principal in context, got %+v", seen)
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAuthenticate_NoCredentials(t *testing.T) {
+	a, _ := NewAuthenticator(&Config{})
+	if _, err := a.Authenticate(httptest.NewRequest("GET", "/", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
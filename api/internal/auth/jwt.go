@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// jwtLeeway tolerates small clock differences when checking token times.
+const jwtLeeway = time.Minute
+
+// JWTConfig configures bearer token verification.
+type JWTConfig struct {
+	// JWKSURL is fetched for verification keys, e.g.
+	// https://www.googleapis.com/oauth2/v3/certs for Google ID tokens.
+	JWKSURL string
+	// KeySet is a local JWKS document used instead of JWKSURL.
+	KeySet []byte
+	// Issuers lists accepted "iss" values. Empty accepts any issuer.
+	Issuers []string
+	// Audience must appear in the token "aud" claim.
+	Audience string
+	// Subjects maps token subjects, or verified emails, to granted scopes.
+	// When set, tokens for other subjects are rejected.
+	Subjects map[string][]string
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     *int64   `json:"exp"`
+	NotBefore     *int64   `json:"nbf"`
+	IssuedAt      *int64   `json:"iat"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Scope         string   `json:"scope"`
+	Scp           []string `json:"scp"`
+}
+
+// audience accepts both the string and array forms of the "aud" claim.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// jwtVerifier checks signed bearer tokens.
+type jwtVerifier struct {
+	config *JWTConfig
+	keys   keySource
+	now    func() time.Time
+}
+
+// verify validates a compact JWT and returns the principal it represents.
+func (v *jwtVerifier) verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid token header", ErrInvalidCredentials)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid token signature encoding", ErrInvalidCredentials)
+	}
+
+	key, err := v.keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid token claims", ErrInvalidCredentials)
+	}
+
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	return v.principal(&claims)
+}
+
+func (v *jwtVerifier) checkClaims(claims *jwtClaims) error {
+	now := v.now()
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if claims.IssuedAt != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.IssuedAt, 0)) {
+		return fmt.Errorf("token issued in the future")
+	}
+	if len(v.config.Issuers) > 0 && !slices.Contains(v.config.Issuers, claims.Issuer) {
+		return fmt.Errorf("untrusted issuer %q", claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, v.config.Audience) {
+		return fmt.Errorf("token audience does not match")
+	}
+
+	return nil
+}
+
+func (v *jwtVerifier) principal(claims *jwtClaims) (*Principal, error) {
+	subject := claims.Subject
+	if claims.Email != "" && claims.EmailVerified {
+		subject = claims.Email
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	var scopes []string
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+	scopes = append(scopes, claims.Scp...)
+
+	if len(v.config.Subjects) > 0 {
+		granted, ok := v.config.Subjects[subject]
+		if !ok {
+			return nil, fmt.Errorf("%w: subject %q is not allowed", ErrInvalidCredentials, subject)
+		}
+		scopes = append(scopes, granted...)
+	}
+
+	return &Principal{Subject: subject, Method: MethodJWT, Scopes: scopes}, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	default:
+		// Notably rejects "none" and HMAC algorithms, which would let a
+		// public key be used as a shared secret.
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// looksLikeJWT reports whether a bearer credential is a compact JWT rather
+// than an API key.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
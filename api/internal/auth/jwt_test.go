@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksFixture holds signing keys and serves their public halves as a JWKS.
+type jwksFixture struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	set    []byte
+}
+
+func newJWKSFixture(t *testing.T) *jwksFixture {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set, err := json.Marshal(JSONWebKeySet{Keys: []JSONWebKey{
+		{
+			Kty: "RSA", Kid: "rsa-1", Alg: "RS256", Use: "sig",
+			N: b64(rsaKey.N.Bytes()), E: b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			Kty: "EC", Kid: "ec-1", Alg: "ES256", Use: "sig", Crv: "P-256",
+			X: b64(ecKey.X.FillBytes(make([]byte, 32))), Y: b64(ecKey.Y.FillBytes(make([]byte, 32))),
+		},
+	}})
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+
+	return &jwksFixture{rsaKey: rsaKey, ecKey: ecKey, set: set}
+}
+
+func (f *jwksFixture) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+	switch alg {
+	case "RS256":
+		s, err := rsa.SignPKCS1v15(rand.Reader, f.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		sig = s
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, f.ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		sig = []byte("unsigned")
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+var fixtureNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":            "https://accounts.google.com",
+		"aud":            "https://api.example.com",
+		"sub":            "1234567890",
+		"email":          "scheduler@project.iam.gserviceaccount.com",
+		"email_verified": true,
+		"iat":            fixtureNow.Add(-time.Minute).Unix(),
+		"exp":            fixtureNow.Add(time.Hour).Unix(),
+	}
+}
+
+func newVerifier(t *testing.T, fixture *jwksFixture, subjects map[string][]string) *Authenticator {
+	t.Helper()
+
+	a, err := NewAuthenticator(&Config{
+		JWT: &JWTConfig{
+			KeySet:   fixture.set,
+			Issuers:  []string{"https://accounts.google.com", "accounts.google.com"},
+			Audience: "https://api.example.com",
+			Subjects: subjects,
+		},
+		Now: func() time.Time { return fixtureNow },
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return a
+}
+
+func TestJWTVerification(t *testing.T) {
+	fixture := newJWKSFixture(t)
+	a := newVerifier(t, fixture, map[string][]string{
+		"scheduler@project.iam.gserviceaccount.com": {ScopeFeedbackRead},
+	})
+
+	with := func(key string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"valid RS256", fixture.sign(t, "RS256", "rsa-1", validClaims()), true},
+		{"valid ES256", fixture.sign(t, "ES256", "ec-1", validClaims()), true},
+		{"audience array", fixture.sign(t, "RS256", "rsa-1", with("aud", []string{"other", "https://api.example.com"})), true},
+		{"expired", fixture.sign(t, "RS256", "rsa-1", with("exp", fixtureNow.Add(-2*time.Minute).Unix())), false},
+		{"missing expiry", fixture.sign(t, "RS256", "rsa-1", with("exp", nil)), false},
+		{"not yet valid", fixture.sign(t, "RS256", "rsa-1", with("nbf", fixtureNow.Add(time.Hour).Unix())), false},
+		{"wrong audience", fixture.sign(t, "RS256", "rsa-1", with("aud", "https://evil.example.com")), false},
+		{"wrong issuer", fixture.sign(t, "RS256", "rsa-1", with("iss", "https://evil.example.com")), false},
+		{"unknown subject", fixture.sign(t, "RS256", "rsa-1", with("email", "someone@example.com")), false},
+		{"unknown key", fixture.sign(t, "RS256", "rsa-2", validClaims()), false},
+		{"algorithm mismatch", fixture.sign(t, "ES256", "rsa-1", validClaims()), false},
+		{"alg none", fixture.sign(t, "none", "rsa-1", validClaims()), false},
+		{"tampered", fixture.sign(t, "RS256", "rsa-1", validClaims()) + "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			principal, err := a.Authenticate(req)
+			if !tt.ok {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("expected ErrInvalidCredentials, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.Subject != "scheduler@project.iam.gserviceaccount.com" || principal.Method != MethodJWT {
+				t.Errorf("unexpected principal: %+v", principal)
+			}
+			if !principal.HasScope(ScopeFeedbackRead) {
+				t.Errorf("expected mapped scopes, got %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestJWTVerification_TokenScopes(t *testing.T) {
+	fixture := newJWKSFixture(t)
+	a := newVerifier(t, fixture, nil)
+
+	claims := validClaims()
+	claims["email_verified"] = false
+	claims["scope"] = "feedback:read feedback:triage"
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+fixture.sign(t, "RS256", "rsa-1", claims))
+
+	principal, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if principal.Subject != "1234567890" {
+		t.Errorf("expected unverified email to fall back to sub, got %q", principal.Subject)
+	}
+	if !principal.HasScope(ScopeFeedbackTriage) {
+		t.Errorf("expected scopes from the scope claim, got %v", principal.Scopes)
+	}
+}
+
+func TestRemoteKeySource(t *testing.T) {
+	fixture := newJWKSFixture(t)
+
+	var fetches atomic.Int32
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture.set)
+	}))
+	defer srv.Close()
+
+	now := fixtureNow
+	source := &remoteKeySource{url: srv.URL, client: srv.Client(), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	if _, err := source.key(ctx, "rsa-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := source.key(ctx, "ec-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("expected keys to be cached, got %d fetches", fetches.Load())
+	}
+
+	// Unknown key IDs trigger at most one refetch per interval.
+	source.key(ctx, "rotated")
+	source.key(ctx, "rotated")
+	if fetches.Load() != 1 {
+		t.Errorf("expected unknown kid refetch to be rate limited, got %d fetches", fetches.Load())
+	}
+	now = now.Add(2 * minJWKSRefreshInterval)
+	source.key(ctx, "rotated")
+	if fetches.Load() != 2 {
+		t.Errorf("expected refetch after the interval, got %d fetches", fetches.Load())
+	}
+
+	// Cached keys keep working while the endpoint is down after expiry.
+	fail.Store(true)
+	now = now.Add(2 * defaultJWKSTTL)
+	if _, err := source.key(ctx, "rsa-1"); err != nil {
+		t.Errorf("expected stale key to be served during outage, got %v", err)
+	}
+}
+
+func TestAuthenticator_JWKSURL(t *testing.T) {
+	fixture := newJWKSFixture(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture.set)
+	}))
+	defer srv.Close()
+
+	a, err := NewAuthenticator(&Config{
+		JWT:        &JWTConfig{JWKSURL: srv.URL, Audience: "https://api.example.com"},
+		HTTPClient: srv.Client(),
+		Now:        func() time.Time { return fixtureNow },
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+fixture.sign(t, "RS256", "rsa-1", validClaims()))
+	if _, err := a.Authenticate(req); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	for _, doc := range []string{`not json`, `{"keys":[]}`, `{"keys":[{"kty":"oct","kid":"x"}]}`} {
+		if _, err := ParseJWKS([]byte(doc)); err == nil {
+			t.Errorf("expected error for %s", doc)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package auth authenticates callers of the privileged API actions using
+// static API keys or signed JWTs, and exposes the resulting principal to
+// handlers through the request context.
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Scopes granted to principals. Handlers check for the scope they need.
+const (
+	// ScopeAll grants every scope.
+	ScopeAll = "*"
+	// ScopeFeedbackRead allows listing and viewing stored feedback.
+	ScopeFeedbackRead = "feedback:read"
+	// ScopeFeedbackTriage allows changing triage status and notes.
+	ScopeFeedbackTriage = "feedback:triage"
+	// ScopeFeedbackExport allows bulk export of stored feedback.
+	ScopeFeedbackExport = "feedback:export"
+	// ScopeMaintenance allows scheduled maintenance such as key rotation.
+	ScopeMaintenance = "maintenance"
+)
+
+// Authentication methods reported on a Principal.
+const (
+	MethodAPIKey = "api-key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller: the API key ID or the token subject.
+	Subject string
+	// Method is how the caller authenticated.
+	Method string
+	// Scopes are the permissions granted to the caller.
+	Scopes []string
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAll)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the middleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -2,5 +2,23 @@ package internal
 
 // Action constants define the supported API actions
 const (
-	ActionFeedback = "feedback"
+	ActionFeedback         = "feedback"
+	ActionAdminFeedback    = "admin/feedback"
+	ActionExport           = "export"
+	ActionStats            = "stats"
+	ActionPrivacyExport    = "privacy/export"
+	ActionPrivacyErase     = "privacy/erase"
+	ActionRotateKeys       = "maintenance/rotate-keys"
+	ActionRetention        = "maintenance/retention"
+	ActionCompactCounts    = "maintenance/compact-counts"
+	ActionSubscribe        = "subscribe"
+	ActionSubscribeConfirm = "subscribe/confirm"
+	ActionUnsubscribe      = "unsubscribe"
+	ActionReleases         = "releases"
+	ActionDownload         = "download"
+	ActionDownloadCounts   = "downloads"
+	ActionSetupGenerate    = "setup/generate"
+	ActionSetupValidate    = "setup/validate"
+	ActionEvents           = "events"
+	ActionEventCounts      = "events/counts"
 )
@@ -0,0 +1,278 @@
+// Package github talks to the GitHub REST API. Reporter files setup problems
+// reported through the feedback form as labelled issues and comments on the
+// Vega repository, and ReleaseCache serves the latest releases of the Vega
+// projects without calling GitHub on every request.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// DefaultRepository receives setup issues unless GITHUB_REPOSITORY is set.
+const DefaultRepository = "benidevo/vega"
+
+// RateLimitError is returned when GitHub refuses a request because a rate
+// limit was reached. Reset is when it is worth trying again.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub rate limit reached until %s", e.Reset.UTC().Format(time.RFC3339))
+}
+
+// Issue is the subset of a GitHub issue used here.
+type Issue struct {
+	Number  int     `json:"number"`
+	Title   string  `json:"title"`
+	State   string  `json:"state"`
+	HTMLURL string  `json:"html_url"`
+	Labels  []Label `json:"labels"`
+	// PullRequest is set when the issue is a pull request, which the issues
+	// API also lists.
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// Label is a GitHub issue label.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Comment is an issue comment.
+type Comment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+	// Token authenticates requests. It needs permission to write issues.
+	Token string
+	// Repository is "owner/name". Defaults to DefaultRepository.
+	Repository string
+	// HTTPClient defaults to a client with a ten second timeout.
+	HTTPClient *http.Client
+}
+
+// Client calls the GitHub issues API for one repository, and the releases
+// API for any public repository.
+type Client struct {
+	baseURL    string
+	token      string
+	repository string
+	http       *http.Client
+}
+
+// NewClient validates config and returns a client.
+func NewClient(config *Config) (*Client, error) {
+	if config.Token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	return NewReadOnlyClient(config)
+}
+
+// NewReadOnlyClient returns a client for public endpoints such as releases,
+// where the token is optional and only raises the rate limit.
+func NewReadOnlyClient(config *Config) (*Client, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.Repository == "" {
+		config.Repository = DefaultRepository
+	}
+	if owner, name, ok := strings.Cut(config.Repository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("GitHub repository must be owner/name, got %q", config.Repository)
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		token:      config.Token,
+		repository: config.Repository,
+		http:       config.HTTPClient,
+	}, nil
+}
+
+// NewClientFromEnv builds a client from GITHUB_TOKEN, GITHUB_REPOSITORY and
+// GITHUB_API_URL. It returns nil without error when GITHUB_TOKEN is unset.
+func NewClientFromEnv() (*Client, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, nil
+	}
+	return NewClient(&Config{
+		BaseURL:    os.Getenv("GITHUB_API_URL"),
+		Token:      token,
+		Repository: os.Getenv("GITHUB_REPOSITORY"),
+	})
+}
+
+// Repository returns the "owner/name" the client works on.
+func (c *Client) Repository() string {
+	return c.repository
+}
+
+// ListOpenIssues returns open issues carrying all of the labels, most
+// recently created first. Pull requests are skipped.
+func (c *Client) ListOpenIssues(ctx context.Context, labels []string) ([]*Issue, error) {
+	query := url.Values{
+		"state":     {"open"},
+		"labels":    {strings.Join(labels, ",")},
+		"sort":      {"created"},
+		"direction": {"desc"},
+		"per_page":  {"100"},
+	}
+
+	var issues []*Issue
+	if err := c.do(ctx, http.MethodGet, "/issues?"+query.Encode(), nil, &issues); err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	filtered := issues[:0]
+	for _, issue := range issues {
+		if issue.PullRequest == nil {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered, nil
+}
+
+// CreateIssue opens an issue.
+func (c *Client) CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error) {
+	request := map[string]any{"title": title, "body": body, "labels": labels}
+
+	var issue Issue
+	if err := c.do(ctx, http.MethodPost, "/issues", request, &issue); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return &issue, nil
+}
+
+// CreateComment adds a comment to an issue.
+func (c *Client) CreateComment(ctx context.Context, number int, body string) (*Comment, error) {
+	var comment Comment
+	path := fmt.Sprintf("/issues/%d/comments", number)
+	if err := c.do(ctx, http.MethodPost, path, map[string]string{"body": body}, &comment); err != nil {
+		return nil, fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	return &comment, nil
+}
+
+// do sends a request to a path under the repository and decodes the JSON
+// response into out.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.newRequest(ctx, method, "/repos/"+c.repository+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid GitHub response: %w", err)
+	}
+	return nil
+}
+
+// newRequest builds an API request with the headers GitHub expects. The
+// token is only sent when the client has one.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("User-Agent", "vega-ai-feedback")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return req, nil
+}
+
+// responseError turns rate limiting and other unsuccessful responses into
+// errors.
+func responseError(resp *http.Response) error {
+	if err := rateLimitError(resp); err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+		return fmt.Errorf("GitHub responded with status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	return nil
+}
+
+// rateLimitError recognises GitHub's primary and secondary rate limit
+// responses.
+func rateLimitError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &RateLimitError{Reset: time.Now().Add(time.Duration(seconds) * time.Second)}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return &RateLimitError{Reset: time.Now().Add(time.Minute)}
+		}
+		return &RateLimitError{Reset: time.Unix(reset, 0)}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{Reset: time.Now().Add(time.Minute)}
+	}
+	return nil
+}
+
+// IsRateLimited reports whether err is, or wraps, a RateLimitError.
+func IsRateLimited(err error) bool {
+	var rateErr *RateLimitError
+	return errors.As(err, &rateErr)
+}
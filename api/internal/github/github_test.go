@@ -0,0 +1,225 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github/githubtest"
+)
+
+const testRepo = "benidevo/vega"
+
+func newTestReporter(t *testing.T, maxPerHour int) (*githubtest.Server, *Reporter) {
+	t.Helper()
+
+	srv := githubtest.NewServer("token")
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(&Config{BaseURL: srv.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return srv, NewReporter(client, maxPerHour)
+}
+
+func testReport() *Report {
+	return &Report{
+		Categories:      []string{"port-conflicts", "other", "docker-installation"},
+		SubmittedAt:     time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Helpfulness:     "not-helpful",
+		SetupDifficulty: 8,
+		DocsQuality:     "no-insufficient",
+		Source:          "landing-page",
+	}
+}
+
+func TestReporter_OpensThenComments(t *testing.T) {
+	srv, reporter := newTestReporter(t, 0)
+	ctx := context.Background()
+
+	outcomes, err := reporter.Report(ctx, testReport())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outcomes) != 2 || !outcomes[0].Created || !outcomes[1].Created {
+		t.Fatalf("expected two new issues, got %+v", outcomes)
+	}
+
+	report := testReport()
+	report.Categories = []string{"port-conflicts"}
+	outcomes, err = reporter.Report(ctx, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outcomes) != 1 || outcomes[0].Created || outcomes[0].Issue != 1 {
+		t.Fatalf("expected a comment on the open issue, got %+v", outcomes)
+	}
+
+	issues := srv.Issues(testRepo)
+	if len(issues) != 2 {
+		t.Fatalf("expected one issue per category, got %d", len(issues))
+	}
+	ports := issues[0]
+	if ports.Title != "Setup feedback: Port conflicts" || strings.Join(ports.Labels, ",") != "user-feedback,setup:port-conflicts" {
+		t.Errorf("unexpected issue %+v", ports)
+	}
+	if len(ports.Comments) != 2 {
+		t.Fatalf("expected two comments, got %d", len(ports.Comments))
+	}
+	comment := ports.Comments[0]
+	for _, want := range []string{"2025-03-01", "landing-page", "| not-helpful | 8/10 | no-insufficient |", "Also reported: `other`, `docker-installation`"} {
+		if !strings.Contains(comment, want) {
+			t.Errorf("expected comment to contain %q, got %q", want, comment)
+		}
+	}
+}
+
+func TestReporter_DeduplicatesAgainstOpenIssues(t *testing.T) {
+	srv, reporter := newTestReporter(t, 0)
+	labels := []string{FeedbackLabel, CategoryLabelPrefix + "gemini-api-key"}
+	srv.AddIssue(testRepo, githubtest.Issue{Title: "Old", State: "closed", Labels: labels})
+	srv.AddIssue(testRepo, githubtest.Issue{Title: "PR", Labels: labels, PullRequest: true})
+	canonical := srv.AddIssue(testRepo, githubtest.Issue{Title: "Gemini keys", Labels: labels})
+	srv.AddIssue(testRepo, githubtest.Issue{Title: "Duplicate", Labels: labels})
+
+	report := testReport()
+	report.Categories = []string{"gemini-api-key"}
+	outcomes, err := reporter.Report(context.Background(), report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcomes[0].Created || outcomes[0].Issue != canonical {
+		t.Errorf("expected a comment on the oldest open issue #%d, got %+v", canonical, outcomes[0])
+	}
+}
+
+func TestReporter_QuotesSafely(t *testing.T) {
+	srv, reporter := newTestReporter(t, 0)
+
+	report := testReport()
+	report.Categories = []string{"chrome-extension"}
+	report.Comment = "Ping @benidevo\n![x](https://evil.example/pixel.png)\n```\n# heading"
+	if _, err := reporter.Report(context.Background(), report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Code blocks are shown verbatim, so nothing in the text is rendered.
+	comment := srv.Issues(testRepo)[0].Comments[0]
+	want := "````text\nPing @benidevo\n![x](https://evil.example/pixel.png)\n```\n# heading\n````"
+	if !strings.Contains(comment, want) {
+		t.Errorf("expected the comment in a fence the text cannot close, got %q", comment)
+	}
+}
+
+func TestReporter_EscapesFields(t *testing.T) {
+	srv, reporter := newTestReporter(t, 0)
+
+	report := testReport()
+	report.Categories = []string{"chrome-extension", "[click](https://evil.example) @team"}
+	report.Source = "**@benidevo** [x](https://evil.example)\n# heading"
+	report.Helpfulness = "a | b"
+	report.DocsQuality = "<img src=x>"
+	if _, err := reporter.Report(context.Background(), report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comment := srv.Issues(testRepo)[0].Comments[0]
+	for _, unwanted := range []string{"@benidevo", "@team", "**", " [x]", "\n#", " <img"} {
+		if strings.Contains(comment, unwanted) {
+			t.Errorf("expected %q to be neutralised, got %q", unwanted, comment)
+		}
+	}
+	if !strings.Contains(comment, "| a \\| b | 8/10 | \\<img src=x\\> |") {
+		t.Errorf("expected escaped table cells, got %q", comment)
+	}
+	if !strings.Contains(comment, "Also reported: `other`\n") {
+		t.Errorf("expected unknown categories to be shown as other, got %q", comment)
+	}
+}
+
+func TestReporter_RateLimits(t *testing.T) {
+	srv, reporter := newTestReporter(t, 2)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	reporter.limiter.now = func() time.Time { return now }
+
+	outcomes, err := reporter.Report(context.Background(), testReport())
+	if err != nil || len(outcomes) != 2 {
+		t.Fatalf("expected two reports within the limit, got %v, %v", outcomes, err)
+	}
+
+	requests := srv.Requests()
+	_, err = reporter.Report(context.Background(), testReport())
+	if !IsRateLimited(err) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if srv.Requests() != requests {
+		t.Error("expected no requests while rate limited")
+	}
+
+	// Tokens refill evenly over the hour.
+	now = now.Add(30 * time.Minute)
+	if outcomes, err := reporter.Report(context.Background(), testReport()); len(outcomes) != 1 || !IsRateLimited(err) {
+		t.Errorf("expected one report after half an hour, got %v, %v", outcomes, err)
+	}
+}
+
+func TestReporter_HonoursGitHubRateLimit(t *testing.T) {
+	srv, reporter := newTestReporter(t, 0)
+	srv.RateLimit(1)
+
+	_, err := reporter.Report(context.Background(), testReport())
+	if !IsRateLimited(err) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+
+	requests := srv.Requests()
+	if _, err := reporter.Report(context.Background(), testReport()); !IsRateLimited(err) {
+		t.Errorf("expected to stay blocked until the reset, got %v", err)
+	}
+	if srv.Requests() != requests {
+		t.Error("expected no requests until the reset")
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(&Config{BaseURL: srv.URL, Token: "token"})
+	if _, err := client.ListOpenIssues(context.Background(), nil); !IsRateLimited(err) {
+		t.Errorf("expected a secondary rate limit error, got %v", err)
+	}
+
+	stub := githubtest.NewServer("token")
+	defer stub.Close()
+	bad, _ := NewClient(&Config{BaseURL: stub.URL, Token: "wrong"})
+	if _, err := bad.CreateIssue(context.Background(), "t", "b", nil); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
+
+func TestNewClient_Validation(t *testing.T) {
+	if _, err := NewClient(&Config{}); err == nil {
+		t.Error("expected an error without a token")
+	}
+	if _, err := NewClient(&Config{Token: "t", Repository: "vega"}); err == nil {
+		t.Error("expected an error for a repository without an owner")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	if reporter, err := NewReporterFromEnv(); reporter != nil || err != nil {
+		t.Errorf("expected no reporter without a token, got %v, %v", reporter, err)
+	}
+	t.Setenv("GITHUB_TOKEN", "t")
+	t.Setenv("GITHUB_ISSUES_PER_HOUR", "0")
+	if _, err := NewReporterFromEnv(); err == nil {
+		t.Error("expected an error for an invalid limit")
+	}
+}
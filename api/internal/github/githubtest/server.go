@@ -0,0 +1,316 @@
+// Package githubtest provides an in-memory fake of the parts of the GitHub
+// REST API used by the github package: listing, creating and commenting on
+// issues, and fetching the latest release with conditional requests. It
+// checks the bearer token and can simulate rate limiting.
+package githubtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issue is an issue held by the fake server.
+type Issue struct {
+	Number      int
+	Title       string
+	Body        string
+	State       string
+	Labels      []string
+	PullRequest bool
+	Comments    []string
+}
+
+// Release is a release held by the fake server.
+type Release struct {
+	TagName     string
+	Name        string
+	Body        string
+	Draft       bool
+	Prerelease  bool
+	PublishedAt time.Time
+	// Assets are file names; each is served as a 1 KiB download.
+	Assets []string
+}
+
+// Server is an in-memory GitHub API server for one token.
+type Server struct {
+	*httptest.Server
+
+	token string
+
+	mu       sync.Mutex
+	issues   map[string][]*Issue
+	releases map[string][]*Release
+	// rateLimited is how many further requests are refused with a primary
+	// rate limit response.
+	rateLimited int
+	requests    int
+}
+
+// NewServer starts a server accepting the given token. Call Close when done.
+func NewServer(token string) *Server {
+	s := &Server{token: token, issues: map[string][]*Issue{}, releases: map[string][]*Release{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// AddIssue stores an issue in the repository ("owner/name") and returns its
+// number.
+func (s *Server) AddIssue(repository string, issue Issue) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue.Number = len(s.issues[repository]) + 1
+	if issue.State == "" {
+		issue.State = "open"
+	}
+	s.issues[repository] = append(s.issues[repository], &issue)
+	return issue.Number
+}
+
+// Issues returns copies of the issues in the repository, in creation order.
+func (s *Server) Issues(repository string) []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var issues []Issue
+	for _, issue := range s.issues[repository] {
+		copied := *issue
+		copied.Labels = slices.Clone(issue.Labels)
+		copied.Comments = slices.Clone(issue.Comments)
+		issues = append(issues, copied)
+	}
+	return issues
+}
+
+// AddRelease publishes a release in the repository. The latest release is
+// the last one added that is neither a draft nor a prerelease.
+func (s *Server) AddRelease(repository string, release Release) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	release.Assets = slices.Clone(release.Assets)
+	s.releases[repository] = append(s.releases[repository], &release)
+}
+
+// RateLimit makes the next n requests fail as if the rate limit was spent.
+func (s *Server) RateLimit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimited = n
+}
+
+// Requests returns how many API requests were received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	// Releases of public repositories can be read without a token.
+	auth := r.Header.Get("Authorization")
+	public := r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/releases/")
+	if auth != "Bearer "+s.token && !(public && auth == "") {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	if s.rateLimited > 0 {
+		s.rateLimited--
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		writeError(w, http.StatusForbidden, "API rate limit exceeded")
+		return
+	}
+
+	// Paths look like /repos/{owner}/{name}/issues[/{number}/comments] or
+	// /repos/{owner}/{name}/releases/latest.
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 5 && parts[0] == "repos" && parts[3] == "releases" && parts[4] == "latest" && r.Method == http.MethodGet {
+		s.latestRelease(w, r, parts[1]+"/"+parts[2])
+		return
+	}
+	if len(parts) < 4 || parts[0] != "repos" || parts[3] != "issues" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	repository := parts[1] + "/" + parts[2]
+
+	switch {
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		s.listIssues(w, r, repository)
+	case len(parts) == 4 && r.Method == http.MethodPost:
+		s.createIssue(w, r, repository)
+	case len(parts) == 6 && parts[5] == "comments" && r.Method == http.MethodPost:
+		s.createComment(w, r, repository, parts[4])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request, repository string) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	var labels []string
+	if value := query.Get("labels"); value != "" {
+		labels = strings.Split(value, ",")
+	}
+
+	result := []map[string]any{}
+	for i := len(s.issues[repository]) - 1; i >= 0; i-- {
+		issue := s.issues[repository][i]
+		if state != "all" && issue.State != state {
+			continue
+		}
+		if !containsAll(issue.Labels, labels) {
+			continue
+		}
+		result = append(result, s.issueJSON(repository, issue))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request, repository string) {
+	var request struct {
+		Title  string   `json:"title"`
+		Body   string   `json:"body"`
+		Labels []string `json:"labels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Title == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+
+	issue := &Issue{
+		Number: len(s.issues[repository]) + 1,
+		Title:  request.Title,
+		Body:   request.Body,
+		State:  "open",
+		Labels: request.Labels,
+	}
+	s.issues[repository] = append(s.issues[repository], issue)
+	writeJSON(w, http.StatusCreated, s.issueJSON(repository, issue))
+}
+
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request, repository, number string) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(s.issues[repository]) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var request struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Body == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+
+	issue := s.issues[repository][n-1]
+	issue.Comments = append(issue.Comments, request.Body)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":       len(issue.Comments),
+		"body":     request.Body,
+		"html_url": fmt.Sprintf("%s/%s/issues/%d#issuecomment-%d", s.URL, repository, n, len(issue.Comments)),
+	})
+}
+
+func (s *Server) latestRelease(w http.ResponseWriter, r *http.Request, repository string) {
+	var latest *Release
+	for _, release := range s.releases[repository] {
+		if !release.Draft && !release.Prerelease {
+			latest = release
+		}
+	}
+	if latest == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	assets := []map[string]any{}
+	for _, name := range latest.Assets {
+		assets = append(assets, map[string]any{
+			"name":                 name,
+			"size":                 1024,
+			"content_type":         "application/octet-stream",
+			"browser_download_url": fmt.Sprintf("%s/%s/releases/download/%s/%s", s.URL, repository, latest.TagName, name),
+		})
+	}
+	body, err := json.Marshal(map[string]any{
+		"tag_name":     latest.TagName,
+		"name":         latest.Name,
+		"body":         latest.Body,
+		"html_url":     fmt.Sprintf("%s/%s/releases/tag/%s", s.URL, repository, latest.TagName),
+		"published_at": latest.PublishedAt.UTC().Format(time.RFC3339),
+		"assets":       assets,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func (s *Server) issueJSON(repository string, issue *Issue) map[string]any {
+	labels := []map[string]string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	result := map[string]any{
+		"number":   issue.Number,
+		"title":    issue.Title,
+		"body":     issue.Body,
+		"state":    issue.State,
+		"labels":   labels,
+		"html_url": fmt.Sprintf("%s/%s/issues/%d", s.URL, repository, issue.Number),
+	}
+	if issue.PullRequest {
+		result["pull_request"] = map[string]any{}
+	}
+	return result
+}
+
+func containsAll(have, want []string) bool {
+	for _, label := range want {
+		if !slices.Contains(have, label) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
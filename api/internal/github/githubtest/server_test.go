@@ -0,0 +1,92 @@
+package githubtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer_RequiresToken(t *testing.T) {
+	srv := NewServer("token")
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/repos/o/r/issues", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_CreateAndComment(t *testing.T) {
+	srv := NewServer("token")
+	defer srv.Close()
+
+	post := func(path, body string) int {
+		req, _ := http.NewRequest("POST", srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post("/repos/o/r/issues", `{"title":"Bug","labels":["a"]}`); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if status := post("/repos/o/r/issues/1/comments", `{"body":"more"}`); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if status := post("/repos/o/r/issues/2/comments", `{"body":"more"}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing issue, got %d", status)
+	}
+
+	issues := srv.Issues("o/r")
+	if len(issues) != 1 || issues[0].Title != "Bug" || len(issues[0].Comments) != 1 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
+
+func TestServer_LatestRelease(t *testing.T) {
+	srv := NewServer("token")
+	defer srv.Close()
+
+	get := func(etag string) *http.Response {
+		req, _ := http.NewRequest("GET", srv.URL+"/repos/o/r/releases/latest", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := get(""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without releases, got %d", resp.StatusCode)
+	}
+
+	srv.AddRelease("o/r", Release{TagName: "v1.0.0"})
+	srv.AddRelease("o/r", Release{TagName: "v1.1.0-rc1", Prerelease: true})
+	resp := get("")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("expected a release with an ETag, got %d", resp.StatusCode)
+	}
+	if resp := get(etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", resp.StatusCode)
+	}
+
+	srv.AddRelease("o/r", Release{TagName: "v1.1.0"})
+	if resp := get(etag); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after a new release, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,352 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repositories whose releases are shown on the landing page, unless
+// GITHUB_VEGA_REPOSITORY or GITHUB_EXTENSION_REPOSITORY override them.
+const (
+	DefaultVegaRepository      = "benidevo/vega-ai"
+	DefaultExtensionRepository = "benidevo/vega-ai-extension"
+)
+
+// Defaults applied by NewReleaseCache.
+const (
+	DefaultReleaseTTL = 10 * time.Minute
+	// DefaultReleaseStaleTTL is how long past its TTL a release is still
+	// served while it is refreshed in the background.
+	DefaultReleaseStaleTTL = 24 * time.Hour
+)
+
+// maxNotesSummary caps the release notes summary, in runes.
+const maxNotesSummary = 400
+
+// ErrNotModified is returned by LatestRelease when the release still matches
+// the ETag sent with the request.
+var ErrNotModified = errors.New("release not modified")
+
+// Release is the subset of a GitHub release used here.
+type Release struct {
+	TagName     string         `json:"tag_name"`
+	Name        string         `json:"name"`
+	Body        string         `json:"body"`
+	HTMLURL     string         `json:"html_url"`
+	PublishedAt time.Time      `json:"published_at"`
+	Assets      []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	ContentType        string `json:"content_type"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// LatestRelease fetches the latest published release of repository
+// ("owner/name"). When etag is set and the release has not changed it
+// returns ErrNotModified; such conditional requests do not count against
+// GitHub's rate limit. The ETag of the response is returned with the
+// release.
+func (c *Client) LatestRelease(ctx context.Context, repository, etag string) (*Release, string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/repos/"+repository+"/releases/latest", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch latest release of %s: %w", repository, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrNotModified
+	}
+	if err := responseError(resp); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch latest release of %s: %w", repository, err)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, "", fmt.Errorf("invalid release of %s: %w", repository, err)
+	}
+	return &release, resp.Header.Get("ETag"), nil
+}
+
+// ReleaseSummary is a release as shown on the landing page.
+type ReleaseSummary struct {
+	Repository  string         `json:"repository"`
+	Version     string         `json:"version"`
+	Name        string         `json:"name"`
+	PublishedAt time.Time      `json:"publishedAt"`
+	URL         string         `json:"url"`
+	Notes       string         `json:"notes"`
+	Assets      []AssetSummary `json:"assets"`
+	// FetchedAt is when GitHub last confirmed the release was current.
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Stale is set when the release is served past its TTL, either while
+	// it is being refreshed or because refreshing failed.
+	Stale bool `json:"stale"`
+}
+
+// AssetSummary is a downloadable file of a release.
+type AssetSummary struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// ReleaseCacheConfig configures a ReleaseCache.
+type ReleaseCacheConfig struct {
+	Client *Client
+	// TTL is how long a release is served without asking GitHub. Defaults
+	// to DefaultReleaseTTL.
+	TTL time.Duration
+	// StaleTTL is how long past TTL a release is served while it is
+	// refreshed in the background. Defaults to DefaultReleaseStaleTTL.
+	StaleTTL time.Duration
+}
+
+// ReleaseCache serves the latest release of repositories from memory.
+// Fresh entries are served as is; entries past their TTL are served while
+// one background request revalidates them with their ETag; older entries
+// are refreshed before they are served. When GitHub cannot be reached, the
+// last known release is served rather than an error.
+type ReleaseCache struct {
+	client   *Client
+	ttl      time.Duration
+	staleTTL time.Duration
+	now      func() time.Time
+	// refreshed is called after each background refresh, for tests.
+	refreshed func(repository string)
+
+	mu      sync.Mutex
+	entries map[string]*releaseEntry
+}
+
+type releaseEntry struct {
+	summary    *ReleaseSummary
+	etag       string
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+// NewReleaseCache returns an empty cache.
+func NewReleaseCache(config *ReleaseCacheConfig) (*ReleaseCache, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("GitHub client is required")
+	}
+	c := &ReleaseCache{
+		client:   config.Client,
+		ttl:      config.TTL,
+		staleTTL: config.StaleTTL,
+		now:      time.Now,
+		entries:  map[string]*releaseEntry{},
+	}
+	if c.ttl <= 0 {
+		c.ttl = DefaultReleaseTTL
+	}
+	if c.staleTTL <= 0 {
+		c.staleTTL = DefaultReleaseStaleTTL
+	}
+	return c, nil
+}
+
+// NewReleaseCacheFromEnv builds a cache using GITHUB_API_URL and, when set,
+// GITHUB_TOKEN. RELEASES_CACHE_TTL and RELEASES_STALE_TTL override the
+// defaults as Go durations such as "15m".
+func NewReleaseCacheFromEnv() (*ReleaseCache, error) {
+	client, err := NewReadOnlyClient(&Config{
+		BaseURL: os.Getenv("GITHUB_API_URL"),
+		Token:   os.Getenv("GITHUB_TOKEN"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ReleaseCacheConfig{Client: client}
+	for key, target := range map[string]*time.Duration{
+		"RELEASES_CACHE_TTL": &config.TTL,
+		"RELEASES_STALE_TTL": &config.StaleTTL,
+	} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s must be a positive duration, got %q", key, value)
+		}
+		*target = d
+	}
+	return NewReleaseCache(config)
+}
+
+// ReleaseRepositories returns the Vega and extension repositories, from
+// GITHUB_VEGA_REPOSITORY and GITHUB_EXTENSION_REPOSITORY or the defaults.
+func ReleaseRepositories() (vega, extension string) {
+	vega, extension = os.Getenv("GITHUB_VEGA_REPOSITORY"), os.Getenv("GITHUB_EXTENSION_REPOSITORY")
+	if vega == "" {
+		vega = DefaultVegaRepository
+	}
+	if extension == "" {
+		extension = DefaultExtensionRepository
+	}
+	return vega, extension
+}
+
+// Latest returns the latest release of repository.
+func (c *ReleaseCache) Latest(ctx context.Context, repository string) (*ReleaseSummary, error) {
+	c.mu.Lock()
+	entry := c.entries[repository]
+	if entry != nil {
+		age := c.now().Sub(entry.fetchedAt)
+		if age < c.ttl {
+			summary := *entry.summary
+			c.mu.Unlock()
+			return &summary, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			if !entry.refreshing {
+				entry.refreshing = true
+				go c.refreshInBackground(repository)
+			}
+			summary := *entry.summary
+			summary.Stale = true
+			c.mu.Unlock()
+			return &summary, nil
+		}
+	}
+	c.mu.Unlock()
+
+	summary, err := c.refresh(ctx, repository)
+	if err == nil {
+		return summary, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry := c.entries[repository]; entry != nil {
+		stale := *entry.summary
+		stale.Stale = true
+		return &stale, nil
+	}
+	return nil, err
+}
+
+func (c *ReleaseCache) refreshInBackground(repository string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	_, _ = c.refresh(ctx, repository)
+
+	c.mu.Lock()
+	if entry := c.entries[repository]; entry != nil {
+		entry.refreshing = false
+	}
+	callback := c.refreshed
+	c.mu.Unlock()
+
+	if callback != nil {
+		callback(repository)
+	}
+}
+
+// refresh asks GitHub for the latest release, sending the cached ETag so
+// that an unchanged release only renews the entry.
+func (c *ReleaseCache) refresh(ctx context.Context, repository string) (*ReleaseSummary, error) {
+	c.mu.Lock()
+	etag := ""
+	if entry := c.entries[repository]; entry != nil {
+		etag = entry.etag
+	}
+	c.mu.Unlock()
+
+	release, newETag, err := c.client.LatestRelease(ctx, repository, etag)
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[repository]
+	switch {
+	case errors.Is(err, ErrNotModified) && entry != nil:
+		entry.fetchedAt = now
+		entry.summary.FetchedAt = now
+	case err != nil:
+		return nil, err
+	default:
+		summary := summarizeRelease(repository, release)
+		summary.FetchedAt = now
+		if entry == nil {
+			entry = &releaseEntry{}
+			c.entries[repository] = entry
+		}
+		entry.summary, entry.etag, entry.fetchedAt = summary, newETag, now
+	}
+
+	summary := *entry.summary
+	return &summary, nil
+}
+
+func summarizeRelease(repository string, release *Release) *ReleaseSummary {
+	summary := &ReleaseSummary{
+		Repository:  repository,
+		Version:     release.TagName,
+		Name:        release.Name,
+		PublishedAt: release.PublishedAt,
+		URL:         release.HTMLURL,
+		Notes:       summarizeNotes(release.Body),
+		Assets:      []AssetSummary{},
+	}
+	if summary.Name == "" {
+		summary.Name = release.TagName
+	}
+	for _, asset := range release.Assets {
+		summary.Assets = append(summary.Assets, AssetSummary{
+			Name: asset.Name,
+			Size: asset.Size,
+			URL:  asset.BrowserDownloadURL,
+		})
+	}
+	return summary
+}
+
+// summarizeNotes returns the opening paragraph of markdown release notes as
+// plain text, skipping headings and capped at maxNotesSummary runes.
+func summarizeNotes(body string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--"):
+			continue
+		case line == "" && len(lines) > 0:
+			return truncateNotes(strings.Join(lines, " "))
+		case line != "":
+			lines = append(lines, line)
+		}
+	}
+	return truncateNotes(strings.Join(lines, " "))
+}
+
+func truncateNotes(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxNotesSummary {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxNotesSummary])) + "…"
+}
@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/github/githubtest"
+)
+
+const releaseRepo = "benidevo/vega-ai"
+
+func newTestReleaseCache(t *testing.T) (*githubtest.Server, *ReleaseCache, *time.Time, chan string) {
+	t.Helper()
+
+	srv := githubtest.NewServer("token")
+	t.Cleanup(srv.Close)
+
+	client, err := NewReadOnlyClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache, err := NewReleaseCache(&ReleaseCacheConfig{Client: client, TTL: time.Minute, StaleTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	refreshed := make(chan string, 4)
+	cache.refreshed = func(repository string) { refreshed <- repository }
+	return srv, cache, &now, refreshed
+}
+
+func waitForRefresh(t *testing.T, refreshed chan string) {
+	t.Helper()
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a background refresh")
+	}
+}
+
+func TestReleaseCache_Lifecycle(t *testing.T) {
+	srv, cache, now, refreshed := newTestReleaseCache(t)
+	ctx := context.Background()
+
+	srv.AddRelease(releaseRepo, githubtest.Release{
+		TagName:     "v1.2.0",
+		Body:        "## What's new\r\n\r\nFaster job capture and a\nnew dashboard.\r\n\r\n- Fix login",
+		PublishedAt: time.Date(2025, 2, 20, 9, 0, 0, 0, time.UTC),
+		Assets:      []string{"vega-ai-linux-amd64.tar.gz"},
+	})
+
+	release, err := cache.Latest(ctx, releaseRepo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if release.Version != "v1.2.0" || release.Name != "v1.2.0" || release.Stale || release.Notes != "Faster job capture and a new dashboard." {
+		t.Fatalf("unexpected release %+v", release)
+	}
+	if len(release.Assets) != 1 || !strings.HasSuffix(release.Assets[0].URL, "/releases/download/v1.2.0/vega-ai-linux-amd64.tar.gz") {
+		t.Errorf("unexpected assets %+v", release.Assets)
+	}
+
+	// Fresh entries are served from memory.
+	*now = now.Add(30 * time.Second)
+	if _, err := cache.Latest(ctx, releaseRepo); err != nil || srv.Requests() != 1 {
+		t.Fatalf("expected a cached release, got %d requests, %v", srv.Requests(), err)
+	}
+
+	// Past the TTL the old release is served while it is revalidated.
+	srv.AddRelease(releaseRepo, githubtest.Release{TagName: "v1.3.0"})
+	*now = now.Add(time.Minute)
+	stale, err := cache.Latest(ctx, releaseRepo)
+	if err != nil || !stale.Stale || stale.Version != "v1.2.0" {
+		t.Fatalf("expected the stale release, got %+v, %v", stale, err)
+	}
+	waitForRefresh(t, refreshed)
+
+	fresh, err := cache.Latest(ctx, releaseRepo)
+	if err != nil || fresh.Stale || fresh.Version != "v1.3.0" {
+		t.Fatalf("expected the refreshed release, got %+v, %v", fresh, err)
+	}
+
+	// An unchanged release is renewed with a conditional request.
+	*now = now.Add(2 * time.Minute)
+	if _, err := cache.Latest(ctx, releaseRepo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForRefresh(t, refreshed)
+	if renewed, _ := cache.Latest(ctx, releaseRepo); renewed.Stale || !renewed.FetchedAt.Equal(*now) {
+		t.Errorf("expected the release to be renewed, got %+v", renewed)
+	}
+	if srv.Requests() != 3 {
+		t.Errorf("expected 3 requests, got %d", srv.Requests())
+	}
+}
+
+func TestReleaseCache_ServesStaleOnError(t *testing.T) {
+	srv, cache, now, _ := newTestReleaseCache(t)
+	ctx := context.Background()
+
+	if _, err := cache.Latest(ctx, releaseRepo); err == nil {
+		t.Fatal("expected an error without any release")
+	}
+
+	srv.AddRelease(releaseRepo, githubtest.Release{TagName: "v1.0.0"})
+	if _, err := cache.Latest(ctx, releaseRepo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Beyond the stale window the release is refreshed before serving, and
+	// the last known release is kept when GitHub refuses.
+	*now = now.Add(2 * time.Hour)
+	srv.RateLimit(1)
+	release, err := cache.Latest(ctx, releaseRepo)
+	if err != nil || !release.Stale || release.Version != "v1.0.0" {
+		t.Errorf("expected the last known release, got %+v, %v", release, err)
+	}
+}
+
+func TestSummarizeNotes(t *testing.T) {
+	tests := map[string]string{
+		"": "",
+		"# v1\n\n<!-- generated -->\nFirst.\nSecond.\n\nLater.": "First. Second.",
+		strings.Repeat("a", maxNotesSummary+10):                 strings.Repeat("a", maxNotesSummary) + "…",
+	}
+	for body, want := range tests {
+		if got := summarizeNotes(body); got != want {
+			t.Errorf("summarizeNotes(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+func TestNewReleaseCacheFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("RELEASES_CACHE_TTL", "15m")
+	t.Setenv("RELEASES_STALE_TTL", "")
+
+	cache, err := NewReleaseCacheFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.ttl != 15*time.Minute || cache.staleTTL != DefaultReleaseStaleTTL {
+		t.Errorf("unexpected cache %+v", cache)
+	}
+
+	t.Setenv("RELEASES_STALE_TTL", "soon")
+	if _, err := NewReleaseCacheFromEnv(); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
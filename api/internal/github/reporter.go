@@ -0,0 +1,320 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Labels applied to every issue the Reporter opens. The category label is
+// CategoryLabelPrefix followed by the setup issue value, such as
+// "setup:port-conflicts".
+const (
+	FeedbackLabel       = "user-feedback"
+	CategoryLabelPrefix = "setup:"
+)
+
+// DefaultMaxPerHour bounds the issues and comments a Reporter writes per
+// hour.
+const DefaultMaxPerHour = 30
+
+// maxCommentText caps quoted feedback in comments.
+const maxCommentText = 2000
+
+// Categories maps the setup issue values of the feedback form to the names
+// used in issue titles. Values not listed here, such as "other", are not
+// reported.
+var Categories = map[string]string{
+	"docker-installation":   "Docker installation",
+	"gemini-api-key":        "Google Gemini API key setup",
+	"chrome-extension":      "Chrome extension installation",
+	"environment-variables": "Environment variables",
+	"port-conflicts":        "Port conflicts",
+}
+
+// Report is an anonymized feedback submission. It never carries an email
+// address or a submission ID.
+type Report struct {
+	// Categories are setup issue values; unknown values are ignored.
+	Categories      []string
+	SubmittedAt     time.Time
+	Helpfulness     string
+	SetupDifficulty int
+	DocsQuality     string
+	Source          string
+	// Comment is free text to quote. Callers only set it when the submitter
+	// agreed to be quoted publicly. Once posted it is outside the feedback
+	// store: a privacy erasure does not remove it from GitHub.
+	Comment string
+}
+
+// Outcome is what the Reporter did for one category.
+type Outcome struct {
+	Category string
+	Issue    int
+	// Created is set when a new issue was opened rather than an open one
+	// commented on.
+	Created bool
+}
+
+// Reporter files reports as one open issue per category, commenting on the
+// existing issue when there is one.
+type Reporter struct {
+	client  *Client
+	limiter *rateLimiter
+
+	// mu serialises reports so that concurrent submissions for the same
+	// category do not both open an issue.
+	mu sync.Mutex
+}
+
+// NewReporter returns a Reporter writing at most maxPerHour issues and
+// comments per hour, or DefaultMaxPerHour when maxPerHour is not positive.
+func NewReporter(client *Client, maxPerHour int) *Reporter {
+	if maxPerHour <= 0 {
+		maxPerHour = DefaultMaxPerHour
+	}
+	return &Reporter{client: client, limiter: newRateLimiter(maxPerHour, time.Hour)}
+}
+
+// NewReporterFromEnv builds a Reporter from the NewClientFromEnv variables
+// and GITHUB_ISSUES_PER_HOUR. It returns nil without error when GitHub is
+// not configured.
+func NewReporterFromEnv() (*Reporter, error) {
+	client, err := NewClientFromEnv()
+	if err != nil || client == nil {
+		return nil, err
+	}
+
+	maxPerHour := 0
+	if value := os.Getenv("GITHUB_ISSUES_PER_HOUR"); value != "" {
+		maxPerHour, err = strconv.Atoi(value)
+		if err != nil || maxPerHour <= 0 {
+			return nil, fmt.Errorf("GITHUB_ISSUES_PER_HOUR must be a positive number, got %q", value)
+		}
+	}
+	return NewReporter(client, maxPerHour), nil
+}
+
+// Report files the report under each of its known categories. It stops at
+// the first error; outcomes for the categories already filed are returned
+// with it. A *RateLimitError means the local or GitHub limit was reached.
+func (r *Reporter) Report(ctx context.Context, report *Report) ([]*Outcome, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var categories []string
+	for _, category := range report.Categories {
+		if _, ok := Categories[category]; ok && !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+
+	var outcomes []*Outcome
+	for _, category := range categories {
+		if reset, ok := r.limiter.allow(); !ok {
+			return outcomes, &RateLimitError{Reset: reset}
+		}
+
+		outcome, err := r.file(ctx, category, report)
+		if err != nil {
+			var rateErr *RateLimitError
+			if errors.As(err, &rateErr) {
+				r.limiter.blockUntil(rateErr.Reset)
+			}
+			return outcomes, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
+func (r *Reporter) file(ctx context.Context, category string, report *Report) (*Outcome, error) {
+	labels := []string{FeedbackLabel, CategoryLabelPrefix + category}
+	outcome := &Outcome{Category: category}
+
+	open, err := r.client.ListOpenIssues(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		// Comment on the oldest open issue so that duplicates opened by
+		// hand do not split the reports.
+		oldest := slices.MinFunc(open, func(a, b *Issue) int { return a.Number - b.Number })
+		outcome.Issue = oldest.Number
+	} else {
+		issue, err := r.client.CreateIssue(ctx, issueTitle(category), issueBody(category), labels)
+		if err != nil {
+			return nil, err
+		}
+		outcome.Issue = issue.Number
+		outcome.Created = true
+	}
+
+	if _, err := r.client.CreateComment(ctx, outcome.Issue, commentBody(category, report)); err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+func issueTitle(category string) string {
+	return "Setup feedback: " + Categories[category]
+}
+
+func issueBody(category string) string {
+	return fmt.Sprintf(`Users of the feedback form on the Vega AI landing page reported problems with **%s** while setting up Vega AI.
+
+Each comment below is one anonymized report. Email addresses and submission IDs are never included, and comments are only quoted when the submitter agreed to be quoted publicly.
+
+This issue was opened automatically. Close it once the underlying problem is fixed; later reports will open a new issue.
+`, Categories[category])
+}
+
+func commentBody(category string, report *Report) string {
+	var b strings.Builder
+
+	date := "an unknown date"
+	if !report.SubmittedAt.IsZero() {
+		date = report.SubmittedAt.UTC().Format("2006-01-02")
+	}
+	fmt.Fprintf(&b, "New anonymized report from %s on %s.\n\n", valueOr(inline(report.Source), "an unknown source"), date)
+
+	b.WriteString("| Helpfulness | Setup difficulty | Docs quality |\n|---|---|---|\n")
+	fmt.Fprintf(&b, "| %s | %d/10 | %s |\n", cell(report.Helpfulness), report.SetupDifficulty, cell(report.DocsQuality))
+
+	// Unknown values are shown as "other" rather than echoed.
+	var others []string
+	for _, other := range report.Categories {
+		if _, ok := Categories[other]; !ok {
+			other = "other"
+		}
+		if other != category && !slices.Contains(others, "`"+other+"`") {
+			others = append(others, "`"+other+"`")
+		}
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(&b, "\nAlso reported: %s\n", strings.Join(others, ", "))
+	}
+
+	if text := quote(report.Comment); text != "" {
+		b.WriteString("\n" + text + "\n")
+	}
+	return b.String()
+}
+
+// quote renders free text as a fenced code block, which GitHub shows
+// verbatim: mentions, links, images and HTML in the text are not rendered.
+// The fence is longer than any run of backticks in the text, so the text
+// cannot close it.
+func quote(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+	if runes := []rune(text); len(runes) > maxCommentText {
+		text = string(runes[:maxCommentText]) + "…"
+	}
+
+	longest, run := 0, 0
+	for _, r := range text {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + "text\n" + text + "\n" + fence
+}
+
+// maxInlineText bounds short values such as the source of a report.
+const maxInlineText = 64
+
+// markdownEscaper escapes the characters that could format, link or break
+// out of short values, and breaks up mentions.
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "\\<", ">", "\\>", "|", "\\|", "~", "\\~", "#", "\\#", "!", "\\!",
+	"@", "@​", "\n", " ", "\r", " ",
+)
+
+// inline makes a short value safe inside a line of Markdown.
+func inline(value string) string {
+	value = strings.TrimSpace(value)
+	if runes := []rune(value); len(runes) > maxInlineText {
+		value = string(runes[:maxInlineText]) + "…"
+	}
+	return markdownEscaper.Replace(value)
+}
+
+// cell makes a value safe inside a Markdown table cell.
+func cell(value string) string {
+	return valueOr(inline(value), "—")
+}
+
+func valueOr(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+// rateLimiter is a token bucket refilled evenly over its period.
+type rateLimiter struct {
+	mu           sync.Mutex
+	capacity     float64
+	tokens       float64
+	perSecond    float64
+	last         time.Time
+	blockedUntil time.Time
+	now          func() time.Time
+}
+
+func newRateLimiter(limit int, period time.Duration) *rateLimiter {
+	return &rateLimiter{
+		capacity:  float64(limit),
+		tokens:    float64(limit),
+		perSecond: float64(limit) / period.Seconds(),
+		now:       time.Now,
+	}
+}
+
+// allow takes a token. When none is left it returns when the next one is
+// due.
+func (l *rateLimiter) allow() (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil, false
+	}
+	if !l.last.IsZero() {
+		l.tokens = min(l.capacity, l.tokens+now.Sub(l.last).Seconds()*l.perSecond)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		wait := time.Duration((1 - l.tokens) / l.perSecond * float64(time.Second))
+		return now.Add(wait), false
+	}
+	l.tokens--
+	return time.Time{}, true
+}
+
+// blockUntil refuses every request until t, after GitHub reported its own
+// limit.
+func (l *rateLimiter) blockUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.blockedUntil) {
+		l.blockedUntil = t
+	}
+}
@@ -0,0 +1,114 @@
+package guidance
+
+var (
+	setupGuide   = Link{Title: "Vega AI setup guide", URL: "https://github.com/benidevo/vega#readme"}
+	issueTracker = Link{Title: "Vega AI issue tracker", URL: "https://github.com/benidevo/vega/issues"}
+
+	getHelp = Suggestion{
+		ID:      "get-help",
+		Title:   "Ask the maintainers",
+		Summary: "If Vega AI still does not work for you, open an issue and we will help.",
+		Steps: []string{
+			"Collect the output of `docker logs vega-ai`, removing your API key and password.",
+			"Open an issue describing what you tried and what happened.",
+		},
+		Links: []Link{issueTracker},
+	}
+)
+
+// defaultRules cover each setup issue on the feedback form, then low
+// helpfulness and hard setups.
+var defaultRules = []*Rule{
+	{
+		When: Condition{SetupIssues: []string{"port-conflicts"}},
+		Suggestion: Suggestion{
+			ID:      "port-conflicts",
+			Title:   "Run Vega AI on a free port",
+			Summary: "Vega AI listens on port 8765. If another program already uses it, map a different host port.",
+			Steps: []string{
+				"Find what uses the port with `lsof -i :8765` on macOS or Linux, or `netstat -ano | findstr 8765` on Windows.",
+				"Remove the old container with `docker rm -f vega-ai`.",
+				"Start it again with another host port, for example `-p 8080:8765`, and open http://localhost:8080.",
+				"Update the connection in the browser extension to the new port.",
+			},
+			Links: []Link{setupGuide},
+		},
+	},
+	{
+		When: Condition{SetupIssues: []string{"gemini-api-key"}},
+		Suggestion: Suggestion{
+			ID:      "gemini-api-key",
+			Title:   "Check your Gemini API key",
+			Summary: "Vega AI reads the key from GEMINI_API_KEY in your config file when the container starts.",
+			Steps: []string{
+				"Create a key in Google AI Studio and copy it in full.",
+				"Put it in the config file as `GEMINI_API_KEY=your-key`, without quotes or spaces around the `=`.",
+				"Recreate the container so it picks up the change: `docker rm -f vega-ai`, then run the docker command again.",
+			},
+			Links: []Link{{Title: "Google AI Studio API keys", URL: "https://aistudio.google.com/app/apikey"}, setupGuide},
+		},
+	},
+	{
+		When: Condition{SetupIssues: []string{"docker-installation"}},
+		Suggestion: Suggestion{
+			ID:      "docker-installation",
+			Title:   "Get Docker running",
+			Summary: "Vega AI runs as a Docker container, so Docker must be installed and its daemon running.",
+			Steps: []string{
+				"Install Docker Desktop on macOS or Windows, or Docker Engine on Linux.",
+				"Check the installation with `docker run hello-world`.",
+				"On Linux, add yourself to the docker group with `sudo usermod -aG docker $USER` and log in again to run Docker without sudo.",
+			},
+			Links: []Link{{Title: "Get Docker", URL: "https://docs.docker.com/get-docker/"}, setupGuide},
+		},
+	},
+	{
+		When: Condition{SetupIssues: []string{"chrome-extension"}},
+		Suggestion: Suggestion{
+			ID:      "chrome-extension",
+			Title:   "Load the browser extension",
+			Summary: "The extension is installed unpacked from its release ZIP and talks to your running Vega AI backend.",
+			Steps: []string{
+				"Download the latest extension ZIP and unzip it.",
+				"Open chrome://extensions, turn on Developer mode and choose Load unpacked with the unzipped folder.",
+				"Make sure Vega AI is running, then set the connection in the extension to localhost:8765.",
+			},
+			Links: []Link{{Title: "Extension releases", URL: "https://github.com/benidevo/vega-ai-extension/releases/latest"}},
+		},
+	},
+	{
+		When: Condition{SetupIssues: []string{"environment-variables"}},
+		Suggestion: Suggestion{
+			ID:      "environment-variables",
+			Title:   "Fix the config file",
+			Summary: "Docker reads the config file line by line and takes values literally.",
+			Steps: []string{
+				"Write one `KEY=value` per line, with no quotes, no spaces around the `=` and no trailing spaces.",
+				"Save the file with Unix line endings; Windows line endings end up in the values.",
+				"Run the docker command from the folder holding the file, since `--env-file config` is a relative path.",
+				"Recreate the container after every change: `docker rm -f vega-ai`, then run the docker command again.",
+			},
+			Links: []Link{setupGuide},
+		},
+	},
+	{When: Condition{Helpfulness: []string{"not-helpful"}}, Suggestion: getHelp},
+	{When: Condition{MinSetupDifficulty: 8}, Suggestion: getHelp},
+	{
+		When: Condition{DocsQuality: []string{"no-insufficient"}},
+		Suggestion: Suggestion{
+			ID:      "setup-guide",
+			Title:   "Read the full setup guide",
+			Summary: "The repository README covers configuration options and troubleshooting in more depth than the quick start.",
+			Links:   []Link{setupGuide},
+		},
+	},
+}
+
+// Default returns the built-in knowledge base.
+func Default() *KnowledgeBase {
+	kb, err := New(defaultRules, DefaultMaxSuggestions)
+	if err != nil {
+		panic(err)
+	}
+	return kb
+}
@@ -0,0 +1,150 @@
+// Package guidance maps feedback to troubleshooting suggestions. A
+// KnowledgeBase is an ordered list of rules, each pairing a condition on a
+// submission with a curated fix and documentation links.
+package guidance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+)
+
+// DefaultMaxSuggestions caps the suggestions returned for one submission.
+const DefaultMaxSuggestions = 4
+
+// Link points to documentation.
+type Link struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Suggestion is a fix shown to the submitter.
+type Suggestion struct {
+	// ID identifies the suggestion. Rules sharing an ID are alternatives
+	// and the suggestion is returned at most once.
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Summary string   `json:"summary,omitempty"`
+	Steps   []string `json:"steps,omitempty"`
+	Links   []Link   `json:"links,omitempty"`
+}
+
+// Condition selects submissions. Each non-empty field must match, and list
+// fields match when the submission has any of the listed values.
+type Condition struct {
+	SetupIssues        []string `json:"setupIssues,omitempty"`
+	Helpfulness        []string `json:"helpfulness,omitempty"`
+	DocsQuality        []string `json:"docsQuality,omitempty"`
+	MinSetupDifficulty int      `json:"minSetupDifficulty,omitempty"`
+}
+
+func (c *Condition) isEmpty() bool {
+	return len(c.SetupIssues) == 0 && len(c.Helpfulness) == 0 && len(c.DocsQuality) == 0 && c.MinSetupDifficulty <= 0
+}
+
+func (c *Condition) matches(q *Query) bool {
+	if len(c.SetupIssues) > 0 && !slices.ContainsFunc(q.SetupIssues, func(issue string) bool {
+		return slices.Contains(c.SetupIssues, issue)
+	}) {
+		return false
+	}
+	if len(c.Helpfulness) > 0 && !slices.Contains(c.Helpfulness, q.Helpfulness) {
+		return false
+	}
+	if len(c.DocsQuality) > 0 && !slices.Contains(c.DocsQuality, q.DocsQuality) {
+		return false
+	}
+	if c.MinSetupDifficulty > 0 && q.SetupDifficulty < c.MinSetupDifficulty {
+		return false
+	}
+	return true
+}
+
+// Rule pairs a condition with the suggestion it triggers.
+type Rule struct {
+	When Condition `json:"when"`
+	Suggestion
+}
+
+// Query describes a submission.
+type Query struct {
+	SetupIssues     []string
+	Helpfulness     string
+	DocsQuality     string
+	SetupDifficulty int
+}
+
+// KnowledgeBase holds the rules consulted for each submission.
+type KnowledgeBase struct {
+	rules []*Rule
+	max   int
+}
+
+// New validates rules and returns a knowledge base returning at most max
+// suggestions, or DefaultMaxSuggestions when max is not positive.
+func New(rules []*Rule, max int) (*KnowledgeBase, error) {
+	for i, rule := range rules {
+		if rule.ID == "" || rule.Title == "" {
+			return nil, fmt.Errorf("rule %d needs an id and a title", i)
+		}
+		if rule.When.isEmpty() {
+			return nil, fmt.Errorf("rule %q has no condition", rule.ID)
+		}
+		for _, link := range rule.Links {
+			parsed, err := url.Parse(link.URL)
+			if err != nil || parsed.Scheme != "https" || parsed.Host == "" || link.Title == "" {
+				return nil, fmt.Errorf("rule %q has an invalid link %q", rule.ID, link.URL)
+			}
+		}
+	}
+	if max <= 0 {
+		max = DefaultMaxSuggestions
+	}
+	return &KnowledgeBase{rules: rules, max: max}, nil
+}
+
+// Load reads a JSON knowledge base of the form {"maxSuggestions": 4,
+// "rules": [...]}.
+func Load(path string) (*KnowledgeBase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read knowledge base: %w", err)
+	}
+
+	var file struct {
+		MaxSuggestions int     `json:"maxSuggestions"`
+		Rules          []*Rule `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid knowledge base: %w", err)
+	}
+	return New(file.Rules, file.MaxSuggestions)
+}
+
+// FromEnv loads the knowledge base named by KNOWLEDGE_BASE_FILE, or returns
+// Default when it is unset.
+func FromEnv() (*KnowledgeBase, error) {
+	if path := os.Getenv("KNOWLEDGE_BASE_FILE"); path != "" {
+		return Load(path)
+	}
+	return Default(), nil
+}
+
+// Suggest returns the suggestions of every matching rule, in rule order.
+func (kb *KnowledgeBase) Suggest(q *Query) []Suggestion {
+	var suggestions []Suggestion
+	seen := map[string]bool{}
+	for _, rule := range kb.rules {
+		if len(suggestions) == kb.max {
+			break
+		}
+		if seen[rule.ID] || !rule.When.matches(q) {
+			continue
+		}
+		seen[rule.ID] = true
+		suggestions = append(suggestions, rule.Suggestion)
+	}
+	return suggestions
+}
@@ -0,0 +1,95 @@
+package guidance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func ids(suggestions []Suggestion) []string {
+	var result []string
+	for _, s := range suggestions {
+		result = append(result, s.ID)
+	}
+	return result
+}
+
+func TestDefault_Suggest(t *testing.T) {
+	kb := Default()
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{"no problems", &Query{Helpfulness: "very-helpful", SetupDifficulty: 2}, nil},
+		{"setup issues in rule order", &Query{SetupIssues: []string{"gemini-api-key", "port-conflicts", "other"}}, []string{"port-conflicts", "gemini-api-key"}},
+		{"low helpfulness", &Query{Helpfulness: "not-helpful"}, []string{"get-help"}},
+		{"hard setup and low helpfulness suggest help once", &Query{Helpfulness: "not-helpful", SetupDifficulty: 9}, []string{"get-help"}},
+		{"insufficient docs", &Query{DocsQuality: "no-insufficient"}, []string{"setup-guide"}},
+		{
+			"capped",
+			&Query{SetupIssues: []string{"docker-installation", "gemini-api-key", "chrome-extension", "environment-variables", "port-conflicts"}, Helpfulness: "not-helpful"},
+			[]string{"port-conflicts", "gemini-api-key", "docker-installation", "chrome-extension"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(kb.Suggest(tt.query))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDefault_EveryFormIssueIsCovered(t *testing.T) {
+	for _, issue := range []string{"docker-installation", "gemini-api-key", "chrome-extension", "environment-variables", "port-conflicts"} {
+		suggestions := Default().Suggest(&Query{SetupIssues: []string{issue}})
+		if len(suggestions) != 1 || len(suggestions[0].Links) == 0 {
+			t.Errorf("expected a suggestion with links for %s, got %+v", issue, suggestions)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kb.json")
+	data := `{"maxSuggestions": 1, "rules": [
+		{"id": "ports", "title": "Ports", "when": {"setupIssues": ["port-conflicts"]}, "links": [{"title": "Docs", "url": "https://example.com/ports"}]},
+		{"id": "hard", "title": "Hard", "when": {"minSetupDifficulty": 7}}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KNOWLEDGE_BASE_FILE", path)
+	kb, err := FromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ids(kb.Suggest(&Query{SetupIssues: []string{"port-conflicts"}, SetupDifficulty: 9})); len(got) != 1 || got[0] != "ports" {
+		t.Errorf("expected the first matching rule only, got %v", got)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := map[string]*Rule{
+		"missing title": {Suggestion: Suggestion{ID: "a"}, When: Condition{Helpfulness: []string{"x"}}},
+		"no condition":  {Suggestion: Suggestion{ID: "a", Title: "A"}},
+		"insecure link": {Suggestion: Suggestion{ID: "a", Title: "A", Links: []Link{{Title: "x", URL: "http://example.com"}}}, When: Condition{Helpfulness: []string{"x"}}},
+		"script link":   {Suggestion: Suggestion{ID: "a", Title: "A", Links: []Link{{Title: "x", URL: "javascript:alert(1)"}}}, When: Condition{Helpfulness: []string{"x"}}},
+	}
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New([]*Rule{rule}, 0); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/actions"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/auth"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/mail"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google/sheetstest"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/tokens"
+)
+
+func setupFakeSheets(t *testing.T) *sheetstest.Server {
+	t.Helper()
+
+	srv := sheetstest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.CreateSpreadsheet("spreadsheet-id", "VegaAIFeedback")
+
+	service, err := google.NewGoogleSheetsService(context.Background(), &google.SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		ClientOptions: srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create sheets service: %v", err)
+	}
+
+	actions.SetSheetsService(service)
+	t.Cleanup(func() { actions.SetSheetsService(nil) })
+
+	return srv
+}
+
+func setupAuth(t *testing.T) {
+	t.Helper()
+
+	a, err := auth.NewAuthenticator(&auth.Config{APIKeys: []auth.APIKey{
+		{ID: "reader", Hash: auth.HashAPIKey("read-key"), Scopes: []string{auth.ScopeFeedbackRead}},
+		{ID: "triager", Hash: auth.HashAPIKey("triage-key"), Scopes: []string{auth.ScopeFeedbackRead, auth.ScopeFeedbackTriage}},
+		{ID: "exporter", Hash: auth.HashAPIKey("export-key"), Scopes: []string{auth.ScopeFeedbackExport}},
+		{ID: "revoked", Hash: auth.HashAPIKey("revoked-key"), Scopes: []string{auth.ScopeAll}, Revoked: true},
+	}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	SetAuthenticator(a)
+	t.Cleanup(func() { SetAuthenticator(nil) })
+}
+
+func TestIntegration_FeedbackStoredInSheets(t *testing.T) {
+	srv := setupFakeSheets(t)
+
+	body := `{"helpfulness":"very-helpful","setupDifficulty":4,"docsQuality":"good","email":"user@example.com","consentContact":true}`
+	req := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	Application(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	rows := srv.Values("spreadsheet-id", "VegaAIFeedback")
+	if len(rows) != 2 {
+		t.Fatalf("Expected header and one feedback row, got %d rows", len(rows))
+	}
+
+	row := rows[1]
+	if row[1] != "very-helpful" || row[2] != "4" || row[3] != "good" {
+		t.Errorf("Unexpected feedback row: %v", row)
+	}
+	if row[6] != "user@example.com" || row[7] != "landing-page" {
+		t.Errorf("Expected email and default source to be stored, got %v", row)
+	}
+}
+
+func TestIntegration_FeedbackFormSubmission(t *testing.T) {
+	srv := setupFakeSheets(t)
+
+	form := "helpfulness=helpful&setupDifficulty=7&setupIssues=docker-installation&setupIssues=port-conflicts&source=test"
+	req := httptest.NewRequest("POST", "/?action=feedback", strings.NewReader(form))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	Application(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	rows := srv.Values("spreadsheet-id", "VegaAIFeedback")
+	if len(rows) != 2 || rows[1][4] != "docker-installation, port-conflicts" {
+		t.Errorf("Expected setup issues to be joined, got %v", rows)
+	}
+}
+
+func TestIntegration_SheetsOutageDoesNotFailSubmission(t *testing.T) {
+	srv := setupFakeSheets(t)
+	srv.RateLimit(1)
+
+	req := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"helpfulness":"helpful"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	Application(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 despite storage failure, got %d", w.Code)
+	}
+	if rows := srv.Values("spreadsheet-id", "VegaAIFeedback"); len(rows) != 1 {
+		t.Errorf("Expected only the header row after a rate limited append, got %d rows", len(rows))
+	}
+}
+
+func TestIntegration_ClientTimestampStored(t *testing.T) {
+	srv := setupFakeSheets(t)
+
+	body := `{"helpfulness":"helpful","clientTimestamp":"2025-03-01T12:00:00.123Z"}`
+	req := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	Application(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	row := srv.Values("spreadsheet-id", "VegaAIFeedback")[1]
+	if len(row) < 12 || row[10] != "2025-03-01T12:00:00Z" || row[11] == "" {
+		t.Errorf("Expected client timestamp and clock skew columns, got %v", row)
+	}
+}
+
+func TestIntegration_AdminTriageFlow(t *testing.T) {
+	srv := setupFakeSheets(t)
+	setupAuth(t)
+
+	submit := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"helpfulness":"not-helpful","setupIssues":"port-conflicts"}`))
+	submit.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), submit)
+
+	id := srv.Values("spreadsheet-id", "VegaAIFeedback")[1][8]
+
+	triage := httptest.NewRequest("PATCH", "/admin/feedback/"+id, strings.NewReader(`{"status":"triaged","note":"Port 8765 in use"}`))
+	triage.Header.Set("Authorization", "Bearer triage-key")
+	w := httptest.NewRecorder()
+	Application(w, triage)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for triage, got %d: %s", w.Code, w.Body.String())
+	}
+
+	list := httptest.NewRequest("GET", "/?action=admin/feedback&status=triaged", nil)
+	list.Header.Set("X-API-Key", "triage-key")
+	w = httptest.NewRecorder()
+	Application(w, list)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":1`) || !strings.Contains(w.Body.String(), "Port 8765 in use") {
+		t.Errorf("Expected triaged submission in listing, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIntegration_AdminRequiresAuthentication(t *testing.T) {
+	setupFakeSheets(t)
+	setupAuth(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		credential string
+		want       int
+	}{
+		{"no credentials", "GET", "/admin/feedback", "", http.StatusUnauthorized},
+		{"unknown key", "GET", "/admin/feedback", "nope", http.StatusUnauthorized},
+		{"revoked key", "GET", "/admin/feedback", "revoked-key", http.StatusUnauthorized},
+		{"read key lists", "GET", "/admin/feedback", "read-key", http.StatusOK},
+		{"read key cannot triage", "PATCH", "/admin/feedback/abc", "read-key", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{"status":"triaged"}`))
+			if tt.credential != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.credential)
+			}
+			w := httptest.NewRecorder()
+
+			Application(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestIntegration_ExportCSV(t *testing.T) {
+	setupFakeSheets(t)
+	setupAuth(t)
+
+	submit := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"helpfulness":"not-helpful","additionalFeedback":"=cmd|' /C calc'!A0"}`))
+	submit.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), submit)
+
+	for key, want := range map[string]int{"read-key": http.StatusForbidden, "export-key": http.StatusOK} {
+		req := httptest.NewRequest("GET", "/?action=export&format=csv", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+
+		Application(w, req)
+
+		if w.Code != want {
+			t.Fatalf("Expected status %d for %s, got %d", want, key, w.Code)
+		}
+		if want == http.StatusOK {
+			body := w.Body.String()
+			if !strings.HasPrefix(body, "Timestamp,Helpfulness,") || !strings.Contains(body, `'=cmd|`) {
+				t.Errorf("Unexpected export body: %s", body)
+			}
+		}
+	}
+}
+
+func TestIntegration_PublicStats(t *testing.T) {
+	setupFakeSheets(t)
+	t.Setenv("STATS_MIN_BUCKET_SIZE", "1")
+
+	submit := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"helpfulness":"very-helpful","setupDifficulty":3}`))
+	submit.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), submit)
+
+	w := httptest.NewRecorder()
+	Application(w, httptest.NewRequest("GET", "/?action=stats", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"helpfulPercent":100`) {
+		t.Errorf("Expected stats without authentication, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// capturingMailer records emails sent during integration tests.
+type capturingMailer struct {
+	sent []*mail.Message
+}
+
+func (m *capturingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestIntegration_PrivacyErase(t *testing.T) {
+	srv := setupFakeSheets(t)
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com/")
+
+	m := &capturingMailer{}
+	actions.SetMailer(m)
+	t.Cleanup(func() { actions.SetMailer(nil) })
+	signer, _ := tokens.NewSigner([]byte(strings.Repeat("k", 32)))
+	actions.SetSigner(signer)
+	t.Cleanup(func() { actions.SetSigner(nil) })
+
+	submit := httptest.NewRequest("POST", "/feedback", strings.NewReader(`{"helpfulness":"not-helpful","additionalFeedback":"My name is Jane","email":"jane@example.com","consentContact":true}`))
+	submit.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), submit)
+
+	// The submitter agreed to be contacted and is thanked by email.
+	if len(m.sent) != 1 || m.sent[0].Subject != "Thanks for your feedback on Vega AI" {
+		t.Fatalf("Expected an acknowledgement email, got %d emails", len(m.sent))
+	}
+	m.sent = nil
+
+	request := httptest.NewRequest("POST", "/privacy/erase", strings.NewReader(`{"email":"jane@example.com"}`))
+	request.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), request)
+
+	if len(m.sent) != 1 {
+		t.Fatalf("Expected one verification email, got %d", len(m.sent))
+	}
+	link := m.sent[0].Text[strings.Index(m.sent[0].Text, "https://api.example.com/"):]
+	link = strings.TrimPrefix(strings.Fields(link)[0], "https://api.example.com")
+
+	confirm := httptest.NewRequest("POST", link, nil)
+	w := httptest.NewRecorder()
+	Application(w, confirm)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for erasure, got %d: %s", w.Code, w.Body.String())
+	}
+
+	row := srv.Values("spreadsheet-id", "VegaAIFeedback")[1]
+	if row[5] != google.ErasedMarker || row[6] != "" {
+		t.Errorf("Expected free text and email to be erased, got %v", row)
+	}
+
+	records := srv.Values("spreadsheet-id", "PrivacyRequests")
+	if len(records) != 3 || records[2][3] != google.PrivacyStageCompleted {
+		t.Errorf("Expected requested and completed records, got %v", records)
+	}
+}
+
+func TestIntegration_ConsentColumns(t *testing.T) {
+	srv := setupFakeSheets(t)
+
+	body := `{"helpfulness":"very-helpful","email":"user@example.com","consentContact":true,"privacyPolicyVersion":"2025-06-01"}`
+	req := httptest.NewRequest("POST", "/feedback", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	Application(httptest.NewRecorder(), req)
+
+	row := srv.Values("spreadsheet-id", "VegaAIFeedback")[1]
+	if row[14] != "TRUE" || row[15] != "FALSE" || row[16] != "2025-06-01" || row[17] != row[0] {
+		t.Errorf("Expected consent flags, policy version and timestamp, got %v", row[14:])
+	}
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hashLength is the number of hex characters kept from a client hash,
+// enough to tell clients apart within a day.
+const hashLength = 16
+
+var (
+	now = time.Now
+
+	saltMu  sync.Mutex
+	saltDay string
+	salt    []byte
+)
+
+// DailyHash returns a salted hash of parts that is stable for the current
+// UTC day only. Each day's salt is random, held in memory only and dropped
+// when the day changes, so once the day is over nobody, including the
+// operators, can recompute old hashes or link them to new ones. Instances
+// have their own salts, so hashes also differ between instances.
+func DailyHash(parts ...string) string {
+	mac := hmac.New(sha256.New, dailySalt())
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// ClientHash identifies the client behind a request for logging without
+// recording its IP address.
+func ClientHash(r *http.Request) string {
+	return DailyHash(ClientIP(r))
+}
+
+// ClientIP returns the client address of a request. Behind the Cloud
+// Functions frontend it is the last X-Forwarded-For entry, which the
+// frontend appends; earlier entries come from the caller and can be forged.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		entries := strings.Split(forwarded, ",")
+		if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func dailySalt() []byte {
+	day := now().UTC().Format("2006-01-02")
+
+	saltMu.Lock()
+	defer saltMu.Unlock()
+	if day != saltDay {
+		fresh := make([]byte, 32)
+		if _, err := rand.Read(fresh); err != nil {
+			panic("logging: failed to generate hash salt: " + err.Error())
+		}
+		saltDay, salt = day, fresh
+	}
+	return salt
+}
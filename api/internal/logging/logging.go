@@ -0,0 +1,189 @@
+// Package logging applies the service's logging policy on top of the
+// standard logger: client IPs are replaced with salted hashes that rotate
+// daily, structured fields are limited to an allowlist, messages are
+// scrubbed of emails, secrets and addresses, and INFO logs can be sampled.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/redact"
+)
+
+// Fields are structured values attached to a log line. Only allowlisted
+// keys are written.
+type Fields map[string]any
+
+// allowedFields lists the keys that may appear in logs. Every value is
+// reduced to a short token, so free text never gets through even under an
+// allowed key.
+var allowedFields = map[string]bool{
+	"action":        true,
+	"client":        true,
+	"count":         true,
+	"duration_ms":   true,
+	"format":        true,
+	"method":        true,
+	"principal":     true,
+	"request_id":    true,
+	"rules":         true,
+	"scope":         true,
+	"source":        true,
+	"stage":         true,
+	"status":        true,
+	"submission_id": true,
+	"type":          true,
+}
+
+// maxFieldLength caps field values, which are identifiers rather than text.
+const maxFieldLength = 64
+
+// scrubber is the last line of defence for printf style messages, which may
+// include errors that echo request content.
+var scrubber = redact.New(
+	redact.AdminPasswordDetector,
+	redact.GeminiAPIKeyDetector,
+	redact.EmailDetector,
+	redact.IPAddressDetector,
+)
+
+var (
+	sampleRate float64
+	configOnce sync.Once
+)
+
+// configure reads LOG_INFO_SAMPLE_RATE, the fraction of INFO logs written.
+// It defaults to 1; WARNING and ERROR logs are never sampled.
+func configure() {
+	configOnce.Do(func() {
+		sampleRate = 1
+		if value := os.Getenv("LOG_INFO_SAMPLE_RATE"); value != "" {
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				log.Printf("WARNING: Invalid LOG_INFO_SAMPLE_RATE %q, logging every INFO message", value)
+				return
+			}
+			sampleRate = rate
+		}
+	})
+}
+
+// SetInfoSampleRate overrides LOG_INFO_SAMPLE_RATE. It is intended for
+// tests.
+func SetInfoSampleRate(rate float64) {
+	configOnce.Do(func() {})
+	sampleRate = rate
+}
+
+// Infof logs a scrubbed INFO message, subject to sampling.
+func Infof(format string, args ...any) {
+	if sampled() {
+		write("INFO", fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Warningf logs a scrubbed WARNING message.
+func Warningf(format string, args ...any) {
+	write("WARNING", fmt.Sprintf(format, args...), nil)
+}
+
+// Errorf logs a scrubbed ERROR message.
+func Errorf(format string, args ...any) {
+	write("ERROR", fmt.Sprintf(format, args...), nil)
+}
+
+// Info logs msg with allowlisted fields, subject to sampling.
+func Info(msg string, fields Fields) {
+	if sampled() {
+		write("INFO", msg, fields)
+	}
+}
+
+// Warning logs msg with allowlisted fields.
+func Warning(msg string, fields Fields) {
+	write("WARNING", msg, fields)
+}
+
+// Error logs msg with allowlisted fields.
+func Error(msg string, fields Fields) {
+	write("ERROR", msg, fields)
+}
+
+func sampled() bool {
+	configure()
+	return sampleRate >= 1 || rand.Float64() < sampleRate
+}
+
+func write(level, msg string, fields Fields) {
+	msg, _ = scrubber.Redact(msg)
+
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteString(": ")
+	b.WriteString(msg)
+	b.WriteString(formatFields(fields))
+	log.Print(b.String())
+}
+
+// formatFields renders allowlisted fields as sorted key=value pairs and
+// names any dropped keys, so that a missing field is easy to diagnose.
+func formatFields(fields Fields) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	var dropped []string
+	for _, key := range keys {
+		if !allowedFields[key] {
+			dropped = append(dropped, key)
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", key, fieldValue(fields[key]))
+	}
+	if len(dropped) > 0 {
+		fmt.Fprintf(&b, " dropped_fields=%s", strings.Join(dropped, ","))
+	}
+	return b.String()
+}
+
+// fieldValue reduces a value to a token of safe characters.
+func fieldValue(value any) string {
+	switch v := value.(type) {
+	case int, int64, float64, bool:
+		return fmt.Sprint(v)
+	case fmt.Stringer:
+		return token(v.String())
+	case string:
+		return token(v)
+	default:
+		return "[unsupported]"
+	}
+}
+
+func token(value string) string {
+	if value == "" {
+		return `""`
+	}
+	if len(value) > maxFieldLength {
+		return "[too-long]"
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("._:/=,-", c):
+		default:
+			return "[invalid]"
+		}
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	flags := log.Flags()
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestInfo_Allowlist(t *testing.T) {
+	SetInfoSampleRate(1)
+	logs := captureLogs(t)
+
+	Info("Processing feedback", Fields{
+		"source":   "landing-page",
+		"action":   "feedback form",
+		"email":    "me@example.com",
+		"feedback": "free text",
+		"count":    3,
+	})
+
+	want := "INFO: Processing feedback action=[invalid] count=3 source=landing-page dropped_fields=email,feedback\n"
+	if logs.String() != want {
+		t.Errorf("expected %q, got %q", want, logs.String())
+	}
+}
+
+func TestErrorf_Scrubs(t *testing.T) {
+	logs := captureLogs(t)
+
+	Errorf("failed for %s from 198.51.100.4 with key %s", "me@example.com", "AIza"+strings.Repeat("q", 35))
+
+	want := "ERROR: failed for [redacted:email] from [redacted:ip] with key [redacted:gemini-api-key]\n"
+	if logs.String() != want {
+		t.Errorf("expected %q, got %q", want, logs.String())
+	}
+}
+
+func TestInfo_Sampling(t *testing.T) {
+	logs := captureLogs(t)
+	SetInfoSampleRate(0)
+	t.Cleanup(func() { SetInfoSampleRate(1) })
+
+	Infof("sampled out")
+	Info("sampled out", nil)
+	Warningf("always written")
+	Errorf("always written")
+
+	if strings.Contains(logs.String(), "sampled out") {
+		t.Error("expected INFO logs to be sampled out")
+	}
+	if strings.Count(logs.String(), "always written") != 2 {
+		t.Errorf("expected warnings and errors to be written, got %q", logs.String())
+	}
+}
+
+func TestClientHash(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.9:51234"
+
+	now = func() time.Time { return time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC) }
+	morning := ClientHash(req)
+	now = func() time.Time { return time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC) }
+	evening := ClientHash(req)
+	now = func() time.Time { return time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC) }
+	nextDay := ClientHash(req)
+
+	if morning != evening {
+		t.Error("expected the hash to be stable within a day")
+	}
+	if morning == nextDay {
+		t.Error("expected the hash to rotate daily")
+	}
+	if len(morning) != hashLength || strings.Contains(morning, "203") {
+		t.Errorf("unexpected hash %q", morning)
+	}
+
+	other := httptest.NewRequest("GET", "/", nil)
+	other.RemoteAddr = "203.0.113.10:51234"
+	if ClientHash(other) == nextDay {
+		t.Error("expected different clients to hash differently")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	if ip := ClientIP(req); ip != "10.0.0.1" {
+		t.Errorf("expected the remote address, got %q", ip)
+	}
+
+	// The frontend appends the address it saw; the caller controls the rest.
+	req.Header.Set("X-Forwarded-For", "192.0.2.1, 198.51.100.4")
+	if ip := ClientIP(req); ip != "198.51.100.4" {
+		t.Errorf("expected the last forwarded address, got %q", ip)
+	}
+}
@@ -0,0 +1,246 @@
+// Package mail sends transactional email over SMTP.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds an SMTP session when the context has no deadline.
+const DefaultTimeout = 30 * time.Second
+
+// Message is an email to a single recipient. HTML is optional; when set the
+// message carries both versions and clients pick one.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// ReplyTo is an optional Reply-To address.
+	ReplyTo string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPConfig configures an SMTPMailer.
+type SMTPConfig struct {
+	Host string
+	// Port defaults to 587.
+	Port     string
+	Username string
+	Password string
+	// From is the sender address.
+	From string
+}
+
+// SMTPMailer sends messages through an SMTP relay.
+type SMTPMailer struct {
+	config *SMTPConfig
+}
+
+// NewSMTPMailer validates config and returns a mailer.
+func NewSMTPMailer(config *SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if config.From == "" {
+		return nil, fmt.Errorf("sender address is required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return &SMTPMailer{config: config}, nil
+}
+
+// NewMailerFromEnv builds an SMTPMailer from SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. When MAIL_PREVIEW_DIR is set
+// it returns a PreviewMailer writing to that directory instead, and no SMTP
+// relay is needed.
+func NewMailerFromEnv() (Mailer, error) {
+	if dir := os.Getenv("MAIL_PREVIEW_DIR"); dir != "" {
+		return NewPreviewMailer(dir, os.Getenv("SMTP_FROM"))
+	}
+
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST environment variable is required")
+	}
+
+	return NewSMTPMailer(&SMTPConfig{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+}
+
+// Send implements Mailer. The whole session, from dialling to QUIT, is
+// bounded by the context deadline, or DefaultTimeout without one.
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := buildMessage(m.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	if err := m.send(ctx, msg.To, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// send runs one SMTP session, upgrading to TLS when the server offers it,
+// as smtp.SendMail does.
+func (m *SMTPMailer) send(ctx context.Context, to string, data []byte) error {
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Closing the connection unblocks the session on cancellation.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(m.config.From)); err != nil {
+		return err
+	}
+	if err := client.Rcpt(envelopeAddress(to)); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// envelopeAddress strips the display name from an address such as
+// "Vega AI <noreply@vega.example>".
+func envelopeAddress(address string) string {
+	if start := strings.LastIndex(address, "<"); start >= 0 {
+		return strings.TrimSuffix(address[start+1:], ">")
+	}
+	return address
+}
+
+// buildMessage renders msg as an RFC 5322 message with quoted-printable
+// UTF-8 parts: plain text alone, or multipart/alternative when msg has HTML.
+func buildMessage(from string, msg *Message, now time.Time) ([]byte, error) {
+	for _, value := range []string{from, msg.To, msg.Subject, msg.ReplyTo} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("email headers must not contain line breaks")
+		}
+	}
+	if msg.To == "" {
+		return nil, fmt.Errorf("recipient is required")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	if msg.ReplyTo != "" {
+		fmt.Fprintf(&b, "Reply-To: %s\r\n", msg.ReplyTo)
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&b, msg.Text); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	// Clients show the last part they understand, so HTML goes last.
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode email body: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return nil
+}
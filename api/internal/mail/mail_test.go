@@ -0,0 +1,233 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/mail/smtptest"
+)
+
+func TestBuildMessage(t *testing.T) {
+	data, err := buildMessage("Vega AI <noreply@vega.example>", &Message{
+		To:      "a@example.com",
+		Subject: "Confirm your request ✓",
+		Text:    "Hello,\nopen https://example.com/?action=privacy/export&token=abc\n",
+	}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	if parsed.Header.Get("To") != "a@example.com" {
+		t.Errorf("unexpected To header %q", parsed.Header.Get("To"))
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "Confirm your request ✓" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	body, _ := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if !strings.Contains(string(body), "token=abc") {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestBuildMessage_RejectsHeaderInjection(t *testing.T) {
+	_, err := buildMessage("noreply@vega.example", &Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "x"}, time.Now())
+	if err == nil {
+		t.Error("expected error for header injection")
+	}
+}
+
+func TestNewSMTPMailer_Validation(t *testing.T) {
+	if _, err := NewSMTPMailer(&SMTPConfig{From: "a@example.com"}); err == nil {
+		t.Error("expected error without host")
+	}
+	if _, err := NewSMTPMailer(&SMTPConfig{Host: "localhost"}); err == nil {
+		t.Error("expected error without sender")
+	}
+	m, err := NewSMTPMailer(&SMTPConfig{Host: "localhost", From: "a@example.com"})
+	if err != nil || m.config.Port != "587" {
+		t.Errorf("expected default port, got %v %v", m, err)
+	}
+}
+
+func TestBuildMessage_Alternative(t *testing.T) {
+	data, err := buildMessage("noreply@vega.example", &Message{
+		To:      "a@example.com",
+		Subject: "Thanks",
+		Text:    "Plain body",
+		HTML:    "<p>HTML body</p>",
+		ReplyTo: "me@example.com",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	if parsed.Header.Get("Reply-To") != "me@example.com" {
+		t.Errorf("unexpected Reply-To header %q", parsed.Header.Get("Reply-To"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q", parsed.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+	}
+	want := []string{"text/plain; charset=UTF-8: Plain body", "text/html; charset=UTF-8: <p>HTML body</p>"}
+	if strings.Join(bodies, "|") != strings.Join(want, "|") {
+		t.Errorf("expected parts %q, got %q", want, bodies)
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	sink := smtptest.NewServer()
+	defer sink.Close()
+
+	m, err := NewSMTPMailer(&SMTPConfig{Host: sink.Host(), Port: sink.Port(), From: "Vega AI <noreply@vega.example>"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Send(context.Background(), &Message{To: "a@example.com", Subject: "Hi", Text: "Hello\n.dot line\n"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := sink.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(messages))
+	}
+	if messages[0].From != "noreply@vega.example" || strings.Join(messages[0].To, ",") != "a@example.com" {
+		t.Errorf("unexpected envelope %+v", messages[0])
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if string(body) != "Hello\r\n.dot line\r\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	sink.Reject("gone@example.com")
+	if err := m.Send(context.Background(), &Message{To: "gone@example.com", Subject: "Hi", Text: "x"}); err == nil {
+		t.Error("expected an error for a rejected recipient")
+	}
+}
+
+func TestSMTPMailer_SendHonoursDeadline(t *testing.T) {
+	// A server that accepts connections but never greets.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	m, _ := NewSMTPMailer(&SMTPConfig{Host: host, Port: port, From: "noreply@vega.example"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := m.Send(ctx, &Message{To: "a@example.com", Subject: "Hi", Text: "x"}); err == nil {
+		t.Error("expected an error from a silent server")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the deadline to end the session, took %s", elapsed)
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := MustTemplate("greeting",
+		"Hello {{.Name}}\n",
+		"Hi {{.Name}}, you said: {{.Comment}}\n",
+		"<p>Hi {{.Name}}, you said: {{.Comment}}</p>",
+	)
+
+	msg, err := tmpl.Render("a@example.com", map[string]string{"Name": "Ada", "Comment": "<script>x</script>"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.To != "a@example.com" || msg.Subject != "Hello Ada" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if msg.Text != "Hi Ada, you said: <script>x</script>\n" {
+		t.Errorf("unexpected text %q", msg.Text)
+	}
+	if msg.HTML != "<p>Hi Ada, you said: &lt;script&gt;x&lt;/script&gt;</p>" {
+		t.Errorf("expected escaped HTML, got %q", msg.HTML)
+	}
+
+	if _, err := tmpl.Render("a@example.com", map[string]string{}); err == nil {
+		t.Error("expected an error for missing data")
+	}
+	if _, err := NewTemplate("broken", "{{", "", ""); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestPreviewMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	t.Setenv("MAIL_PREVIEW_DIR", dir)
+	t.Setenv("SMTP_HOST", "")
+
+	m, err := NewMailerFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview, ok := m.(*PreviewMailer)
+	if !ok {
+		t.Fatalf("expected a preview mailer, got %T", m)
+	}
+	preview.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	if err := m.Send(context.Background(), &Message{To: "a@example.com", Subject: "Thanks for your feedback!", Text: "x", HTML: "<p>x</p>"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := "20250102T030405-001-thanks-for-your-feedback.eml,20250102T030405-001-thanks-for-your-feedback.html"
+	if strings.Join(names, ",") != want {
+		t.Errorf("expected %s, got %v", want, names)
+	}
+}
@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PreviewMailer writes messages to a directory instead of sending them, for
+// working on templates locally. Each message is saved as an .eml file that
+// mail clients open, with its HTML part alongside for a browser.
+type PreviewMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewPreviewMailer creates dir if needed and returns a mailer writing to it.
+// from defaults to a placeholder sender.
+func NewPreviewMailer(dir, from string) (*PreviewMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	if from == "" {
+		from = "Vega AI <preview@localhost>"
+	}
+	return &PreviewMailer{dir: dir, from: from, now: time.Now}, nil
+}
+
+// Send implements Mailer.
+func (m *PreviewMailer) Send(ctx context.Context, msg *Message) error {
+	now := m.now()
+	data, err := buildMessage(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.seq++
+	base := filepath.Join(m.dir, fmt.Sprintf("%s-%03d-%s", now.UTC().Format("20060102T150405"), m.seq, slug(msg.Subject)))
+	m.mu.Unlock()
+
+	if err := os.WriteFile(base+".eml", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write email preview: %w", err)
+	}
+	if msg.HTML != "" {
+		if err := os.WriteFile(base+".html", []byte(msg.HTML), 0o644); err != nil {
+			return fmt.Errorf("failed to write email preview: %w", err)
+		}
+	}
+	return nil
+}
+
+// slug turns a subject into a short file name component.
+func slug(subject string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(subject) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+	return "message"
+}
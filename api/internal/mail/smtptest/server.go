@@ -0,0 +1,191 @@
+// Package smtptest provides a local SMTP sink that accepts every message and
+// keeps it in memory, so that code sending email can be exercised end to end
+// without a mail relay.
+package smtptest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Message is an email received by the sink.
+type Message struct {
+	From string
+	To   []string
+	// Data is the message as sent after DATA, with dot stuffing removed and
+	// CRLF line endings.
+	Data string
+}
+
+// Server is an in-memory SMTP server. It speaks enough of RFC 5321 for
+// net/smtp clients: EHLO, MAIL, RCPT, DATA, RSET, NOOP and QUIT. It does not
+// offer STARTTLS or AUTH.
+type Server struct {
+	// Addr is the host:port the server listens on.
+	Addr string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []*Message
+	// rejectRcpt lists recipients refused with a permanent error.
+	rejectRcpt map[string]bool
+}
+
+// NewServer starts a server on a random local port. Call Close when done.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: failed to listen: %v", err))
+	}
+
+	s := &Server{Addr: listener.Addr().String(), listener: listener, rejectRcpt: map[string]bool{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Host returns the host part of Addr.
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr)
+	return host
+}
+
+// Port returns the port part of Addr.
+func (s *Server) Port() string {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	return port
+}
+
+// Reject makes the server refuse the recipient.
+func (s *Server) Reject(recipient string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectRcpt[strings.ToLower(recipient)] = true
+}
+
+// Messages returns the messages received so far.
+func (s *Server) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
+
+// Close stops the server and waits for open sessions to end.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(conn)
+		}()
+	}
+}
+
+func (s *Server) session(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 smtptest ready")
+
+	var current *Message
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			current = nil
+			reply("250 smtptest")
+		case "MAIL":
+			current = &Message{From: address(arg)}
+			reply("250 OK")
+		case "RCPT":
+			if current == nil {
+				reply("503 MAIL first")
+				continue
+			}
+			to := address(arg)
+			s.mu.Lock()
+			rejected := s.rejectRcpt[strings.ToLower(to)]
+			s.mu.Unlock()
+			if rejected {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			current.To = append(current.To, to)
+			reply("250 OK")
+		case "DATA":
+			if current == nil || len(current.To) == 0 {
+				reply("503 RCPT first")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := readData(reader)
+			if err != nil {
+				return
+			}
+			current.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			current = nil
+			reply("250 OK")
+		case "RSET":
+			current = nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// readData reads a DATA section up to the terminating dot line.
+func readData(reader *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			return b.String(), nil
+		}
+		b.WriteString(strings.TrimPrefix(line, "."))
+		b.WriteString("\r\n")
+	}
+}
+
+// address extracts the address from "FROM:<a@example.com>" style arguments.
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,43 @@
+package smtptest
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestServer_ReceivesMessages(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	body := "Subject: hi\r\n\r\n.leading dot\r\nbye\r\n"
+	if err := smtp.SendMail(srv.Addr, nil, "from@example.com", []string{"to@example.com"}, []byte(body)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := srv.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.From != "from@example.com" || strings.Join(msg.To, ",") != "to@example.com" {
+		t.Errorf("unexpected envelope %+v", msg)
+	}
+	if msg.Data != body {
+		t.Errorf("expected data %q, got %q", body, msg.Data)
+	}
+}
+
+func TestServer_Reject(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.Reject("Gone@example.com")
+
+	err := smtp.SendMail(srv.Addr, nil, "from@example.com", []string{"gone@example.com"}, []byte("Subject: x\r\n\r\nx\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("expected a rejected recipient, got %v", err)
+	}
+	if len(srv.Messages()) != 0 {
+		t.Error("expected no message to be stored")
+	}
+}
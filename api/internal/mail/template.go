@@ -0,0 +1,73 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template renders messages from a subject and plain text template, written
+// with text/template, and an optional HTML template, written with
+// html/template so that data is escaped.
+type Template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// NewTemplate parses the templates of one kind of message.
+func NewTemplate(name, subject, text, html string) (*Template, error) {
+	t := &Template{}
+
+	var err error
+	if t.subject, err = texttemplate.New(name + ".subject").Option("missingkey=error").Parse(subject); err != nil {
+		return nil, fmt.Errorf("failed to parse %s subject template: %w", name, err)
+	}
+	if t.text, err = texttemplate.New(name + ".text").Option("missingkey=error").Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse %s text template: %w", name, err)
+	}
+	if html != "" {
+		if t.html, err = htmltemplate.New(name + ".html").Option("missingkey=error").Parse(html); err != nil {
+			return nil, fmt.Errorf("failed to parse %s HTML template: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// MustTemplate is like NewTemplate but panics on error, for templates
+// defined in package variables.
+func MustTemplate(name, subject, text, html string) *Template {
+	t, err := NewTemplate(name, subject, text, html)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render executes the templates with data into a message for to. Line
+// breaks in the rendered subject are folded into spaces.
+func (t *Template) Render(to string, data any) (*Message, error) {
+	var subject, text bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render email text: %w", err)
+	}
+
+	msg := &Message{
+		To:      to,
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    text.String(),
+	}
+	if t.html != nil {
+		var html bytes.Buffer
+		if err := t.html.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("failed to render email HTML: %w", err)
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
@@ -0,0 +1,173 @@
+// Package protect encrypts and pseudonymises sensitive fields before they are
+// written to storage. Values are sealed with envelope encryption: each value
+// gets its own data key, which is wrapped by a key encryption key held by a
+// KeyProvider. Rotating the key encryption key only rewraps data keys.
+package protect
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keyLength is the size of every key, in bytes, for AES-256 and HMAC-SHA256.
+const keyLength = 32
+
+// ErrUnknownKey is returned when a value was sealed with a key that is not
+// in the keyring.
+var ErrUnknownKey = errors.New("unknown key")
+
+// KeyProvider wraps and unwraps data keys, in the style of a cloud KMS.
+// Implementations backed by a hosted KMS keep the key encryption keys out of
+// the process entirely.
+type KeyProvider interface {
+	// PrimaryKeyID names the key new data keys are wrapped with.
+	PrimaryKeyID() string
+	// WrapKey encrypts a data key with the primary key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped with keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Keyring is the JSON layout of a local key file. Keys are base64 encoded
+// 32 byte values. Old keys stay in the file after rotation so that existing
+// values can still be read.
+type Keyring struct {
+	// PrimaryKey names the key encryption key used for new values.
+	PrimaryKey string `json:"primaryKey"`
+	// Keys holds key encryption keys by ID.
+	Keys map[string]string `json:"keys"`
+	// PrimaryHashKey names the key used for new keyed hashes.
+	PrimaryHashKey string `json:"primaryHashKey"`
+	// HashKeys holds keyed hash keys by ID.
+	HashKeys map[string]string `json:"hashKeys"`
+}
+
+// LoadKeyring reads a keyring from a JSON file.
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	var keyring Keyring
+	if err := json.Unmarshal(data, &keyring); err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	return &keyring, nil
+}
+
+// LocalKeyProvider wraps data keys with AES-256-GCM using keys held in
+// memory, typically loaded from a Keyring file.
+type LocalKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyProvider returns a provider for the given keys, wrapping new
+// data keys with primary.
+func NewLocalKeyProvider(primary string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if err := validateKeys(primary, keys); err != nil {
+		return nil, err
+	}
+	return &LocalKeyProvider{primary: primary, keys: keys}, nil
+}
+
+// PrimaryKeyID implements KeyProvider.
+func (p *LocalKeyProvider) PrimaryKeyID() string {
+	return p.primary
+}
+
+// WrapKey implements KeyProvider.
+func (p *LocalKeyProvider) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := sealAESGCM(p.keys[p.primary], dataKey, []byte(p.primary))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return p.primary, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (p *LocalKeyProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	dataKey, err := openAESGCM(key, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// decodeKeys decodes base64 keys from a Keyring.
+func decodeKeys(encoded map[string]string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(encoded))
+	for id, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func validateKeys(primary string, keys map[string][]byte) error {
+	if primary == "" {
+		return fmt.Errorf("a primary key is required")
+	}
+	if _, ok := keys[primary]; !ok {
+		return fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	for id, key := range keys {
+		if id == "" || strings.ContainsAny(id, ":") {
+			return fmt.Errorf("key ID %q must be non-empty and must not contain ':'", id)
+		}
+		if len(key) != keyLength {
+			return fmt.Errorf("key %q must be %d bytes", id, keyLength)
+		}
+	}
+	return nil
+}
+
+func sealAESGCM(key, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openAESGCM(key, sealed, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,222 @@
+package protect
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// sealedPrefix marks values produced by Seal. Values without it are treated
+// as legacy plaintext.
+const sealedPrefix = "enc:v1:"
+
+// Config configures a Protector.
+type Config struct {
+	// Keys wraps the per-value data keys.
+	Keys KeyProvider
+	// HashKeys holds keyed hash keys by ID.
+	HashKeys map[string][]byte
+	// PrimaryHashKey names the hash key used for new hashes.
+	PrimaryHashKey string
+}
+
+// Protector seals and hashes sensitive field values.
+type Protector struct {
+	keys        KeyProvider
+	hashKeys    map[string][]byte
+	primaryHash string
+}
+
+// NewProtector validates config and returns a Protector.
+func NewProtector(config *Config) (*Protector, error) {
+	if config.Keys == nil {
+		return nil, fmt.Errorf("a key provider is required")
+	}
+	if err := validateKeys(config.PrimaryHashKey, config.HashKeys); err != nil {
+		return nil, fmt.Errorf("invalid hash keys: %w", err)
+	}
+	return &Protector{keys: config.Keys, hashKeys: config.HashKeys, primaryHash: config.PrimaryHashKey}, nil
+}
+
+// NewProtectorFromKeyring builds a Protector backed by a LocalKeyProvider.
+func NewProtectorFromKeyring(keyring *Keyring) (*Protector, error) {
+	keys, err := decodeKeys(keyring.Keys)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := NewLocalKeyProvider(keyring.PrimaryKey, keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption keys: %w", err)
+	}
+
+	hashKeys, err := decodeKeys(keyring.HashKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProtector(&Config{Keys: provider, HashKeys: hashKeys, PrimaryHashKey: keyring.PrimaryHashKey})
+}
+
+// NewProtectorFromEnv loads the keyring named by FIELD_KEYRING_FILE. It
+// returns nil without error when the variable is unset, leaving fields
+// unprotected.
+func NewProtectorFromEnv() (*Protector, error) {
+	path := os.Getenv("FIELD_KEYRING_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewProtectorFromKeyring(keyring)
+}
+
+// Seal encrypts value for the named field. The field is bound to the
+// ciphertext, so a value cannot be moved to another column and decrypted.
+// Empty values stay empty.
+func (p *Protector) Seal(ctx context.Context, field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, keyLength)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	ciphertext, err := sealAESGCM(dataKey, []byte(value), []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+
+	keyID, wrapped, err := p.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return sealedPrefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open decrypts a value produced by Seal for the same field. Values that
+// were never sealed are returned unchanged.
+func (p *Protector) Open(ctx context.Context, field, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+
+	keyID, wrapped, ciphertext, err := parseSealed(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := p.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAESGCM(dataKey, ciphertext, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap moves a sealed value to the primary key without decrypting the
+// value itself. Values that are already current, or not sealed, are
+// returned unchanged.
+func (p *Protector) Rewrap(ctx context.Context, value string) (string, bool, error) {
+	if !IsSealed(value) {
+		return value, false, nil
+	}
+
+	keyID, wrapped, ciphertext, err := parseSealed(value)
+	if err != nil {
+		return "", false, err
+	}
+	if keyID == p.keys.PrimaryKeyID() {
+		return value, false, nil
+	}
+
+	dataKey, err := p.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", false, err
+	}
+	newKeyID, rewrapped, err := p.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	return sealedPrefix + newKeyID + ":" +
+		base64.RawStdEncoding.EncodeToString(rewrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), true, nil
+}
+
+// Hash returns a keyed hash of value under the primary hash key, prefixed
+// with the key ID. Equal values hash equally, which allows lookups and
+// deduplication without decrypting anything.
+func (p *Protector) Hash(value string) string {
+	return p.hashWith(p.primaryHash, value)
+}
+
+// Hashes returns the hash of value under every hash key, for lookups that
+// must also match values hashed before a rotation.
+func (p *Protector) Hashes(value string) []string {
+	ids := make([]string, 0, len(p.hashKeys))
+	for id := range p.hashKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	hashes := make([]string, 0, len(ids))
+	for _, id := range ids {
+		hashes = append(hashes, p.hashWith(id, value))
+	}
+	return hashes
+}
+
+// HashIsCurrent reports whether hash was made with the primary hash key.
+func (p *Protector) HashIsCurrent(hash string) bool {
+	return strings.HasPrefix(hash, p.primaryHash+":")
+}
+
+func (p *Protector) hashWith(keyID, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.hashKeys[keyID])
+	mac.Write([]byte(value))
+	return keyID + ":" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsSealed reports whether value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+func parseSealed(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, fmt.Errorf("malformed sealed value")
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed sealed value")
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed sealed value")
+	}
+	return parts[0], wrapped, ciphertext, nil
+}
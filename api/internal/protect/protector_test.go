@@ -0,0 +1,196 @@
+package protect
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, keyLength))
+}
+
+func testProtector(t *testing.T, keyring *Keyring) *Protector {
+	t.Helper()
+	p, err := NewProtectorFromKeyring(keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestProtector_SealOpen(t *testing.T) {
+	p := testProtector(t, &Keyring{
+		PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+	})
+	ctx := context.Background()
+
+	sealed, err := p.Seal(ctx, "email", "me@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "example") {
+		t.Fatalf("expected an opaque sealed value, got %q", sealed)
+	}
+
+	again, _ := p.Seal(ctx, "email", "me@example.com")
+	if again == sealed {
+		t.Error("expected each seal to use a fresh data key")
+	}
+
+	opened, err := p.Open(ctx, "email", sealed)
+	if err != nil || opened != "me@example.com" {
+		t.Fatalf("expected the email back, got %q, %v", opened, err)
+	}
+
+	if _, err := p.Open(ctx, "notes", sealed); err == nil {
+		t.Error("expected a value sealed for another field to fail")
+	}
+	if plain, err := p.Open(ctx, "email", "legacy@example.com"); err != nil || plain != "legacy@example.com" {
+		t.Errorf("expected plain text to pass through, got %q, %v", plain, err)
+	}
+	if empty, _ := p.Seal(ctx, "email", ""); empty != "" {
+		t.Errorf("expected empty values to stay empty, got %q", empty)
+	}
+}
+
+func TestProtector_Rotation(t *testing.T) {
+	ctx := context.Background()
+	old := testProtector(t, &Keyring{
+		PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+	})
+	sealed, _ := old.Seal(ctx, "email", "me@example.com")
+	oldHash := old.Hash("me@example.com")
+
+	rotated := testProtector(t, &Keyring{
+		PrimaryKey: "k2", Keys: map[string]string{"k1": testKey(1), "k2": testKey(3)},
+		PrimaryHashKey: "h2", HashKeys: map[string]string{"h1": testKey(2), "h2": testKey(4)},
+	})
+
+	if opened, err := rotated.Open(ctx, "email", sealed); err != nil || opened != "me@example.com" {
+		t.Fatalf("expected old values to stay readable, got %q, %v", opened, err)
+	}
+
+	rewrapped, changed, err := rotated.Rewrap(ctx, sealed)
+	if err != nil || !changed {
+		t.Fatalf("expected the value to be rewrapped, got %v, %v", changed, err)
+	}
+	if !strings.HasPrefix(rewrapped, sealedPrefix+"k2:") {
+		t.Errorf("expected the primary key ID, got %q", rewrapped)
+	}
+	if _, changed, _ := rotated.Rewrap(ctx, rewrapped); changed {
+		t.Error("expected a current value not to be rewrapped again")
+	}
+
+	current := testProtector(t, &Keyring{
+		PrimaryKey: "k2", Keys: map[string]string{"k2": testKey(3)},
+		PrimaryHashKey: "h2", HashKeys: map[string]string{"h2": testKey(4)},
+	})
+	if opened, err := current.Open(ctx, "email", rewrapped); err != nil || opened != "me@example.com" {
+		t.Errorf("expected the rewrapped value to open without the old key, got %q, %v", opened, err)
+	}
+	if _, err := current.Open(ctx, "email", sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey for a retired key, got %v", err)
+	}
+
+	if rotated.HashIsCurrent(oldHash) || !rotated.HashIsCurrent(rotated.Hash("me@example.com")) {
+		t.Error("expected only hashes under the primary hash key to be current")
+	}
+	found := false
+	for _, hash := range rotated.Hashes("me@example.com") {
+		found = found || hash == oldHash
+	}
+	if !found {
+		t.Error("expected lookups to include hashes under retired keys")
+	}
+}
+
+func TestProtector_Hash(t *testing.T) {
+	p := testProtector(t, &Keyring{
+		PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+	})
+	other := testProtector(t, &Keyring{
+		PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(5)},
+	})
+
+	if p.Hash("a@example.com") != p.Hash("a@example.com") {
+		t.Error("expected hashes to be stable")
+	}
+	if p.Hash("a@example.com") == p.Hash("b@example.com") {
+		t.Error("expected different values to hash differently")
+	}
+	if p.Hash("a@example.com") == other.Hash("a@example.com") {
+		t.Error("expected hashes to depend on the key")
+	}
+	if p.Hash("") != "" {
+		t.Error("expected empty values not to be hashed")
+	}
+}
+
+func TestNewProtectorFromEnv(t *testing.T) {
+	t.Setenv("FIELD_KEYRING_FILE", "")
+	if p, err := NewProtectorFromEnv(); p != nil || err != nil {
+		t.Fatalf("expected no protector without a keyring, got %v, %v", p, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	data, _ := json.Marshal(&Keyring{
+		PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+	})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FIELD_KEYRING_FILE", path)
+
+	p, err := NewProtectorFromEnv()
+	if err != nil || p == nil {
+		t.Fatalf("expected a protector, got %v, %v", p, err)
+	}
+}
+
+func TestNewProtectorFromKeyring_Invalid(t *testing.T) {
+	tests := map[string]*Keyring{
+		"missing primary": {
+			Keys:           map[string]string{"k1": testKey(1)},
+			PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+		},
+		"primary not in keyring": {
+			PrimaryKey: "k2", Keys: map[string]string{"k1": testKey(1)},
+			PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+		},
+		"short key": {
+			PrimaryKey: "k1", Keys: map[string]string{"k1": base64.StdEncoding.EncodeToString([]byte("short"))},
+			PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+		},
+		"bad base64": {
+			PrimaryKey: "k1", Keys: map[string]string{"k1": "not base64!"},
+			PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+		},
+		"colon in key ID": {
+			PrimaryKey: "k:1", Keys: map[string]string{"k:1": testKey(1)},
+			PrimaryHashKey: "h1", HashKeys: map[string]string{"h1": testKey(2)},
+		},
+		"missing hash keys": {
+			PrimaryKey: "k1", Keys: map[string]string{"k1": testKey(1)},
+		},
+	}
+
+	for name, keyring := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewProtectorFromKeyring(keyring); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
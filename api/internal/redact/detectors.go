@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// AdminPasswordDetector masks the rest of the line after ADMIN_PASSWORD
+// assignments pasted from the Vega config file, keeping the key so the
+// report still makes sense.
+var AdminPasswordDetector = Pattern(
+	"admin-password",
+	regexp.MustCompile(`(?i)(\bADMIN_PASSWORD[ \t]*[=:][ \t]*)\S[^\r\n]*`),
+	"${1}"+Mask("admin-password"),
+)
+
+// GeminiAPIKeyDetector masks Google API keys, which Gemini keys are.
+var GeminiAPIKeyDetector = Pattern(
+	"gemini-api-key",
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	"",
+)
+
+// EmailDetector masks email addresses.
+var EmailDetector = Pattern(
+	"email",
+	regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	"",
+)
+
+// PhoneDetector masks phone numbers. Candidates need 9 to 15 digits and
+// either a leading + or separators between digit groups, so that ports,
+// dates and plain numeric IDs in pasted logs are left alone.
+var PhoneDetector = Checked(
+	"phone",
+	regexp.MustCompile(`\+?\(?\d[\d \t().\-]{6,}\d`),
+	isPhoneNumber,
+)
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+func isPhoneNumber(candidate string) bool {
+	digits := 0
+	for _, c := range candidate {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	if digits < 9 || digits > 15 {
+		return false
+	}
+	if datePattern.MatchString(candidate) {
+		return false
+	}
+	// Dotted numbers are versions or addresses far more often than phones.
+	if strings.Count(candidate, ".") > 1 {
+		return false
+	}
+	return strings.HasPrefix(candidate, "+") || strings.ContainsAny(candidate, " -()")
+}
+
+// IPAddressDetector masks IPv4 and IPv6 addresses. It is meant for logs
+// rather than feedback, where addresses in pasted output are harmless.
+var IPAddressDetector = Checked(
+	"ip",
+	regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}`),
+	func(candidate string) bool {
+		return net.ParseIP(candidate) != nil
+	},
+)
@@ -0,0 +1,155 @@
+// Package redact masks secrets and personal data in free text before it is
+// stored or logged. Each kind of data is found by a Detector; a Redactor runs
+// its detectors in order and reports which of them fired.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Detector finds one kind of sensitive data in text and masks it.
+type Detector interface {
+	// Name identifies the detector in Findings.
+	Name() string
+	// Redact returns text with every match masked, and the number of
+	// matches.
+	Redact(text string) (string, int)
+}
+
+// Mask returns the placeholder that replaces data found by the named
+// detector.
+func Mask(name string) string {
+	return "[redacted:" + name + "]"
+}
+
+// Findings counts matches per detector name.
+type Findings map[string]int
+
+// Add merges other into f.
+func (f Findings) Add(other Findings) {
+	for name, count := range other {
+		f[name] += count
+	}
+}
+
+// Rules returns the names of the detectors that fired, sorted.
+func (f Findings) Rules() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String lists the findings as "name=count" pairs sorted by name, in the
+// form stored alongside redacted feedback.
+func (f Findings) String() string {
+	parts := make([]string, 0, len(f))
+	for _, name := range f.Rules() {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, f[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Redactor runs detectors over text in registration order.
+type Redactor struct {
+	detectors []Detector
+}
+
+// New returns a Redactor running the given detectors.
+func New(detectors ...Detector) *Redactor {
+	return &Redactor{detectors: detectors}
+}
+
+// Default returns a Redactor with the built in detectors. Secret detectors
+// run first so that, for example, a password that looks like an email is
+// reported as a password.
+func Default() *Redactor {
+	return New(
+		AdminPasswordDetector,
+		GeminiAPIKeyDetector,
+		EmailDetector,
+		PhoneDetector,
+	)
+}
+
+// Register appends a detector.
+func (r *Redactor) Register(d Detector) {
+	r.detectors = append(r.detectors, d)
+}
+
+// Redact masks everything the detectors find and reports which fired.
+func (r *Redactor) Redact(text string) (string, Findings) {
+	findings := Findings{}
+	if text == "" {
+		return text, findings
+	}
+
+	for _, d := range r.detectors {
+		var count int
+		text, count = d.Redact(text)
+		if count > 0 {
+			findings[d.Name()] += count
+		}
+	}
+	return text, findings
+}
+
+// patternDetector masks regular expression matches.
+type patternDetector struct {
+	name        string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Pattern returns a Detector masking matches of pattern. The replacement
+// may refer to submatches as in regexp.Regexp.ReplaceAllString; an empty
+// replacement masks the whole match.
+func Pattern(name string, pattern *regexp.Regexp, replacement string) Detector {
+	if replacement == "" {
+		replacement = Mask(name)
+	}
+	return &patternDetector{name: name, pattern: pattern, replacement: replacement}
+}
+
+func (p *patternDetector) Name() string { return p.name }
+
+func (p *patternDetector) Redact(text string) (string, int) {
+	count := len(p.pattern.FindAllStringIndex(text, -1))
+	if count == 0 {
+		return text, 0
+	}
+	return p.pattern.ReplaceAllString(text, p.replacement), count
+}
+
+// funcDetector masks matches of a pattern that are confirmed by a check,
+// for data that a regular expression alone cannot pin down.
+type funcDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	check   func(match string) bool
+}
+
+// Checked returns a Detector masking matches of pattern for which check
+// returns true.
+func Checked(name string, pattern *regexp.Regexp, check func(match string) bool) Detector {
+	return &funcDetector{name: name, pattern: pattern, check: check}
+}
+
+func (f *funcDetector) Name() string { return f.name }
+
+func (f *funcDetector) Redact(text string) (string, int) {
+	count := 0
+	text = f.pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if !f.check(match) {
+			return match
+		}
+		count++
+		return Mask(f.name)
+	})
+	return text, count
+}
@@ -0,0 +1,99 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Default(t *testing.T) {
+	key := "AIza" + strings.Repeat("x", 35)
+
+	tests := []struct {
+		name     string
+		text     string
+		want     string
+		findings string
+	}{
+		{
+			"gemini key",
+			"docker logs: invalid key " + key + " rejected",
+			"docker logs: invalid key [redacted:gemini-api-key] rejected",
+			"gemini-api-key=1",
+		},
+		{
+			"admin password line",
+			"GEMINI_API_KEY=abc\nADMIN_PASSWORD=hunter2 # changed\n export ADMIN_PASSWORD: \"x y\"\nPORT=8765",
+			"GEMINI_API_KEY=abc\nADMIN_PASSWORD=[redacted:admin-password]\n export ADMIN_PASSWORD: [redacted:admin-password]\nPORT=8765",
+			"admin-password=2",
+		},
+		{
+			"password that looks like an email",
+			"ADMIN_PASSWORD=me@example.com",
+			"ADMIN_PASSWORD=[redacted:admin-password]",
+			"admin-password=1",
+		},
+		{
+			"emails",
+			"contact me at Jane.Doe+vega@example.co.uk or j@x.io",
+			"contact me at [redacted:email] or [redacted:email]",
+			"email=2",
+		},
+		{
+			"phone numbers",
+			"call +44 20 7946 0958 or (555) 123-4567",
+			"call [redacted:phone] or [redacted:phone]",
+			"phone=2",
+		},
+		{
+			"log noise is kept",
+			"2025-01-01 10:00:00 listening on 0.0.0.0:8765, build 1234567890, version 1.20.3",
+			"2025-01-01 10:00:00 listening on 0.0.0.0:8765, build 1234567890, version 1.20.3",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, findings := Default().Redact(tt.text)
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if findings.String() != tt.findings {
+				t.Errorf("expected findings %q, got %q", tt.findings, findings.String())
+			}
+		})
+	}
+}
+
+func TestRedactor_Register(t *testing.T) {
+	r := Default()
+	r.Register(Pattern("github-token", regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36}`), ""))
+
+	token := "ghp_" + strings.Repeat("a", 36)
+	got, findings := r.Redact("token " + token + " and me@example.com")
+
+	if got != "token [redacted:github-token] and [redacted:email]" {
+		t.Errorf("unexpected redaction %q", got)
+	}
+	if findings.String() != "email=1, github-token=1" {
+		t.Errorf("unexpected findings %q", findings.String())
+	}
+}
+
+func TestFindings_Add(t *testing.T) {
+	findings := Findings{"email": 1}
+	findings.Add(Findings{"email": 2, "phone": 1})
+
+	if findings.String() != "email=3, phone=1" {
+		t.Errorf("unexpected findings %q", findings.String())
+	}
+}
+
+func TestIPAddressDetector(t *testing.T) {
+	got, count := IPAddressDetector.Redact("from 203.0.113.7 and 2001:db8::1 at 10:00:00, build 1.2.3.4567")
+
+	if got != "from [redacted:ip] and [redacted:ip] at 10:00:00, build 1.2.3.4567" || count != 2 {
+		t.Errorf("unexpected redaction %q (%d)", got, count)
+	}
+}
@@ -2,11 +2,19 @@ package google
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/protect"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
@@ -14,8 +22,133 @@ import (
 // SheetsService defines the interface for Google Sheets operations
 type SheetsService interface {
 	AppendFeedback(ctx context.Context, feedback *FeedbackData) error
+	ListFeedback(ctx context.Context, query *FeedbackQuery) (*FeedbackPage, error)
+	GetFeedback(ctx context.Context, id string) (*FeedbackData, error)
+	FeedbackAnswers(ctx context.Context, to time.Time) ([]FeedbackAnswers, error)
+	UpdateTriage(ctx context.Context, id string, update *TriageUpdate) (*FeedbackData, error)
+	FindFeedbackByEmail(ctx context.Context, email string) ([]*FeedbackData, error)
+	EraseFeedbackByEmail(ctx context.Context, email string) (int, error)
+	RecordPrivacyRequest(ctx context.Context, record *PrivacyRecord) error
+	RotateFieldKeys(ctx context.Context) (*KeyRotationResult, error)
+	ApplyRetention(ctx context.Context, policy *RetentionPolicy, dryRun bool) (*RetentionReport, error)
+	RecordDelivery(ctx context.Context, record *DeliveryRecord) error
+	FindSubscriber(ctx context.Context, email string) (*Subscriber, error)
+	SaveSubscriber(ctx context.Context, subscriber *Subscriber) error
+	EraseSubscriber(ctx context.Context, email string) (bool, error)
+	RecordDownload(ctx context.Context, event *DownloadEvent) error
+	DownloadCounts(ctx context.Context, from, to time.Time) ([]DownloadCount, error)
+	CompactCounts(ctx context.Context) (*CompactionResult, error)
+	AddEventCounts(ctx context.Context, counts []EventCount) error
+	EventCounts(ctx context.Context, from, to time.Time) ([]EventCount, error)
+	Location() *time.Location
 }
 
+// feedbackHeaders lists the feedback sheet columns in order. New columns are
+// only ever appended so that existing sheets keep their layout.
+var feedbackHeaders = []any{
+	"Timestamp",
+	"Helpfulness",
+	"Setup Difficulty",
+	"Docs Quality",
+	"Setup Issues",
+	"Additional Feedback",
+	"Email",
+	"Source",
+	"Submission ID",
+	"Overflow Ref",
+	"Client Timestamp",
+	"Clock Skew Seconds",
+	"Triage Status",
+	"Internal Notes",
+	"Contact Consent",
+	"Quote Consent",
+	"Privacy Policy Version",
+	"Consent Timestamp",
+	"Email Hash",
+	"Redactions",
+}
+
+// Column indexes of the feedback sheet, matching feedbackHeaders.
+const (
+	colTimestamp = iota
+	colHelpfulness
+	colSetupDifficulty
+	colDocsQuality
+	colSetupIssues
+	colAdditionalFeedback
+	colEmail
+	colSource
+	colSubmissionID
+	colOverflowRef
+	colClientTimestamp
+	colClockSkew
+	colTriageStatus
+	colInternalNotes
+	colContactConsent
+	colQuoteConsent
+	colPrivacyPolicyVersion
+	colConsentTimestamp
+	colEmailHash
+	colRedactions
+)
+
+// FeedbackHeaders returns the feedback sheet column headers in order.
+func FeedbackHeaders() []string {
+	headers := make([]string, len(feedbackHeaders))
+	for i, header := range feedbackHeaders {
+		headers[i] = header.(string)
+	}
+	return headers
+}
+
+// Record returns the submission as text cells in FeedbackHeaders order, as
+// it reads back from the sheet.
+func (f *FeedbackData) Record() []string {
+	record := make([]string, len(feedbackHeaders))
+	if !f.SubmittedAt.IsZero() {
+		record[colTimestamp] = f.SubmittedAt.Format(time.RFC3339)
+	}
+	record[colHelpfulness] = f.Helpfulness
+	record[colSetupDifficulty] = strconv.Itoa(f.SetupDifficulty)
+	record[colDocsQuality] = f.DocsQuality
+	record[colSetupIssues] = f.SetupIssues
+	record[colAdditionalFeedback] = f.AdditionalFeedback
+	record[colEmail] = f.Email
+	record[colSource] = f.Source
+	record[colSubmissionID] = f.ID
+	record[colOverflowRef] = f.OverflowRef
+	if !f.ClientSubmittedAt.IsZero() {
+		record[colClientTimestamp] = f.ClientSubmittedAt.Format(time.RFC3339)
+		record[colClockSkew] = strconv.FormatInt(int64(f.ClockSkew.Round(time.Second)/time.Second), 10)
+	}
+	record[colTriageStatus] = f.Status
+	record[colInternalNotes] = f.Notes
+	record[colContactConsent] = sheetBool(f.ContactConsent)
+	record[colQuoteConsent] = sheetBool(f.QuoteConsent)
+	record[colPrivacyPolicyVersion] = f.PrivacyPolicyVersion
+	if !f.ConsentedAt.IsZero() {
+		record[colConsentTimestamp] = f.ConsentedAt.Format(time.RFC3339)
+	}
+	record[colEmailHash] = f.EmailHash
+	record[colRedactions] = f.Redactions
+	return record
+}
+
+// DefaultClockSkewThreshold is how far a client reported timestamp may drift
+// from the server clock before it is reported as skewed.
+const DefaultClockSkewThreshold = 5 * time.Minute
+
+// Clock provides the current time, so that tests can control timestamps.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time { return time.Now() }
+
 // FeedbackData represents feedback data for storage in Google Sheets
 type FeedbackData struct {
 	Helpfulness        string
@@ -25,19 +158,126 @@ type FeedbackData struct {
 	AdditionalFeedback string
 	Email              string
 	Source             string
+
+	// ID uniquely identifies a submission. AppendFeedback generates one when
+	// it is empty.
+	ID string
+	// SubmittedAt is when the server received the feedback. It is set by
+	// AppendFeedback and populated when reading feedback back.
+	SubmittedAt time.Time
+	// ClientSubmittedAt is the submit time reported by the client, if any.
+	ClientSubmittedAt time.Time
+	// ClockSkew is ClientSubmittedAt minus SubmittedAt. It is zero when the
+	// client did not report a time.
+	ClockSkew time.Duration
+	// OverflowRef points at the full text of fields that exceeded
+	// MaxCellLength and were truncated in the sheet.
+	OverflowRef string
+	// Status is the maintainer triage status, TriageStatusNew by default.
+	Status string
+	// Notes holds internal maintainer notes, one per line.
+	Notes string
+
+	// ContactConsent records that the submitter agreed to be contacted at
+	// Email.
+	ContactConsent bool
+	// QuoteConsent records that the submitter agreed to be quoted publicly.
+	QuoteConsent bool
+	// PrivacyPolicyVersion is the privacy policy version shown with the form.
+	PrivacyPolicyVersion string
+	// ConsentedAt is when consent was given. AppendFeedback sets it to the
+	// receive time when either consent flag is set.
+	ConsentedAt time.Time
+
+	// EmailHash is a keyed hash of the normalized email, set when field
+	// protection is configured. It identifies a submitter without
+	// decrypting Email.
+	EmailHash string
+
+	// Redactions lists the redaction rules that fired on the free text
+	// fields, as "rule=count" pairs.
+	Redactions string
+
+	// row is the 1-based sheet row the submission was read from.
+	row int
+}
+
+// CanContact reports whether the submitter may be contacted by email. Only
+// submissions with explicit contact consent qualify.
+func (f *FeedbackData) CanContact() bool {
+	return f.ContactConsent && strings.TrimSpace(f.Email) != ""
 }
 
 // GoogleSheetsService handles Google Sheets operations
 type GoogleSheetsService struct {
-	service       *sheets.Service
-	spreadsheetID string
-	sheetName     string
+	service          *sheets.Service
+	spreadsheetID    string
+	sheetName        string
+	overflow         OverflowStore
+	privacySheet     string
+	retentionSheet   string
+	webhookSheet     string
+	subscribersSheet string
+	downloadsSheet   string
+	eventsSheet      string
+	protector        *protect.Protector
+	clock            Clock
+	location         *time.Location
+	skewThreshold    time.Duration
+
+	// tabs remembers the tabs ensureTab has found or added, so that writes
+	// do not look the spreadsheet up every time.
+	tabsMu sync.Mutex
+	tabs   map[string]bool
 }
 
 // SheetsConfig holds configuration for Google Sheets service
 type SheetsConfig struct {
 	SpreadsheetID string
 	SheetName     string
+	// ApplyFormatting styles the header row and adds column widths, data
+	// validation and conditional formatting to the feedback sheet.
+	ApplyFormatting bool
+	// EnableSummary maintains a tab of formula driven aggregates.
+	EnableSummary bool
+	// SummarySheetName names the summary tab. Defaults to "Summary".
+	SummarySheetName string
+	// OverflowSheetName names the tab holding the full text of oversized
+	// fields. Defaults to "Overflow"; the tab is created on first use.
+	OverflowSheetName string
+	// OverflowStore replaces the overflow tab with another store.
+	OverflowStore OverflowStore
+	// PrivacySheetName names the tab recording data subject requests.
+	// Defaults to "PrivacyRequests"; the tab is created on first use.
+	PrivacySheetName string
+	// RetentionSheetName names the tab summarising retention runs.
+	// Defaults to "Retention"; the tab is created on first use.
+	RetentionSheetName string
+	// WebhookSheetName names the tab logging webhook deliveries. Defaults
+	// to "WebhookDeliveries"; the tab is created on first use.
+	WebhookSheetName string
+	// SubscribersSheetName names the tab holding newsletter subscribers.
+	// Defaults to "Subscribers"; the tab is created on first use.
+	SubscribersSheetName string
+	// DownloadsSheetName names the tab recording downloads. Defaults to
+	// "Downloads"; the tab is created on first use.
+	DownloadsSheetName string
+	// EventsSheetName names the tab holding daily page event counts.
+	// Defaults to "Events"; the tab is created on first use.
+	EventsSheetName string
+	// Protector encrypts emails before they are written and adds a keyed
+	// hash for lookups. Emails are stored in plain text when it is nil.
+	Protector *protect.Protector
+	// Clock supplies server timestamps. Defaults to SystemClock.
+	Clock Clock
+	// Location is the time zone timestamps are written in. Defaults to UTC.
+	Location *time.Location
+	// ClockSkewThreshold is the drift between client and server time that
+	// is logged as clock skew. Defaults to DefaultClockSkewThreshold.
+	ClockSkewThreshold time.Duration
+	// ClientOptions are passed to the underlying Sheets client, for example
+	// to point it at a local emulator.
+	ClientOptions []option.ClientOption
 }
 
 // NewGoogleSheetsServiceFromEnv creates a new Google Sheets service from environment variables
@@ -50,8 +290,46 @@ func NewGoogleSheetsServiceFromEnv(ctx context.Context) (SheetsService, error) {
 	}
 
 	config := &SheetsConfig{
-		SpreadsheetID: spreadsheetID,
-		SheetName:     sheetName,
+		SpreadsheetID:     spreadsheetID,
+		SheetName:         sheetName,
+		ApplyFormatting:   envBool("GOOGLE_SHEETS_FORMATTING"),
+		EnableSummary:     envBool("GOOGLE_SHEETS_SUMMARY"),
+		SummarySheetName:  os.Getenv("GOOGLE_SUMMARY_SHEET_NAME"),
+		OverflowSheetName: os.Getenv("GOOGLE_OVERFLOW_SHEET_NAME"),
+		PrivacySheetName:  os.Getenv("GOOGLE_PRIVACY_SHEET_NAME"),
+
+		RetentionSheetName:   os.Getenv("GOOGLE_RETENTION_SHEET_NAME"),
+		WebhookSheetName:     os.Getenv("GOOGLE_WEBHOOK_SHEET_NAME"),
+		SubscribersSheetName: os.Getenv("GOOGLE_SUBSCRIBERS_SHEET_NAME"),
+		DownloadsSheetName:   os.Getenv("GOOGLE_DOWNLOADS_SHEET_NAME"),
+		EventsSheetName:      os.Getenv("GOOGLE_EVENTS_SHEET_NAME"),
+	}
+
+	protector, err := protect.NewProtectorFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load field protection keys: %w", err)
+	}
+	config.Protector = protector
+
+	if tz := os.Getenv("GOOGLE_SHEETS_TIMEZONE"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOOGLE_SHEETS_TIMEZONE %q: %w", tz, err)
+		}
+		config.Location = location
+	}
+
+	// GOOGLE_SHEETS_EMULATOR points the service at a local emulator such as
+	// the sheetstest fake server, given by GOOGLE_SHEETS_ENDPOINT, without
+	// credentials. It is meant for local development only.
+	if envBool("GOOGLE_SHEETS_EMULATOR") {
+		options, err := emulatorOptions(os.Getenv("GOOGLE_SHEETS_ENDPOINT"))
+		if err != nil {
+			return nil, err
+		}
+		config.ClientOptions = options
+	} else if os.Getenv("GOOGLE_SHEETS_ENDPOINT") != "" {
+		return nil, fmt.Errorf("GOOGLE_SHEETS_ENDPOINT requires GOOGLE_SHEETS_EMULATOR=true")
 	}
 
 	return NewGoogleSheetsService(ctx, config)
@@ -65,86 +343,217 @@ func NewGoogleSheetsService(ctx context.Context, config *SheetsConfig) (*GoogleS
 	if config.SheetName == "" {
 		config.SheetName = "VegaAIFeedback" // default sheet name
 	}
+	if config.SummarySheetName == "" {
+		config.SummarySheetName = "Summary"
+	}
+	if config.OverflowSheetName == "" {
+		config.OverflowSheetName = "Overflow"
+	}
+	if config.PrivacySheetName == "" {
+		config.PrivacySheetName = "PrivacyRequests"
+	}
+	if config.RetentionSheetName == "" {
+		config.RetentionSheetName = "Retention"
+	}
+	if config.WebhookSheetName == "" {
+		config.WebhookSheetName = "WebhookDeliveries"
+	}
+	if config.SubscribersSheetName == "" {
+		config.SubscribersSheetName = "Subscribers"
+	}
+	if config.DownloadsSheetName == "" {
+		config.DownloadsSheetName = "Downloads"
+	}
+	if config.EventsSheetName == "" {
+		config.EventsSheetName = "Events"
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock{}
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.ClockSkewThreshold <= 0 {
+		config.ClockSkewThreshold = DefaultClockSkewThreshold
+	}
 
-	log.Printf("INFO: Creating Google Sheets service with default credentials")
+	logging.Infof("Creating Google Sheets service with default credentials")
 
-	service, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope))
+	opts := append([]option.ClientOption{option.WithScopes(sheets.SpreadsheetsScope)}, config.ClientOptions...)
+	service, err := sheets.NewService(ctx, opts...)
 	if err != nil {
-		log.Printf("ERROR: Failed to create sheets service - Type: %T, Error: %v", err, err)
+		logging.Errorf("Failed to create sheets service - Type: %T, Error: %v", err, err)
 		return nil, fmt.Errorf("failed to create sheets service: %w", err)
 	}
 
 	sheetsService := &GoogleSheetsService{
-		service:       service,
-		spreadsheetID: config.SpreadsheetID,
-		sheetName:     config.SheetName,
+		service:          service,
+		spreadsheetID:    config.SpreadsheetID,
+		sheetName:        config.SheetName,
+		overflow:         config.OverflowStore,
+		privacySheet:     config.PrivacySheetName,
+		retentionSheet:   config.RetentionSheetName,
+		webhookSheet:     config.WebhookSheetName,
+		subscribersSheet: config.SubscribersSheetName,
+		downloadsSheet:   config.DownloadsSheetName,
+		eventsSheet:      config.EventsSheetName,
+		protector:        config.Protector,
+		clock:            config.Clock,
+		location:         config.Location,
+		skewThreshold:    config.ClockSkewThreshold,
+	}
+	if sheetsService.overflow == nil {
+		sheetsService.overflow = &sheetsOverflowStore{
+			service:       service,
+			spreadsheetID: config.SpreadsheetID,
+			sheetName:     config.OverflowSheetName,
+			owner:         sheetsService,
+		}
 	}
 
 	if err := sheetsService.ensureHeaders(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize sheet headers: %w", err)
 	}
 
-	log.Printf("INFO: Google Sheets service initialized successfully for spreadsheet: %s", config.SpreadsheetID)
+	// Formatting and the summary tab are conveniences for people reading the
+	// sheet, so failures are logged rather than blocking feedback storage.
+	if config.ApplyFormatting {
+		if err := sheetsService.ensureFormatting(ctx); err != nil {
+			logging.Warningf("Failed to apply sheet formatting: %v", err)
+		}
+	}
+	if config.EnableSummary {
+		if err := sheetsService.ensureSummary(ctx, config.SummarySheetName); err != nil {
+			logging.Warningf("Failed to set up summary sheet: %v", err)
+		}
+	}
+
+	logging.Infof("Google Sheets service initialized successfully for spreadsheet: %s", config.SpreadsheetID)
 	return sheetsService, nil
 }
 
+// Location returns the time zone the service writes timestamps and download
+// days in.
+func (g *GoogleSheetsService) Location() *time.Location {
+	return g.location
+}
+
 // AppendFeedback appends feedback data to the Google Sheet
 func (g *GoogleSheetsService) AppendFeedback(ctx context.Context, feedback *FeedbackData) error {
 	if feedback == nil {
 		return fmt.Errorf("feedback data cannot be nil")
 	}
 
+	if feedback.ID == "" {
+		id, err := newSubmissionID()
+		if err != nil {
+			return fmt.Errorf("failed to generate submission ID: %w", err)
+		}
+		feedback.ID = id
+	}
+	feedback.SubmittedAt = g.clock.Now().In(g.location)
+
+	clientTimestamp := ""
+	clockSkew := ""
+	if !feedback.ClientSubmittedAt.IsZero() {
+		feedback.ClockSkew = feedback.ClientSubmittedAt.Sub(feedback.SubmittedAt)
+		clientTimestamp = feedback.ClientSubmittedAt.In(g.location).Format(time.RFC3339)
+		clockSkew = strconv.FormatInt(int64(feedback.ClockSkew.Round(time.Second)/time.Second), 10)
+
+		if feedback.ClockSkew > g.skewThreshold || feedback.ClockSkew < -g.skewThreshold {
+			logging.Warningf("Clock skew of %s detected for submission %s", feedback.ClockSkew.Round(time.Second), feedback.ID)
+		}
+	}
+
+	consentTimestamp := ""
+	if feedback.ContactConsent || feedback.QuoteConsent {
+		if feedback.ConsentedAt.IsZero() {
+			feedback.ConsentedAt = feedback.SubmittedAt
+		}
+		consentTimestamp = feedback.ConsentedAt.In(g.location).Format(time.RFC3339)
+	}
+
+	// Free text can legitimately be long, so its full content is kept in
+	// the overflow store. Other fields are short by design and are simply
+	// clamped to the cell limit.
+	setupIssues, setupIssuesRef := g.limitFreeText(ctx, feedback.ID, "setupIssues", feedback.SetupIssues)
+	additional, additionalRef := g.limitFreeText(ctx, feedback.ID, "additionalFeedback", feedback.AdditionalFeedback)
+
+	var refs []string
+	for _, ref := range []string{setupIssuesRef, additionalRef} {
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	feedback.OverflowRef = strings.Join(refs, ", ")
+
+	clamp := func(value string) string {
+		clamped, _ := limitCell(value, " … [truncated]")
+		return clamped
+	}
+
+	email, emailHash, err := g.protectEmail(ctx, clamp(feedback.Email))
+	if err != nil {
+		return err
+	}
+	feedback.EmailHash = emailHash
+
 	values := []any{
-		time.Now().Format(time.RFC3339),
-		feedback.Helpfulness,
+		feedback.SubmittedAt.Format(time.RFC3339),
+		clamp(feedback.Helpfulness),
 		feedback.SetupDifficulty,
-		feedback.DocsQuality,
-		feedback.SetupIssues,
-		feedback.AdditionalFeedback,
-		feedback.Email,
-		feedback.Source,
+		clamp(feedback.DocsQuality),
+		setupIssues,
+		additional,
+		email,
+		clamp(feedback.Source),
+		feedback.ID,
+		feedback.OverflowRef,
+		clientTimestamp,
+		clockSkew,
+		"",
+		"",
+		feedback.ContactConsent,
+		feedback.QuoteConsent,
+		clamp(feedback.PrivacyPolicyVersion),
+		consentTimestamp,
+		emailHash,
+		clamp(feedback.Redactions),
 	}
 
 	valueRange := &sheets.ValueRange{
 		Values: [][]any{values},
 	}
 
-	range_ := fmt.Sprintf("%s!A:H", g.sheetName)
+	range_ := fmt.Sprintf("%s!A:%s", g.sheetName, columnLetter(len(feedbackHeaders)-1))
 	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, valueRange)
 	appendCall.ValueInputOption("RAW")
 	appendCall.InsertDataOption("INSERT_ROWS")
 
-	_, err := appendCall.Context(ctx).Do()
+	_, err = appendCall.Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to append feedback to sheet: %w", err)
 	}
 
-	log.Printf("INFO: Successfully appended feedback to Google Sheets from source: %s", feedback.Source)
+	logging.Info("Successfully appended feedback to Google Sheets", logging.Fields{
+		"source":        feedback.Source,
+		"submission_id": feedback.ID,
+	})
 	return nil
 }
 
 // ensureHeaders ensures the sheet has proper headers
 func (g *GoogleSheetsService) ensureHeaders(ctx context.Context) error {
-	range_ := fmt.Sprintf("%s!A1:H1", g.sheetName)
+	range_ := fmt.Sprintf("%s!A1:%s1", g.sheetName, columnLetter(len(feedbackHeaders)-1))
 	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to check existing headers: %w", err)
 	}
 
-	if len(response.Values) == 0 || len(response.Values[0]) == 0 {
-		headers := []any{
-			"Timestamp",
-			"Helpfulness",
-			"Setup Difficulty",
-			"Docs Quality",
-			"Setup Issues",
-			"Additional Feedback",
-			"Email",
-			"Source",
-		}
-
+	// Sheets created before a column was added get the missing headers too.
+	if len(response.Values) == 0 || len(response.Values[0]) < len(feedbackHeaders) {
 		valueRange := &sheets.ValueRange{
-			Values: [][]any{headers},
+			Values: [][]any{feedbackHeaders},
 		}
 
 		updateCall := g.service.Spreadsheets.Values.Update(g.spreadsheetID, range_, valueRange)
@@ -155,8 +564,118 @@ func (g *GoogleSheetsService) ensureHeaders(ctx context.Context) error {
 			return fmt.Errorf("failed to add headers: %w", err)
 		}
 
-		log.Printf("INFO: Added headers to Google Sheet: %s", g.sheetName)
+		logging.Infof("Added headers to Google Sheet: %s", g.sheetName)
+	}
+
+	return nil
+}
+
+// newSubmissionID returns a random identifier for a feedback submission.
+func newSubmissionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// columnLetter converts a zero based column index into its A1 column name.
+func columnLetter(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// sheetBool formats a boolean the way Sheets displays it.
+func sheetBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// emulatorOptions returns client options for an unauthenticated emulator at
+// endpoint, which must be on a loopback address so that requests can never
+// reach a remote server without credentials.
+func emulatorOptions(endpoint string) ([]option.ClientOption, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid GOOGLE_SHEETS_ENDPOINT %q", endpoint)
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("GOOGLE_SHEETS_ENDPOINT must be a loopback address, got %q", host)
+	}
+	return []option.ClientOption{
+		option.WithEndpoint(endpoint),
+		option.WithoutAuthentication(),
+	}, nil
+}
+
+// envBool reports whether an environment variable is set to a true value.
+func envBool(key string) bool {
+	enabled, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && enabled
+}
+
+// ensureTab adds a tab with a header row unless it already exists. Once a
+// tab has been found or added it is not looked up again, until forgetTab.
+func (g *GoogleSheetsService) ensureTab(ctx context.Context, title string, headers []any) error {
+	g.tabsMu.Lock()
+	ready := g.tabs[title]
+	g.tabsMu.Unlock()
+	if ready {
+		return nil
 	}
 
+	sheet, err := g.sheet(ctx, title)
+	if err != nil {
+		return err
+	}
+	if sheet != nil {
+		g.rememberTab(title, true)
+		return nil
+	}
+
+	_, err = g.service.Spreadsheets.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{Title: title},
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to add sheet %s: %w", title, err)
+	}
+
+	range_ := fmt.Sprintf("%s!A1", quoteSheetName(title))
+	updateCall := g.service.Spreadsheets.Values.Update(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{headers},
+	})
+	updateCall.ValueInputOption("RAW")
+	if _, err := updateCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to add headers to sheet %s: %w", title, err)
+	}
+
+	logging.Infof("Added sheet: %s", title)
+	g.rememberTab(title, true)
 	return nil
 }
+
+// forgetTab makes the next ensureTab look the tab up again, after a write to
+// it failed, in case it was deleted.
+func (g *GoogleSheetsService) forgetTab(title string) {
+	g.rememberTab(title, false)
+}
+
+func (g *GoogleSheetsService) rememberTab(title string, ready bool) {
+	g.tabsMu.Lock()
+	defer g.tabsMu.Unlock()
+	if g.tabs == nil {
+		g.tabs = map[string]bool{}
+	}
+	g.tabs[title] = ready
+}
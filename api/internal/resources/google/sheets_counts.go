@@ -0,0 +1,165 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// countRow is a row read from a tab of append-only counts, such as the
+// downloads tab.
+type countRow struct {
+	values []any
+	// row is the 1-based sheet row.
+	row int
+}
+
+func (r countRow) cell(col int) string {
+	if col >= len(r.values) || r.values[col] == nil {
+		return ""
+	}
+	return fmt.Sprint(r.values[col])
+}
+
+func (r countRow) number(col int) int {
+	n, _ := strconv.ParseFloat(r.cell(col), 64)
+	return int(n)
+}
+
+// readCountRows loads the rows of a counts tab with the given number of
+// columns. A missing tab means nothing has been counted yet.
+func (g *GoogleSheetsService) readCountRows(ctx context.Context, title string, columns int) ([]countRow, error) {
+	sheet, err := g.sheet(ctx, title)
+	if err != nil || sheet == nil {
+		return nil, err
+	}
+
+	range_ := fmt.Sprintf("%s!A2:%s", quoteSheetName(title), columnLetter(columns-1))
+	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from sheet: %w", title, err)
+	}
+
+	rows := make([]countRow, 0, len(response.Values))
+	for i, values := range response.Values {
+		if len(values) == 0 {
+			continue
+		}
+		rows = append(rows, countRow{values: values, row: i + 2})
+	}
+	return rows, nil
+}
+
+// CompactionResult reports how many delta rows CompactCounts merged away.
+type CompactionResult struct {
+	DownloadRows int `json:"downloadRows"`
+	EventRows    int `json:"eventRows"`
+}
+
+// CompactCounts merges the delta rows that downloads and page events append
+// into one row per day and key, for days before today. It keeps the counts
+// tabs from growing with every request. It is meant to run daily from a
+// single scheduler, because two runs at once could merge the same rows
+// twice. Rows appended while it runs are left for the next run.
+func (g *GoogleSheetsService) CompactCounts(ctx context.Context) (*CompactionResult, error) {
+	now := g.clock.Now()
+	result := &CompactionResult{}
+
+	var err error
+	result.DownloadRows, err = g.compactTab(ctx, g.downloadsSheet, len(downloadHeaders), dlColCount,
+		now.In(g.location).Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact download counts: %w", err)
+	}
+	// Event days are UTC days.
+	result.EventRows, err = g.compactTab(ctx, g.eventsSheet, len(eventHeaders), evColCount,
+		now.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact event counts: %w", err)
+	}
+	return result, nil
+}
+
+// compactTab merges the rows of a counts tab whose key, the columns before
+// keyCols, is the same and whose day, in the first column, is before today.
+// The remaining columns are added up. The first row of each group is
+// overwritten with the totals and the others deleted, in one batch update
+// that the Sheets API applies atomically. It returns the number of rows
+// deleted.
+func (g *GoogleSheetsService) compactTab(ctx context.Context, title string, columns, keyCols int, today string) (int, error) {
+	rows, err := g.readCountRows(ctx, title, columns)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	var order []string
+	groups := map[string][]countRow{}
+	for _, row := range rows {
+		if day := row.cell(0); day == "" || day >= today {
+			continue
+		}
+		key := make([]string, keyCols)
+		for col := range key {
+			key[col] = row.cell(col)
+		}
+		k := strings.Join(key, "\x00")
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], row)
+	}
+
+	sheet, err := g.sheet(ctx, title)
+	if err != nil {
+		return 0, err
+	}
+
+	var requests []*sheets.Request
+	var deleted []int
+	for _, k := range order {
+		group := groups[k]
+		if len(group) < 2 {
+			continue
+		}
+
+		cells := make([]*sheets.CellData, 0, columns-keyCols)
+		for col := keyCols; col < columns; col++ {
+			total := 0
+			for _, row := range group {
+				total += row.number(col)
+			}
+			value := float64(total)
+			cells = append(cells, &sheets.CellData{UserEnteredValue: &sheets.ExtendedValue{NumberValue: &value}})
+		}
+		requests = append(requests, &sheets.Request{
+			UpdateCells: &sheets.UpdateCellsRequest{
+				Start: &sheets.GridCoordinate{
+					SheetId:     sheet.Properties.SheetId,
+					RowIndex:    int64(group[0].row - 1),
+					ColumnIndex: int64(keyCols),
+				},
+				Rows:   []*sheets.RowData{{Values: cells}},
+				Fields: "userEnteredValue",
+			},
+		})
+		for _, row := range group[1:] {
+			deleted = append(deleted, row.row)
+		}
+	}
+	if len(deleted) == 0 {
+		return 0, nil
+	}
+
+	// Updates come first, while the rows are still where they were read.
+	requests = append(requests, deleteRowRequests(sheet.Properties.SheetId, deleted)...)
+	_, err = g.service.Spreadsheets.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Context(ctx).Do()
+	if err != nil {
+		return 0, err
+	}
+	return len(deleted), nil
+}
@@ -0,0 +1,121 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+var downloadHeaders = []any{"Day", "Artifact", "Version", "Referrer", "Country", "Count"}
+
+// Column indexes of the downloads tab, matching downloadHeaders. Every
+// column before dlColCount is part of the key counts are merged on.
+const (
+	dlColDay = iota
+	dlColArtifact
+	dlColVersion
+	dlColReferrer
+	dlColCountry
+	dlColCount
+)
+
+// DownloadEvent is one download through the landing page. It is anonymous:
+// the referrer is reduced to a host and the location to a country.
+type DownloadEvent struct {
+	Artifact string
+	Version  string
+	Referrer string
+	Country  string
+	At       time.Time
+}
+
+// DownloadCount is the number of downloads of an artifact on one day, in
+// the service's time zone.
+type DownloadCount struct {
+	Day      string `json:"day"`
+	Artifact string `json:"artifact"`
+	Count    int    `json:"count"`
+}
+
+// RecordDownload adds a download to the downloads tab as a delta row of one
+// for its day, artifact, version, referrer and country. Rows are only ever
+// appended, so concurrent downloads never overwrite each other's counts;
+// CompactCounts later merges the deltas of past days into one row per key.
+func (g *GoogleSheetsService) RecordDownload(ctx context.Context, event *DownloadEvent) error {
+	if event == nil || event.Artifact == "" {
+		return fmt.Errorf("download event must name an artifact")
+	}
+
+	if err := g.ensureTab(ctx, g.downloadsSheet, downloadHeaders); err != nil {
+		return err
+	}
+
+	at := event.At
+	if at.IsZero() {
+		at = g.clock.Now()
+	}
+	values := []any{
+		at.In(g.location).Format("2006-01-02"),
+		event.Artifact,
+		event.Version,
+		event.Referrer,
+		event.Country,
+		1,
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.downloadsSheet), columnLetter(len(downloadHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{values},
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		g.forgetTab(g.downloadsSheet)
+		return fmt.Errorf("failed to record download: %w", err)
+	}
+	return nil
+}
+
+// DownloadCounts adds up downloads per artifact and day, in the service's
+// time zone, for days between from and to, inclusive. Zero times leave that
+// end open. Counts are sorted by day, then artifact.
+func (g *GoogleSheetsService) DownloadCounts(ctx context.Context, from, to time.Time) ([]DownloadCount, error) {
+	rows, err := g.readCountRows(ctx, g.downloadsSheet, len(downloadHeaders))
+	if err != nil {
+		return nil, err
+	}
+
+	var first, last string
+	if !from.IsZero() {
+		first = from.In(g.location).Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		last = to.In(g.location).Format("2006-01-02")
+	}
+
+	type key struct{ day, artifact string }
+	totals := map[key]int{}
+	for _, row := range rows {
+		day := row.cell(dlColDay)
+		if row.cell(dlColArtifact) == "" || (first != "" && day < first) || (last != "" && day > last) {
+			continue
+		}
+		totals[key{day, row.cell(dlColArtifact)}] += row.number(dlColCount)
+	}
+
+	counts := make([]DownloadCount, 0, len(totals))
+	for k, count := range totals {
+		counts = append(counts, DownloadCount{Day: k.day, Artifact: k.artifact, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Day != counts[j].Day {
+			return counts[i].Day < counts[j].Day
+		}
+		return counts[i].Artifact < counts[j].Artifact
+	})
+	return counts, nil
+}
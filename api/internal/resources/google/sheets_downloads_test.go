@@ -0,0 +1,81 @@
+package google
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_Downloads(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	if counts, err := service.DownloadCounts(ctx, time.Time{}, time.Time{}); err != nil || len(counts) != 0 {
+		t.Fatalf("expected no counts before the tab exists, got %v, %v", counts, err)
+	}
+
+	day := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := []*DownloadEvent{
+		{Artifact: "extension", Version: "v1.0.0", Referrer: "vega.benidevo.com", Country: "NG", At: day},
+		{Artifact: "extension", Version: "v1.0.0", Referrer: "vega.benidevo.com", Country: "NG", At: day.Add(30 * time.Minute)},
+		{Artifact: "extension", Version: "v1.0.0", Referrer: "direct", Country: "unknown", At: day.Add(time.Hour)},
+		{Artifact: "vega", At: day.Add(2 * time.Hour)},
+		{Artifact: "extension", At: day.AddDate(0, 0, 1)},
+		{Artifact: "extension", At: day.AddDate(0, 0, -10)},
+	}
+	for _, event := range events {
+		if err := service.RecordDownload(ctx, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := service.RecordDownload(ctx, &DownloadEvent{}); err == nil {
+		t.Error("expected an error without an artifact")
+	}
+
+	rows := srv.Values("spreadsheet-id", "Downloads")
+	if len(rows) != len(events)+1 || rows[1][dlColDay] != "2025-03-01" || rows[1][dlColReferrer] != "vega.benidevo.com" ||
+		rows[1][dlColCountry] != "NG" || rows[1][dlColCount] != "1" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	want := []DownloadCount{
+		{Day: "2025-03-01", Artifact: "extension", Count: 3},
+		{Day: "2025-03-01", Artifact: "vega", Count: 1},
+		{Day: "2025-03-02", Artifact: "extension", Count: 1},
+	}
+	assertCounts := func() {
+		t.Helper()
+		counts, err := service.DownloadCounts(ctx, day.AddDate(0, 0, -1), time.Time{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(counts) != len(want) {
+			t.Fatalf("expected %v, got %v", want, counts)
+		}
+		for i := range want {
+			if counts[i] != want[i] {
+				t.Errorf("count %d: expected %+v, got %+v", i, want[i], counts[i])
+			}
+		}
+	}
+	assertCounts()
+
+	// Compaction merges the deltas of past days only and keeps the totals.
+	service.clock = fixedClock{now: day.AddDate(0, 0, 1)}
+	result, err := service.CompactCounts(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DownloadRows != 1 {
+		t.Errorf("expected one row to be merged away, got %+v", result)
+	}
+	rows = srv.Values("spreadsheet-id", "Downloads")
+	if len(rows) != len(events) || rows[1][dlColCount] != "2" {
+		t.Errorf("expected the repeated download to be merged, got %v", rows)
+	}
+	assertCounts()
+
+	if again, err := service.CompactCounts(ctx); err != nil || again.DownloadRows != 0 {
+		t.Errorf("expected nothing left to compact, got %+v, %v", again, err)
+	}
+}
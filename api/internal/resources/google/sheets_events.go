@@ -0,0 +1,114 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+var eventHeaders = []any{"Day", "Event", "Count", "Visitors"}
+
+// Column indexes of the events tab, matching eventHeaders.
+const (
+	evColDay = iota
+	evColEvent
+	evColCount
+	evColVisitors
+)
+
+// EventCount aggregates one landing page event over a UTC day: how often it
+// happened and how many distinct visitors caused it. Visitors are told apart
+// by a hash that rotates daily, so they cannot be added up across days.
+// Each instance only dedupes the visitors it has seen itself, so Visitors is
+// an approximate figure that can count a visitor more than once.
+type EventCount struct {
+	Day      string `json:"day"`
+	Event    string `json:"event"`
+	Count    int    `json:"count"`
+	Visitors int    `json:"visitors"`
+}
+
+// AddEventCounts appends counts to the events tab as delta rows. Rows are
+// never updated in place, so concurrent writers cannot overwrite each
+// other's counts; EventCounts adds up the rows of a day and event, and
+// CompactCounts later merges the rows of past days. The tab never holds
+// individual visitors.
+func (g *GoogleSheetsService) AddEventCounts(ctx context.Context, counts []EventCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	values := make([][]any, 0, len(counts))
+	for _, count := range counts {
+		if count.Day == "" || count.Event == "" {
+			return fmt.Errorf("event counts must name a day and an event")
+		}
+		values = append(values, []any{count.Day, count.Event, count.Count, count.Visitors})
+	}
+
+	if err := g.ensureTab(ctx, g.eventsSheet, eventHeaders); err != nil {
+		return err
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.eventsSheet), columnLetter(len(eventHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: values,
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		g.forgetTab(g.eventsSheet)
+		return fmt.Errorf("failed to append event counts: %w", err)
+	}
+	return nil
+}
+
+// EventCounts returns the event counts for UTC days between from and to,
+// inclusive. Zero times leave that end open. The delta rows of each day and
+// event are added together. Counts are sorted by day, then event.
+func (g *GoogleSheetsService) EventCounts(ctx context.Context, from, to time.Time) ([]EventCount, error) {
+	rows, err := g.readCountRows(ctx, g.eventsSheet, len(eventHeaders))
+	if err != nil {
+		return nil, err
+	}
+
+	var first, last string
+	if !from.IsZero() {
+		first = from.UTC().Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		last = to.UTC().Format("2006-01-02")
+	}
+
+	totals := map[[2]string]*EventCount{}
+	for _, row := range rows {
+		day, event := row.cell(evColDay), row.cell(evColEvent)
+		if event == "" || (first != "" && day < first) || (last != "" && day > last) {
+			continue
+		}
+		key := [2]string{day, event}
+		total, ok := totals[key]
+		if !ok {
+			total = &EventCount{Day: day, Event: event}
+			totals[key] = total
+		}
+		total.Count += row.number(evColCount)
+		total.Visitors += row.number(evColVisitors)
+	}
+
+	counts := make([]EventCount, 0, len(totals))
+	for _, count := range totals {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Day != counts[j].Day {
+			return counts[i].Day < counts[j].Day
+		}
+		return counts[i].Event < counts[j].Event
+	})
+	return counts, nil
+}
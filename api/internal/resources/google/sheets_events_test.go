@@ -0,0 +1,96 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_Events(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	if counts, err := service.EventCounts(ctx, time.Time{}, time.Time{}); err != nil || len(counts) != 0 {
+		t.Fatalf("expected no counts before the tab exists, got %v, %v", counts, err)
+	}
+
+	batches := [][]EventCount{
+		{
+			{Day: "2025-03-01", Event: "pageview", Count: 5, Visitors: 3},
+			{Day: "2025-03-01", Event: "open-faq", Count: 1, Visitors: 1},
+		},
+		{
+			{Day: "2025-03-01", Event: "pageview", Count: 2, Visitors: 1},
+			{Day: "2025-03-02", Event: "pageview", Count: 4, Visitors: 4},
+		},
+		{
+			{Day: "2025-02-20", Event: "pageview", Count: 9, Visitors: 9},
+		},
+	}
+	before := len(srv.Requests())
+	for _, batch := range batches {
+		if err := service.AddEventCounts(ctx, batch); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lookups := 0
+	for _, req := range srv.Requests()[before:] {
+		if req.Method == http.MethodGet {
+			lookups++
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("expected the tab to be looked up once, got %d lookups", lookups)
+	}
+	if err := service.AddEventCounts(ctx, []EventCount{{Day: "2025-03-01", Count: 1}}); err == nil {
+		t.Error("expected an error without an event")
+	}
+
+	rows := srv.Values("spreadsheet-id", "Events")
+	if len(rows) != 6 {
+		t.Fatalf("expected a header and one row per count added, got %v", rows)
+	}
+
+	counts, err := service.EventCounts(ctx, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []EventCount{
+		{Day: "2025-03-01", Event: "open-faq", Count: 1, Visitors: 1},
+		{Day: "2025-03-01", Event: "pageview", Count: 7, Visitors: 4},
+		{Day: "2025-03-02", Event: "pageview", Count: 4, Visitors: 4},
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("count %d: expected %+v, got %+v", i, want[i], counts[i])
+		}
+	}
+
+	service.clock = fixedClock{now: time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC)}
+	result, err := service.CompactCounts(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.EventRows != 1 {
+		t.Errorf("expected the two pageview rows of 2025-03-01 to be merged, got %+v", result)
+	}
+	if rows := srv.Values("spreadsheet-id", "Events"); len(rows) != 5 {
+		t.Errorf("expected the rows of today to be left alone, got %v", rows)
+	}
+	compacted, err := service.EventCounts(ctx, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(compacted) != len(want) {
+		t.Fatalf("expected %v after compaction, got %v", want, compacted)
+	}
+	for i := range want {
+		if compacted[i] != want[i] {
+			t.Errorf("count %d after compaction: expected %+v, got %+v", i, want[i], compacted[i])
+		}
+	}
+}
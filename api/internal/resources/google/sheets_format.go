@@ -0,0 +1,288 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"google.golang.org/api/sheets/v4"
+)
+
+// HelpfulnessOptions are the helpfulness answers offered by the feedback form.
+var HelpfulnessOptions = []string{"very-helpful", "somewhat-helpful", "not-helpful", "havent-tried"}
+
+// DocsQualityOptions are the documentation quality answers offered by the
+// feedback form.
+var DocsQualityOptions = []string{"yes-clear", "mostly-sufficient", "no-insufficient"}
+
+// SetupIssueOptions are the setup issues offered by the feedback form.
+var SetupIssueOptions = []string{"docker-installation", "gemini-api-key", "chrome-extension", "environment-variables", "port-conflicts", "other"}
+
+// highSetupDifficulty is the lowest setup difficulty highlighted in the sheet.
+const highSetupDifficulty = 8
+
+// feedbackColumnWidths sets column widths in pixels, keyed by header.
+var feedbackColumnWidths = map[string]int64{
+	"Timestamp":              180,
+	"Helpfulness":            140,
+	"Setup Difficulty":       120,
+	"Docs Quality":           150,
+	"Setup Issues":           260,
+	"Additional Feedback":    400,
+	"Email":                  220,
+	"Source":                 120,
+	"Submission ID":          160,
+	"Overflow Ref":           220,
+	"Client Timestamp":       180,
+	"Clock Skew Seconds":     140,
+	"Triage Status":          120,
+	"Internal Notes":         400,
+	"Contact Consent":        130,
+	"Quote Consent":          130,
+	"Privacy Policy Version": 170,
+	"Consent Timestamp":      180,
+	"Email Hash":             220,
+	"Redactions":             200,
+}
+
+// ensureFormatting styles the feedback sheet. Every request is idempotent
+// except conditional formatting, which is only added when missing.
+func (g *GoogleSheetsService) ensureFormatting(ctx context.Context) error {
+	sheet, err := g.sheet(ctx, g.sheetName)
+	if err != nil {
+		return err
+	}
+	if sheet == nil {
+		return fmt.Errorf("sheet %q not found", g.sheetName)
+	}
+	sheetID := sheet.Properties.SheetId
+
+	requests := []*sheets.Request{
+		{
+			UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+				Properties: &sheets.SheetProperties{
+					SheetId:        sheetID,
+					GridProperties: &sheets.GridProperties{FrozenRowCount: 1},
+				},
+				Fields: "gridProperties.frozenRowCount",
+			},
+		},
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{SheetId: sheetID, StartRowIndex: 0, EndRowIndex: 1},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						TextFormat: &sheets.TextFormat{Bold: true},
+					},
+				},
+				Fields: "userEnteredFormat.textFormat.bold",
+			},
+		},
+		dataValidationRequest(sheetID, colHelpfulness, HelpfulnessOptions),
+		dataValidationRequest(sheetID, colDocsQuality, DocsQualityOptions),
+		dataValidationRequest(sheetID, colTriageStatus, TriageStatuses),
+	}
+
+	for i, header := range feedbackHeaders {
+		width, ok := feedbackColumnWidths[header.(string)]
+		if !ok {
+			continue
+		}
+		requests = append(requests, &sheets.Request{
+			UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetID,
+					Dimension:  "COLUMNS",
+					StartIndex: int64(i),
+					EndIndex:   int64(i + 1),
+				},
+				Properties: &sheets.DimensionProperties{PixelSize: width},
+				Fields:     "pixelSize",
+			},
+		})
+	}
+
+	if !hasDifficultyRule(sheet) {
+		requests = append(requests, &sheets.Request{
+			AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+				Index: 0,
+				Rule:  difficultyRule(sheetID),
+			},
+		})
+	}
+
+	_, err = g.service.Spreadsheets.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to format sheet: %w", err)
+	}
+
+	logging.Infof("Applied formatting to Google Sheet: %s", g.sheetName)
+	return nil
+}
+
+// ensureSummary creates the summary tab when missing and rewrites its
+// formulas so that they track the current column layout.
+func (g *GoogleSheetsService) ensureSummary(ctx context.Context, summarySheetName string) error {
+	sheet, err := g.sheet(ctx, summarySheetName)
+	if err != nil {
+		return err
+	}
+
+	if sheet == nil {
+		_, err := g.service.Spreadsheets.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{Title: summarySheetName},
+				},
+			}},
+		}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to add summary sheet: %w", err)
+		}
+		logging.Infof("Added summary sheet: %s", summarySheetName)
+	}
+
+	range_ := fmt.Sprintf("%s!A1", quoteSheetName(summarySheetName))
+	updateCall := g.service.Spreadsheets.Values.Update(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: summaryValues(g.sheetName),
+	})
+	updateCall.ValueInputOption("USER_ENTERED")
+
+	if _, err := updateCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to write summary formulas: %w", err)
+	}
+
+	return nil
+}
+
+// summaryValues builds the summary tab contents. Aggregates are formulas over
+// the feedback sheet so that they stay current without any API traffic.
+func summaryValues(feedbackSheet string) [][]any {
+	ref := func(col int) string {
+		letter := columnLetter(col)
+		return fmt.Sprintf("%s!%s2:%s", quoteSheetName(feedbackSheet), letter, letter)
+	}
+	data := fmt.Sprintf("%s!A2:%s", quoteSheetName(feedbackSheet), columnLetter(len(feedbackHeaders)-1))
+
+	// Timestamps are stored as RFC 3339 text, so weeks are derived from the
+	// date prefix and start on Monday.
+	weekOf := fmt.Sprintf(
+		`ARRAYFORMULA(IF(%[1]s="",,TEXT(DATEVALUE(LEFT(%[1]s,10))-WEEKDAY(DATEVALUE(LEFT(%[1]s,10)),3),"yyyy-mm-dd")))`,
+		ref(colTimestamp),
+	)
+
+	return [][]any{
+		{"Vega AI Feedback Summary"},
+		{"Total submissions", fmt.Sprintf("=COUNTA(%s)", ref(colTimestamp))},
+		{"Average setup difficulty", fmt.Sprintf(`=IFERROR(ROUND(AVERAGE(%s),1),"")`, ref(colSetupDifficulty))},
+		{"Found Vega helpful", fmt.Sprintf(
+			`=IFERROR(COUNTIF(%[1]s,"very-helpful")+COUNTIF(%[1]s,"somewhat-helpful"),0)/MAX(1,COUNTA(%[1]s))`,
+			ref(colHelpfulness),
+		)},
+		{"High difficulty submissions", fmt.Sprintf("=COUNTIF(%s,\">=%d\")", ref(colSetupDifficulty), highSetupDifficulty)},
+		{},
+		// The grouped tables spill downwards, so they sit side by side.
+		{"By source", "", "", "", "By week"},
+		{
+			fmt.Sprintf(
+				`=QUERY(%[1]s,"select %[2]s, count(%[3]s), avg(%[4]s) where %[3]s is not null group by %[2]s label %[2]s 'Source', count(%[3]s) 'Submissions', avg(%[4]s) 'Avg difficulty'",0)`,
+				data, columnLetter(colSource), columnLetter(colTimestamp), columnLetter(colSetupDifficulty),
+			),
+			"", "", "",
+			fmt.Sprintf(
+				`=QUERY({%s,%s},"select Col1, count(Col2), avg(Col2) where Col1 is not null group by Col1 order by Col1 desc label Col1 'Week', count(Col2) 'Submissions', avg(Col2) 'Avg difficulty'",0)`,
+				weekOf, ref(colSetupDifficulty),
+			),
+		},
+	}
+}
+
+// sheet looks up a tab by title, returning nil when it does not exist.
+func (g *GoogleSheetsService) sheet(ctx context.Context, title string) (*sheets.Sheet, error) {
+	spreadsheet, err := g.service.Spreadsheets.Get(g.spreadsheetID).
+		Fields("sheets(properties(sheetId,title),conditionalFormats)").
+		Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spreadsheet metadata: %w", err)
+	}
+
+	for _, s := range spreadsheet.Sheets {
+		if s.Properties != nil && s.Properties.Title == title {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func dataValidationRequest(sheetID int64, col int, options []string) *sheets.Request {
+	values := make([]*sheets.ConditionValue, 0, len(options))
+	for _, option := range options {
+		values = append(values, &sheets.ConditionValue{UserEnteredValue: option})
+	}
+
+	return &sheets.Request{
+		SetDataValidation: &sheets.SetDataValidationRequest{
+			Range: &sheets.GridRange{
+				SheetId:          sheetID,
+				StartRowIndex:    1,
+				StartColumnIndex: int64(col),
+				EndColumnIndex:   int64(col + 1),
+			},
+			Rule: &sheets.DataValidationRule{
+				Condition:    &sheets.BooleanCondition{Type: "ONE_OF_LIST", Values: values},
+				ShowCustomUi: true,
+				// Not strict, so that values from older form versions are
+				// still accepted and merely flagged.
+				Strict: false,
+			},
+		},
+	}
+}
+
+func difficultyRule(sheetID int64) *sheets.ConditionalFormatRule {
+	return &sheets.ConditionalFormatRule{
+		Ranges: []*sheets.GridRange{{
+			SheetId:          sheetID,
+			StartRowIndex:    1,
+			StartColumnIndex: colSetupDifficulty,
+			EndColumnIndex:   colSetupDifficulty + 1,
+		}},
+		BooleanRule: &sheets.BooleanRule{
+			Condition: &sheets.BooleanCondition{
+				Type:   "NUMBER_GREATER_THAN_EQ",
+				Values: []*sheets.ConditionValue{{UserEnteredValue: fmt.Sprint(highSetupDifficulty)}},
+			},
+			Format: &sheets.CellFormat{
+				BackgroundColor: &sheets.Color{Red: 0.96, Green: 0.8, Blue: 0.8},
+				TextFormat:      &sheets.TextFormat{Bold: true},
+			},
+		},
+	}
+}
+
+// hasDifficultyRule reports whether the high difficulty highlight already
+// exists, so that repeated cold starts do not stack duplicate rules.
+func hasDifficultyRule(sheet *sheets.Sheet) bool {
+	for _, rule := range sheet.ConditionalFormats {
+		if rule.BooleanRule == nil || rule.BooleanRule.Condition == nil {
+			continue
+		}
+		if rule.BooleanRule.Condition.Type != "NUMBER_GREATER_THAN_EQ" {
+			continue
+		}
+		for _, r := range rule.Ranges {
+			if r.StartColumnIndex == colSetupDifficulty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// quoteSheetName quotes a tab name for use in A1 notation and formulas.
+func quoteSheetName(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
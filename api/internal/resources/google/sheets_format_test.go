@@ -0,0 +1,110 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google/sheetstest"
+)
+
+func newFormattedService(t *testing.T, srv *sheetstest.Server) *GoogleSheetsService {
+	t.Helper()
+
+	service, err := NewGoogleSheetsService(context.Background(), &SheetsConfig{
+		SpreadsheetID:   "spreadsheet-id",
+		SheetName:       "Feedback",
+		ApplyFormatting: true,
+		EnableSummary:   true,
+		ClientOptions:   srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return service
+}
+
+func countBatchUpdatesContaining(srv *sheetstest.Server, fragment string) int {
+	count := 0
+	for _, req := range srv.Requests() {
+		if strings.HasSuffix(req.Path, ":batchUpdate") && strings.Contains(string(req.Body), fragment) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGoogleSheetsService_ApplyFormatting(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	newFormattedService(t, srv)
+
+	for _, fragment := range []string{"frozenRowCount", "textFormat.bold", "ONE_OF_LIST", "pixelSize", "NUMBER_GREATER_THAN_EQ"} {
+		if countBatchUpdatesContaining(srv, fragment) == 0 {
+			t.Errorf("expected a formatting request containing %q", fragment)
+		}
+	}
+
+	// A second cold start must not stack another conditional format rule.
+	newFormattedService(t, srv)
+	if got := countBatchUpdatesContaining(srv, "addConditionalFormatRule"); got != 1 {
+		t.Errorf("expected conditional format rule to be added once, got %d", got)
+	}
+}
+
+func TestGoogleSheetsService_SummarySheet(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	newFormattedService(t, srv)
+
+	names := srv.SheetNames("spreadsheet-id")
+	if len(names) != 2 || names[1] != "Summary" {
+		t.Fatalf("expected Summary tab to be created, got %v", names)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Summary")
+	if len(rows) < 8 {
+		t.Fatalf("expected summary rows, got %v", rows)
+	}
+	if rows[1][1] != "=COUNTA('Feedback'!A2:A)" {
+		t.Errorf("unexpected total formula: %q", rows[1][1])
+	}
+	if !strings.Contains(rows[7][0], "group by H") || !strings.HasPrefix(rows[7][4], "=QUERY(") {
+		t.Errorf("expected by-source and by-week queries, got %v", rows[7])
+	}
+
+	// Recreating the service keeps a single summary tab.
+	newFormattedService(t, srv)
+	if names := srv.SheetNames("spreadsheet-id"); len(names) != 2 {
+		t.Errorf("expected summary tab not to be duplicated, got %v", names)
+	}
+}
+
+func TestGoogleSheetsService_FormattingFailureIsNotFatal(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+	srv.InjectFault(sheetstest.Fault{
+		Method:       http.MethodPost,
+		PathContains: ":batchUpdate",
+		Status:       http.StatusForbidden,
+		Times:        2,
+	})
+
+	service := newFormattedService(t, srv)
+	if err := service.AppendFeedback(context.Background(), &FeedbackData{Helpfulness: "helpful"}); err != nil {
+		t.Errorf("expected feedback storage to keep working, got %v", err)
+	}
+}
+
+func TestSummaryValues_QuotesSheetName(t *testing.T) {
+	values := summaryValues("Bob's Feedback")
+	if got := values[1][1]; got != "=COUNTA('Bob''s Feedback'!A2:A)" {
+		t.Errorf("expected quoted sheet name, got %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"google.golang.org/api/sheets/v4"
+)
+
+// MaxCellLength is the largest number of characters Google Sheets accepts in
+// a single cell. Longer values make the whole append fail.
+const MaxCellLength = 50000
+
+// overflowChunkLength is the size of each chunk written to the overflow tab.
+const overflowChunkLength = 45000
+
+// OverflowStore keeps the full text of fields that were too long to store in
+// a sheet cell.
+type OverflowStore interface {
+	// Put stores text for a field of a submission and returns a reference
+	// that is written to the feedback row.
+	Put(ctx context.Context, submissionID, field, text string) (string, error)
+	// Get returns the full text stored for a field of a submission, or an
+	// empty string when nothing was stored.
+	Get(ctx context.Context, submissionID, field string) (string, error)
+	// Delete erases all text stored for a submission.
+	Delete(ctx context.Context, submissionID string) error
+}
+
+// limitCell returns value unchanged when it fits in a cell, otherwise a
+// truncated copy ending in marker. Lengths are measured in UTF-16 code units,
+// which is how Sheets counts characters.
+func limitCell(value, marker string) (string, bool) {
+	if utf16Len(value) <= MaxCellLength {
+		return value, false
+	}
+
+	budget := MaxCellLength - utf16Len(marker)
+	var b strings.Builder
+	used := 0
+	for _, r := range value {
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if used+n > budget {
+			break
+		}
+		b.WriteRune(r)
+		used += n
+	}
+	b.WriteString(marker)
+
+	return b.String(), true
+}
+
+// limitFreeText truncates an oversized free text field and moves the full
+// text to the overflow store. The returned reference is empty when nothing
+// overflowed or the overflow store could not be written.
+func (g *GoogleSheetsService) limitFreeText(ctx context.Context, submissionID, field, value string) (string, string) {
+	if utf16Len(value) <= MaxCellLength {
+		return value, ""
+	}
+
+	ref := ""
+	if g.overflow != nil {
+		stored, err := g.overflow.Put(ctx, submissionID, field, value)
+		if err != nil {
+			// Losing the tail of an oversized field is better than losing
+			// the submission, so the row is still written.
+			logging.Errorf("Failed to store overflow text for submission %s: %v", submissionID, err)
+		} else {
+			ref = stored
+		}
+	}
+
+	marker := fmt.Sprintf(" … [truncated, %d characters]", utf16Len(value))
+	if ref != "" {
+		marker = fmt.Sprintf(" … [truncated, full text: %s]", ref)
+	}
+
+	truncated, _ := limitCell(value, marker)
+	return truncated, ref
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if l := utf16.RuneLen(r); l > 0 {
+			n += l
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// sheetsOverflowStore writes overflow text to a dedicated tab of the feedback
+// spreadsheet, split across rows so that every cell stays within limits.
+type sheetsOverflowStore struct {
+	service       *sheets.Service
+	spreadsheetID string
+	sheetName     string
+	owner         *GoogleSheetsService
+}
+
+var overflowHeaders = []any{"Reference", "Submission ID", "Field", "Part", "Parts", "Text"}
+
+// Put implements OverflowStore.
+func (s *sheetsOverflowStore) Put(ctx context.Context, submissionID, field, text string) (string, error) {
+	// The tab is created the first time it is needed, so that spreadsheets
+	// without oversized feedback are left untouched.
+	if err := s.owner.ensureTab(ctx, s.sheetName, overflowHeaders); err != nil {
+		return "", err
+	}
+
+	chunks := splitText(text, overflowChunkLength)
+	ref := fmt.Sprintf("%s:%s/%s", s.sheetName, submissionID, field)
+
+	rows := make([][]any, 0, len(chunks))
+	for i, chunk := range chunks {
+		rows = append(rows, []any{ref, submissionID, field, i + 1, len(chunks), chunk})
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(s.sheetName), columnLetter(len(overflowHeaders)-1))
+	appendCall := s.service.Spreadsheets.Values.Append(s.spreadsheetID, range_, &sheets.ValueRange{Values: rows})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		return "", fmt.Errorf("failed to append overflow text: %w", err)
+	}
+
+	return ref, nil
+}
+
+// Get implements OverflowStore.
+func (s *sheetsOverflowStore) Get(ctx context.Context, submissionID, field string) (string, error) {
+	rows, err := s.rows(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	parts := map[int]string{}
+	for _, row := range rows {
+		if row.submissionID == submissionID && row.field == field {
+			parts[row.part] = row.text
+		}
+	}
+
+	var b strings.Builder
+	for i := 1; i <= len(parts); i++ {
+		b.WriteString(parts[i])
+	}
+	return b.String(), nil
+}
+
+// Delete implements OverflowStore. The rows are kept, with their text
+// replaced by ErasedMarker, so that references in the feedback sheet still
+// resolve to something meaningful.
+func (s *sheetsOverflowStore) Delete(ctx context.Context, submissionID string) error {
+	rows, err := s.rows(ctx)
+	if err != nil {
+		return err
+	}
+
+	textCol := columnLetter(len(overflowHeaders) - 1)
+	for _, row := range rows {
+		if row.submissionID != submissionID {
+			continue
+		}
+		range_ := fmt.Sprintf("%s!%s%d", quoteSheetName(s.sheetName), textCol, row.number)
+		updateCall := s.service.Spreadsheets.Values.Update(s.spreadsheetID, range_, &sheets.ValueRange{
+			Values: [][]any{{ErasedMarker}},
+		})
+		updateCall.ValueInputOption("RAW")
+		if _, err := updateCall.Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to erase overflow text: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type overflowRow struct {
+	number       int
+	submissionID string
+	field        string
+	part         int
+	text         string
+}
+
+// rows reads the overflow tab. A missing tab has no rows.
+func (s *sheetsOverflowStore) rows(ctx context.Context) ([]overflowRow, error) {
+	sheet, err := s.owner.sheet(ctx, s.sheetName)
+	if err != nil || sheet == nil {
+		return nil, err
+	}
+
+	range_ := fmt.Sprintf("%s!A2:%s", quoteSheetName(s.sheetName), columnLetter(len(overflowHeaders)-1))
+	response, err := s.service.Spreadsheets.Values.Get(s.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overflow text: %w", err)
+	}
+
+	rows := make([]overflowRow, 0, len(response.Values))
+	for i, values := range response.Values {
+		cell := func(col int) string {
+			if col >= len(values) || values[col] == nil {
+				return ""
+			}
+			return fmt.Sprint(values[col])
+		}
+		part, _ := strconv.Atoi(cell(3))
+		rows = append(rows, overflowRow{
+			number:       i + 2,
+			submissionID: cell(1),
+			field:        cell(2),
+			part:         part,
+			text:         cell(5),
+		})
+	}
+	return rows, nil
+}
+
+// splitText splits text into chunks of at most size UTF-16 code units
+// without breaking runes.
+func splitText(text string, size int) []string {
+	var chunks []string
+	var b strings.Builder
+	used := 0
+	for _, r := range text {
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if used+n > size {
+			chunks = append(chunks, b.String())
+			b.Reset()
+			used = 0
+		}
+		b.WriteRune(r)
+		used += n
+	}
+	if b.Len() > 0 || len(chunks) == 0 {
+		chunks = append(chunks, b.String())
+	}
+	return chunks
+}
@@ -0,0 +1,192 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google/sheetstest"
+)
+
+type memoryOverflowStore struct {
+	texts map[string]string
+	err   error
+}
+
+func (m *memoryOverflowStore) Put(ctx context.Context, submissionID, field, text string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	ref := "memory:" + submissionID + "/" + field
+	m.texts[ref] = text
+	return ref, nil
+}
+
+func (m *memoryOverflowStore) Get(ctx context.Context, submissionID, field string) (string, error) {
+	return m.texts["memory:"+submissionID+"/"+field], m.err
+}
+
+func (m *memoryOverflowStore) Delete(ctx context.Context, submissionID string) error {
+	for ref := range m.texts {
+		if strings.HasPrefix(ref, "memory:"+submissionID+"/") {
+			delete(m.texts, ref)
+		}
+	}
+	return m.err
+}
+
+func TestLimitCell(t *testing.T) {
+	short := strings.Repeat("a", MaxCellLength)
+	if got, truncated := limitCell(short, "[cut]"); truncated || got != short {
+		t.Error("expected value at the limit to be kept as is")
+	}
+
+	long := strings.Repeat("a", MaxCellLength+10)
+	got, truncated := limitCell(long, "[cut]")
+	if !truncated || len(got) != MaxCellLength || !strings.HasSuffix(got, "[cut]") {
+		t.Errorf("expected truncation to the limit with a marker, got length %d", len(got))
+	}
+
+	// Emoji take two UTF-16 code units and must not be split.
+	emoji := strings.Repeat("😀", MaxCellLength)
+	got, _ = limitCell(emoji, "[cut]")
+	if n := len(utf16.Encode([]rune(got))); n > MaxCellLength {
+		t.Errorf("expected at most %d code units, got %d", MaxCellLength, n)
+	}
+	if !strings.HasPrefix(got, "😀") || strings.ContainsRune(got, '�') {
+		t.Error("expected truncation on a rune boundary")
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	chunks := splitText(strings.Repeat("b", 25), 10)
+	if len(chunks) != 3 || chunks[2] != "bbbbb" {
+		t.Errorf("unexpected chunks: %v", chunks)
+	}
+	if got := splitText("", 10); len(got) != 1 || got[0] != "" {
+		t.Errorf("expected a single empty chunk, got %v", got)
+	}
+}
+
+func TestAppendFeedback_OverflowToSheet(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+	service := newFakeBackedService(t, srv)
+
+	long := strings.Repeat("x", MaxCellLength*2)
+	feedback := &FeedbackData{Helpfulness: "helpful", AdditionalFeedback: long, SetupIssues: "docker-installation"}
+	if err := service.AppendFeedback(context.Background(), feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	row := rows[1]
+	if n := utf16Len(row[colAdditionalFeedback]); n > MaxCellLength {
+		t.Errorf("expected additional feedback to fit in a cell, got %d characters", n)
+	}
+	if !strings.Contains(row[colAdditionalFeedback], "truncated, full text: Overflow:") {
+		t.Errorf("expected truncation marker linking to overflow, got suffix %q", row[colAdditionalFeedback][len(row[colAdditionalFeedback])-60:])
+	}
+	if row[colSetupIssues] != "docker-installation" {
+		t.Errorf("expected short fields to be untouched, got %q", row[colSetupIssues])
+	}
+
+	wantRef := "Overflow:" + feedback.ID + "/additionalFeedback"
+	if row[colOverflowRef] != wantRef || feedback.OverflowRef != wantRef {
+		t.Errorf("expected overflow ref %q, got %q", wantRef, row[colOverflowRef])
+	}
+
+	overflow := srv.Values("spreadsheet-id", "Overflow")
+	if len(overflow) != 4 || overflow[0][0] != "Reference" {
+		t.Fatalf("expected header and three chunks in overflow tab, got %d rows", len(overflow))
+	}
+	var full strings.Builder
+	for _, chunk := range overflow[1:] {
+		full.WriteString(chunk[5])
+	}
+	if full.String() != long {
+		t.Error("expected overflow chunks to reassemble the full text")
+	}
+
+	stored, err := service.overflow.Get(context.Background(), feedback.ID, "additionalFeedback")
+	if err != nil || stored != long {
+		t.Errorf("expected Get to return the full text, got %d characters (err %v)", len(stored), err)
+	}
+
+	if err := service.overflow.Delete(context.Background(), feedback.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, chunk := range srv.Values("spreadsheet-id", "Overflow")[1:] {
+		if chunk[5] != ErasedMarker {
+			t.Errorf("expected overflow text to be erased, got %d characters", len(chunk[5]))
+		}
+	}
+}
+
+func TestAppendFeedback_OverflowStoreFailure(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	service, err := NewGoogleSheetsService(context.Background(), &SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		SheetName:     "Feedback",
+		OverflowStore: &memoryOverflowStore{err: errors.New("store unavailable")},
+		ClientOptions: srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	feedback := &FeedbackData{Helpfulness: "helpful", SetupIssues: strings.Repeat("y", MaxCellLength+1)}
+	if err := service.AppendFeedback(context.Background(), feedback); err != nil {
+		t.Fatalf("expected submission to be stored despite overflow failure, got %v", err)
+	}
+
+	row := srv.Values("spreadsheet-id", "Feedback")[1]
+	if !strings.HasSuffix(row[colSetupIssues], "[truncated, 50001 characters]") {
+		t.Errorf("expected plain truncation marker, got suffix %q", row[colSetupIssues][len(row[colSetupIssues])-40:])
+	}
+	if feedback.OverflowRef != "" {
+		t.Errorf("expected no overflow ref, got %q", feedback.OverflowRef)
+	}
+}
+
+func TestAppendFeedback_CustomOverflowStore(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	store := &memoryOverflowStore{texts: map[string]string{}}
+	service, err := NewGoogleSheetsService(context.Background(), &SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		SheetName:     "Feedback",
+		OverflowStore: store,
+		ClientOptions: srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	feedback := &FeedbackData{
+		Helpfulness:        "helpful",
+		SetupIssues:        strings.Repeat("s", MaxCellLength+1),
+		AdditionalFeedback: strings.Repeat("a", MaxCellLength+1),
+	}
+	if err := service.AppendFeedback(context.Background(), feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.texts) != 2 {
+		t.Errorf("expected both fields in the overflow store, got %d", len(store.texts))
+	}
+	if !strings.Contains(feedback.OverflowRef, "/setupIssues, memory:") {
+		t.Errorf("expected both refs to be recorded, got %q", feedback.OverflowRef)
+	}
+	if names := srv.SheetNames("spreadsheet-id"); len(names) != 1 {
+		t.Errorf("expected no overflow tab with a custom store, got %v", names)
+	}
+}
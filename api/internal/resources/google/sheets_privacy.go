@@ -0,0 +1,183 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"google.golang.org/api/sheets/v4"
+)
+
+// ErasedMarker replaces free text removed by an erasure request.
+const ErasedMarker = "[erased]"
+
+// Data subject request types and the stages recorded for them.
+const (
+	PrivacyRequestExport = "export"
+	PrivacyRequestErase  = "erase"
+
+	PrivacyStageRequested = "requested"
+	PrivacyStageCompleted = "completed"
+)
+
+var privacyHeaders = []any{"Timestamp", "Request ID", "Type", "Stage", "Email Hash", "Submissions"}
+
+// PrivacyRecord is one entry in the log of data subject requests. The email
+// itself is not recorded, only its hash.
+type PrivacyRecord struct {
+	RequestID string
+	Type      string
+	Stage     string
+	// Email is recorded by RecordPrivacyRequest as its keyed hash when field
+	// protection is configured, and left out otherwise.
+	Email       string
+	Submissions int
+}
+
+// NormalizeEmail returns the form of an address used for matching.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// FindFeedbackByEmail returns every submission left with the given email,
+// with any overflowed free text restored to its full length.
+func (g *GoogleSheetsService) FindFeedbackByEmail(ctx context.Context, email string) ([]*FeedbackData, error) {
+	matches, err := g.feedbackByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.openEmails(ctx, matches); err != nil {
+		return nil, err
+	}
+
+	for _, feedback := range matches {
+		if feedback.OverflowRef == "" || g.overflow == nil {
+			continue
+		}
+		for field, value := range map[string]*string{
+			"setupIssues":        &feedback.SetupIssues,
+			"additionalFeedback": &feedback.AdditionalFeedback,
+		} {
+			full, err := g.overflow.Get(ctx, feedback.ID, field)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read overflow text for submission %s: %w", feedback.ID, err)
+			}
+			if full != "" {
+				*value = full
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// EraseFeedbackByEmail removes the email and free text of every submission
+// left with the given email, including overflowed text. Setup issues keep
+// only the form's fixed options. It returns the number of submissions
+// erased. The rows are cleared with a single batch update.
+func (g *GoogleSheetsService) EraseFeedbackByEmail(ctx context.Context, email string) (int, error) {
+	matches, err := g.feedbackByEmail(ctx, email)
+	if err != nil {
+		return 0, err
+	}
+
+	var data []*sheets.ValueRange
+	for _, feedback := range matches {
+		additional := ""
+		if feedback.AdditionalFeedback != "" {
+			additional = ErasedMarker
+		}
+		data = append(data, g.feedbackCells(feedback.row, colSetupIssues,
+			keepSetupIssueOptions(feedback.SetupIssues), additional, ""))
+		// The hash identifies the submitter as much as the email does.
+		if feedback.EmailHash != "" {
+			data = append(data, g.feedbackCells(feedback.row, colEmailHash, ""))
+		}
+	}
+	if err := g.writeFeedbackCells(ctx, data); err != nil {
+		return 0, fmt.Errorf("failed to erase %d submissions: %w", len(matches), err)
+	}
+
+	for _, feedback := range matches {
+		if feedback.OverflowRef != "" && g.overflow != nil {
+			if err := g.overflow.Delete(ctx, feedback.ID); err != nil {
+				return 0, fmt.Errorf("failed to erase overflow text for submission %s: %w", feedback.ID, err)
+			}
+		}
+	}
+
+	logging.Infof("Erased personal data from %d submissions", len(matches))
+	return len(matches), nil
+}
+
+// RecordPrivacyRequest appends an entry to the privacy request log tab.
+func (g *GoogleSheetsService) RecordPrivacyRequest(ctx context.Context, record *PrivacyRecord) error {
+	if record == nil {
+		return fmt.Errorf("privacy record cannot be nil")
+	}
+
+	if err := g.ensureTab(ctx, g.privacySheet, privacyHeaders); err != nil {
+		return err
+	}
+
+	values := []any{
+		g.clock.Now().In(g.location).Format(time.RFC3339),
+		record.RequestID,
+		record.Type,
+		record.Stage,
+		g.emailHash(record.Email),
+		record.Submissions,
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.privacySheet), columnLetter(len(privacyHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{values},
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to record privacy request: %w", err)
+	}
+	return nil
+}
+
+func (g *GoogleSheetsService) feedbackByEmail(ctx context.Context, email string) ([]*FeedbackData, error) {
+	email = NormalizeEmail(email)
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	all, err := g.readFeedback(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	if g.protector != nil {
+		hashes = g.protector.Hashes(email)
+	}
+
+	var matches []*FeedbackData
+	for _, feedback := range all {
+		if g.matchesEmail(feedback, email, hashes) {
+			matches = append(matches, feedback)
+		}
+	}
+	return matches, nil
+}
+
+// keepSetupIssueOptions drops anything but the form's fixed options from a
+// comma separated list of setup issues.
+func keepSetupIssueOptions(issues string) string {
+	var kept []string
+	for _, issue := range strings.Split(issues, ",") {
+		if issue = strings.TrimSpace(issue); slices.Contains(SetupIssueOptions, issue) {
+			kept = append(kept, issue)
+		}
+	}
+	return strings.Join(kept, ", ")
+}
@@ -0,0 +1,114 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_FindAndEraseByEmail(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	long := strings.Repeat("z", MaxCellLength+100)
+	feedback := &FeedbackData{
+		Helpfulness:        "not-helpful",
+		SetupIssues:        "docker-installation, my laptop is called jane-mbp",
+		AdditionalFeedback: long,
+		Email:              "A@Example.com",
+	}
+	if err := service.AppendFeedback(ctx, feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := service.FindFeedbackByEmail(ctx, " a@example.COM ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != "id-2" || found[1].ID != feedback.ID {
+		t.Fatalf("expected both submissions for the address, got %d", len(found))
+	}
+	if found[1].AdditionalFeedback != long {
+		t.Error("expected overflowed text to be restored in full")
+	}
+
+	before := len(srv.Requests())
+	erased, err := service.EraseFeedbackByEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if erased != 2 {
+		t.Errorf("expected 2 submissions erased, got %d", erased)
+	}
+	writes := 0
+	for _, req := range srv.Requests()[before:] {
+		if req.Method != http.MethodGet && strings.HasSuffix(req.Path, "/values:batchUpdate") {
+			writes++
+		}
+	}
+	if writes != 1 {
+		t.Errorf("expected the feedback rows to be cleared with one batch update, got %d", writes)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	row := rows[len(rows)-1]
+	if row[colEmail] != "" || row[colAdditionalFeedback] != ErasedMarker || row[colSetupIssues] != "docker-installation" {
+		t.Errorf("unexpected erased row: email %q, issues %q, feedback %q", row[colEmail], row[colSetupIssues], row[colAdditionalFeedback][:min(20, len(row[colAdditionalFeedback]))])
+	}
+	if row[colSubmissionID] != feedback.ID || row[colHelpfulness] != "not-helpful" {
+		t.Error("expected non-personal fields to be kept")
+	}
+	if rows[2][colEmail] != "" || rows[2][colSetupIssues] != "docker-installation, port-conflicts" {
+		t.Errorf("unexpected erased seed row %v", rows[2])
+	}
+	if rows[1][colAdditionalFeedback] != "love it" {
+		t.Error("expected other submissions to be untouched")
+	}
+
+	for _, chunk := range srv.Values("spreadsheet-id", "Overflow")[1:] {
+		if chunk[5] != ErasedMarker {
+			t.Error("expected overflow text to be erased")
+		}
+	}
+
+	if found, _ := service.FindFeedbackByEmail(ctx, "a@example.com"); len(found) != 0 {
+		t.Errorf("expected no submissions after erasure, got %d", len(found))
+	}
+}
+
+func TestGoogleSheetsService_RecordPrivacyRequest(t *testing.T) {
+	srv, service := seedFeedback(t)
+	service.clock = fixedClock{now: time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC)}
+
+	record := &PrivacyRecord{RequestID: "req-1", Type: PrivacyRequestErase, Stage: PrivacyStageCompleted, Email: "a@example.com", Submissions: 2}
+	if err := service.RecordPrivacyRequest(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := srv.Values("spreadsheet-id", "PrivacyRequests")
+	if len(rows) != 2 || rows[0][0] != "Timestamp" {
+		t.Fatalf("expected header and one record, got %v", rows)
+	}
+	// Without keys an unkeyed hash could be reversed, so none is recorded.
+	want := []string{"2025-04-01T08:00:00Z", "req-1", "erase", "completed", "", "2"}
+	for i := range want {
+		if rows[1][i] != want[i] {
+			t.Errorf("column %d: expected %q, got %q", i, want[i], rows[1][i])
+		}
+	}
+	if strings.Contains(strings.Join(rows[1], ","), "example.com") {
+		t.Error("expected the email address not to be recorded")
+	}
+
+	service.protector = testProtector(t, "k1", "h1", map[string]byte{"k1": 1, "h1": 2})
+	record.Email = "A@example.com "
+	if err := service.RecordPrivacyRequest(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows = srv.Values("spreadsheet-id", "PrivacyRequests")
+	if hash := rows[2][4]; hash != service.protector.Hash("a@example.com") || !strings.HasPrefix(hash, "h1:") {
+		t.Errorf("expected the keyed hash of the normalized email, got %q", hash)
+	}
+}
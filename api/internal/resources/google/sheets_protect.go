@@ -0,0 +1,186 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/protect"
+	"google.golang.org/api/sheets/v4"
+)
+
+// emailField binds sealed emails to the email column.
+const emailField = "email"
+
+// KeyRotationResult summarises a RotateFieldKeys run.
+type KeyRotationResult struct {
+	// Scanned is the number of submissions with an email.
+	Scanned int `json:"scanned"`
+	// Encrypted counts plain text emails that were encrypted.
+	Encrypted int `json:"encrypted"`
+	// Rewrapped counts emails moved to the primary encryption key.
+	Rewrapped int `json:"rewrapped"`
+	// Rehashed counts email hashes recomputed with the primary hash key.
+	Rehashed int `json:"rehashed"`
+}
+
+// protectEmail returns the email cell and keyed hash to store for email.
+// Without a protector the email is stored as is and no hash is kept.
+func (g *GoogleSheetsService) protectEmail(ctx context.Context, email string) (string, string, error) {
+	if g.protector == nil || email == "" {
+		return email, "", nil
+	}
+
+	sealed, err := g.protector.Seal(ctx, emailField, email)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to protect email: %w", err)
+	}
+	return sealed, g.protector.Hash(NormalizeEmail(email)), nil
+}
+
+// openEmails decrypts the emails of submissions about to be returned to a
+// caller. Lookups work on hashes, so only returned rows are decrypted.
+func (g *GoogleSheetsService) openEmails(ctx context.Context, items []*FeedbackData) error {
+	for _, feedback := range items {
+		if !protect.IsSealed(feedback.Email) {
+			continue
+		}
+		if g.protector == nil {
+			return fmt.Errorf("submission %s has an encrypted email but no keys are configured", feedback.ID)
+		}
+
+		email, err := g.protector.Open(ctx, emailField, feedback.Email)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt email for submission %s: %w", feedback.ID, err)
+		}
+		feedback.Email = email
+	}
+	return nil
+}
+
+// emailHash returns the keyed hash recorded in place of an email in logs
+// such as the privacy request tab. An unkeyed hash of an address is
+// reversed by hashing candidate addresses, so without a protector no hash
+// is recorded.
+func (g *GoogleSheetsService) emailHash(email string) string {
+	if g.protector == nil {
+		return ""
+	}
+	return g.protector.Hash(NormalizeEmail(email))
+}
+
+// matchesEmail reports whether a stored submission was left with the
+// normalized email. Hashed rows are compared by hash under every known key;
+// rows stored before protection was enabled fall back to the plain email.
+func (g *GoogleSheetsService) matchesEmail(feedback *FeedbackData, email string, hashes []string) bool {
+	if feedback.EmailHash != "" {
+		return slices.Contains(hashes, feedback.EmailHash)
+	}
+	return !protect.IsSealed(feedback.Email) && NormalizeEmail(feedback.Email) == email
+}
+
+// RotateFieldKeys brings every stored email up to date with the current
+// keys: plain text emails are encrypted, data keys wrapped with an older key
+// are rewrapped, and hashes made with an older hash key are recomputed. Old
+// keys can be removed from the keyring once a run reports no changes.
+func (g *GoogleSheetsService) RotateFieldKeys(ctx context.Context) (*KeyRotationResult, error) {
+	if g.protector == nil {
+		return nil, fmt.Errorf("field protection is not configured")
+	}
+
+	all, err := g.readFeedback(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeyRotationResult{}
+	var changed []emailCells
+	for _, feedback := range all {
+		if feedback.Email == "" || feedback.Email == ErasedMarker {
+			continue
+		}
+		result.Scanned++
+
+		email, hash := feedback.Email, feedback.EmailHash
+		if protect.IsSealed(email) {
+			rewrapped, changed, err := g.protector.Rewrap(ctx, email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewrap email for submission %s: %w", feedback.ID, err)
+			}
+			if changed {
+				email = rewrapped
+				result.Rewrapped++
+			}
+		} else {
+			if email, err = g.protector.Seal(ctx, emailField, email); err != nil {
+				return nil, fmt.Errorf("failed to encrypt email for submission %s: %w", feedback.ID, err)
+			}
+			result.Encrypted++
+		}
+
+		if hash == "" || !g.protector.HashIsCurrent(hash) {
+			plain, err := g.protector.Open(ctx, emailField, email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt email for submission %s: %w", feedback.ID, err)
+			}
+			hash = g.protector.Hash(NormalizeEmail(plain))
+			result.Rehashed++
+		}
+
+		if email == feedback.Email && hash == feedback.EmailHash {
+			continue
+		}
+		changed = append(changed, emailCells{row: feedback.row, email: email, hash: hash})
+	}
+	if err := g.writeEmailCells(ctx, changed); err != nil {
+		return nil, fmt.Errorf("failed to update keys of %d submissions: %w", len(changed), err)
+	}
+
+	logging.Infof("Field key rotation scanned %d emails: %d encrypted, %d rewrapped, %d rehashed",
+		result.Scanned, result.Encrypted, result.Rewrapped, result.Rehashed)
+	return result, nil
+}
+
+// emailCells are the email and email hash values to write to a row.
+type emailCells struct {
+	row         int
+	email, hash string
+}
+
+// writeEmailCells overwrites the email and email hash cells of the given
+// rows with a single batch update, so that a run over many submissions
+// stays within the Sheets API write quota.
+func (g *GoogleSheetsService) writeEmailCells(ctx context.Context, cells []emailCells) error {
+	data := make([]*sheets.ValueRange, 0, 2*len(cells))
+	for _, c := range cells {
+		data = append(data,
+			g.feedbackCells(c.row, colEmail, c.email),
+			g.feedbackCells(c.row, colEmailHash, c.hash))
+	}
+	return g.writeFeedbackCells(ctx, data)
+}
+
+// feedbackCells returns the cells of a feedback row starting at col, set to
+// values.
+func (g *GoogleSheetsService) feedbackCells(row, col int, values ...any) *sheets.ValueRange {
+	return &sheets.ValueRange{
+		Range:  fmt.Sprintf("%s!%s%d:%s%d", g.sheetName, columnLetter(col), row, columnLetter(col+len(values)-1), row),
+		Values: [][]any{values},
+	}
+}
+
+// writeFeedbackCells writes ranges of the feedback sheet with a single batch
+// update.
+func (g *GoogleSheetsService) writeFeedbackCells(ctx context.Context, data []*sheets.ValueRange) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	batchCall := g.service.Spreadsheets.Values.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data:             data,
+	})
+	_, err := batchCall.Context(ctx).Do()
+	return err
+}
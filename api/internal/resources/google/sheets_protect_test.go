@@ -0,0 +1,143 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/protect"
+)
+
+func testProtector(t *testing.T, keyID, hashKeyID string, keys map[string]byte) *protect.Protector {
+	t.Helper()
+
+	keyring := &protect.Keyring{
+		PrimaryKey:     keyID,
+		Keys:           map[string]string{},
+		PrimaryHashKey: hashKeyID,
+		HashKeys:       map[string]string{},
+	}
+	for id, b := range keys {
+		key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+		if strings.HasPrefix(id, "h") {
+			keyring.HashKeys[id] = key
+		} else {
+			keyring.Keys[id] = key
+		}
+	}
+
+	p, err := protect.NewProtectorFromKeyring(keyring)
+	if err != nil {
+		t.Fatalf("failed to create protector: %v", err)
+	}
+	return p
+}
+
+func TestGoogleSheetsService_ProtectedEmail(t *testing.T) {
+	srv, service := seedFeedback(t)
+	service.protector = testProtector(t, "k1", "h1", map[string]byte{"k1": 1, "h1": 2})
+	ctx := context.Background()
+
+	feedback := &FeedbackData{Helpfulness: "very-helpful", Email: "Me@Example.com", ContactConsent: true}
+	if err := service.AppendFeedback(ctx, feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	row := rows[len(rows)-1]
+	if !protect.IsSealed(row[colEmail]) || strings.Contains(strings.Join(row, ","), "xample") {
+		t.Fatalf("expected the email to be encrypted at rest, got %v", row)
+	}
+	if row[colEmailHash] == "" || row[colEmailHash] != feedback.EmailHash {
+		t.Errorf("expected the keyed hash to be stored, got %q", row[colEmailHash])
+	}
+
+	got, err := service.GetFeedback(ctx, feedback.ID)
+	if err != nil || got.Email != "Me@Example.com" {
+		t.Fatalf("expected the email to be decrypted on read, got %+v, %v", got, err)
+	}
+
+	found, err := service.FindFeedbackByEmail(ctx, "me@example.com")
+	if err != nil || len(found) != 1 || found[0].Email != "Me@Example.com" {
+		t.Fatalf("expected a lookup by hash, got %v, %v", found, err)
+	}
+
+	// Rows stored before protection was enabled are still found.
+	if legacy, _ := service.FindFeedbackByEmail(ctx, "a@example.com"); len(legacy) != 1 || legacy[0].ID != "id-2" {
+		t.Errorf("expected the plain text row to match, got %v", legacy)
+	}
+
+	if _, err := service.EraseFeedbackByEmail(ctx, "me@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows = srv.Values("spreadsheet-id", "Feedback")
+	if row := rows[len(rows)-1]; row[colEmail] != "" || row[colEmailHash] != "" {
+		t.Errorf("expected erasure to clear the email and its hash, got %q, %q", row[colEmail], row[colEmailHash])
+	}
+}
+
+func TestGoogleSheetsService_RotateFieldKeys(t *testing.T) {
+	srv, service := seedFeedback(t)
+	service.protector = testProtector(t, "k1", "h1", map[string]byte{"k1": 1, "h1": 2})
+	ctx := context.Background()
+
+	feedback := &FeedbackData{Helpfulness: "very-helpful", Email: "me@example.com"}
+	if err := service.AppendFeedback(ctx, feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service.protector = testProtector(t, "k2", "h2", map[string]byte{"k1": 1, "k2": 3, "h1": 2, "h2": 4})
+	before := len(srv.Requests())
+	result, err := service.RotateFieldKeys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writes := 0
+	for _, req := range srv.Requests()[before:] {
+		if req.Method != http.MethodGet {
+			writes++
+			if !strings.HasSuffix(req.Path, "/values:batchUpdate") {
+				t.Errorf("expected rotation to write with one batch update, got %s %s", req.Method, req.Path)
+			}
+		}
+	}
+	if writes != 1 {
+		t.Errorf("expected one write for all rotated rows, got %d", writes)
+	}
+	want := KeyRotationResult{Scanned: 2, Encrypted: 1, Rewrapped: 1, Rehashed: 2}
+	if *result != want {
+		t.Errorf("expected %+v, got %+v", want, *result)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	if !protect.IsSealed(rows[2][colEmail]) || rows[2][colEmailHash] == "" {
+		t.Errorf("expected the legacy email to be encrypted and hashed, got %q, %q", rows[2][colEmail], rows[2][colEmailHash])
+	}
+
+	// Once rotated, the retired keys are no longer needed.
+	service.protector = testProtector(t, "k2", "h2", map[string]byte{"k2": 3, "h2": 4})
+	for _, email := range []string{"a@example.com", "me@example.com"} {
+		found, err := service.FindFeedbackByEmail(ctx, email)
+		if err != nil || len(found) != 1 || found[0].Email != email {
+			t.Errorf("expected %s to be found with the new keys only, got %v, %v", email, found, err)
+		}
+	}
+
+	again, err := service.RotateFieldKeys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.Encrypted+again.Rewrapped+again.Rehashed != 0 {
+		t.Errorf("expected a second run to change nothing, got %+v", *again)
+	}
+}
+
+func TestGoogleSheetsService_RotateFieldKeys_RequiresProtector(t *testing.T) {
+	_, service := seedFeedback(t)
+	if _, err := service.RotateFieldKeys(context.Background()); err == nil {
+		t.Error("expected an error without field protection")
+	}
+}
@@ -0,0 +1,317 @@
+package google
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is used when a FeedbackQuery does not set a limit.
+	DefaultPageSize = 50
+	// MaxPageSize caps the number of submissions returned in one page.
+	MaxPageSize = 500
+)
+
+// ErrFeedbackNotFound is returned when no submission matches an ID.
+var ErrFeedbackNotFound = errors.New("feedback not found")
+
+// FeedbackQuery filters and pages through stored feedback. Zero values
+// disable the corresponding filter.
+type FeedbackQuery struct {
+	// From and To bound the submission time. From is inclusive, To exclusive.
+	From time.Time
+	To   time.Time
+	// Source matches the submission source exactly.
+	Source string
+	// Helpfulness matches the helpfulness answer exactly.
+	Helpfulness string
+	// SetupIssue matches submissions that reported this setup issue.
+	SetupIssue string
+	// Status matches the triage status.
+	Status string
+	// Limit is the page size, defaulting to DefaultPageSize.
+	Limit int
+	// PageToken continues a previous listing.
+	PageToken string
+}
+
+// FeedbackPage is one page of stored feedback, newest first.
+type FeedbackPage struct {
+	Items         []*FeedbackData
+	NextPageToken string
+	// Total is the number of submissions matching the query across all pages.
+	Total int
+}
+
+// ListFeedback returns stored feedback matching the query, newest first.
+func (g *GoogleSheetsService) ListFeedback(ctx context.Context, query *FeedbackQuery) (*FeedbackPage, error) {
+	if query == nil {
+		query = &FeedbackQuery{}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var cursor *pageCursor
+	if query.PageToken != "" {
+		parsed, err := parsePageToken(query.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		cursor = parsed
+	}
+
+	all, err := g.readFeedback(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*FeedbackData
+	for i := len(all) - 1; i >= 0; i-- {
+		if query.matches(all[i]) {
+			matched = append(matched, all[i])
+		}
+	}
+	// Pages follow (SubmittedAt, ID) rather than row positions, so rows added
+	// or deleted between pages neither skip nor repeat items.
+	sort.SliceStable(matched, func(i, j int) bool {
+		return newerThan(matched[i], matched[j].SubmittedAt, matched[j].ID)
+	})
+
+	page := &FeedbackPage{Total: len(matched)}
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return !newerThan(matched[i], cursor.submittedAt, cursor.id) &&
+				!(matched[i].SubmittedAt.Equal(cursor.submittedAt) && matched[i].ID == cursor.id)
+		})
+	}
+	if start >= len(matched) {
+		return page, nil
+	}
+
+	end := start + limit
+	if end < len(matched) {
+		page.NextPageToken = newPageToken(matched[end-1])
+	} else {
+		end = len(matched)
+	}
+	page.Items = matched[start:end]
+
+	if err := g.openEmails(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// pageCursor is the position after the last item of a page.
+type pageCursor struct {
+	submittedAt time.Time
+	id          string
+}
+
+// newerThan reports whether feedback sorts before the submission with the
+// given time and ID, newest first.
+func newerThan(feedback *FeedbackData, submittedAt time.Time, id string) bool {
+	if !feedback.SubmittedAt.Equal(submittedAt) {
+		return feedback.SubmittedAt.After(submittedAt)
+	}
+	return feedback.ID > id
+}
+
+// newPageToken encodes the position after feedback as an opaque token.
+func newPageToken(feedback *FeedbackData) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(feedback.SubmittedAt.UTC().Format(time.RFC3339Nano) + "|" + feedback.ID))
+}
+
+func parsePageToken(token string) (*pageCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token: %q", token)
+	}
+	timestamp, id, ok := strings.Cut(string(decoded), "|")
+	submittedAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("invalid page token: %q", token)
+	}
+	return &pageCursor{submittedAt: submittedAt, id: id}, nil
+}
+
+// GetFeedback returns the submission with the given ID.
+func (g *GoogleSheetsService) GetFeedback(ctx context.Context, id string) (*FeedbackData, error) {
+	if id == "" {
+		return nil, fmt.Errorf("submission ID is required")
+	}
+
+	all, err := g.readFeedback(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, feedback := range all {
+		if feedback.ID == id {
+			if err := g.openEmails(ctx, []*FeedbackData{feedback}); err != nil {
+				return nil, err
+			}
+			return feedback, nil
+		}
+	}
+
+	return nil, ErrFeedbackNotFound
+}
+
+// FeedbackAnswers holds the multiple-choice answers of a submission. It is
+// all public statistics need, and leaves out the free text and email.
+type FeedbackAnswers struct {
+	SubmittedAt     time.Time
+	Helpfulness     string
+	SetupDifficulty int
+	DocsQuality     string
+	SetupIssues     string
+}
+
+// FeedbackAnswers returns the answers of every submission made before to, or
+// of all of them when to is zero. It reads only the leading answer columns
+// in a single request, so no email is read or decrypted.
+func (g *GoogleSheetsService) FeedbackAnswers(ctx context.Context, to time.Time) ([]FeedbackAnswers, error) {
+	range_ := fmt.Sprintf("%s!A2:%s", g.sheetName, columnLetter(colSetupIssues))
+	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback answers from sheet: %w", err)
+	}
+
+	answers := make([]FeedbackAnswers, 0, len(response.Values))
+	for _, row := range response.Values {
+		if len(row) == 0 {
+			continue
+		}
+		// parseFeedbackRow leaves the columns past the range empty.
+		parsed := parseFeedbackRow(row)
+		if !to.IsZero() && !parsed.SubmittedAt.Before(to) {
+			continue
+		}
+		answers = append(answers, FeedbackAnswers{
+			SubmittedAt:     parsed.SubmittedAt,
+			Helpfulness:     parsed.Helpfulness,
+			SetupDifficulty: parsed.SetupDifficulty,
+			DocsQuality:     parsed.DocsQuality,
+			SetupIssues:     parsed.SetupIssues,
+		})
+	}
+	return answers, nil
+}
+
+// readFeedback loads every stored submission in sheet order.
+func (g *GoogleSheetsService) readFeedback(ctx context.Context) ([]*FeedbackData, error) {
+	range_ := fmt.Sprintf("%s!A2:%s", g.sheetName, columnLetter(len(feedbackHeaders)-1))
+	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback from sheet: %w", err)
+	}
+
+	feedback := make([]*FeedbackData, 0, len(response.Values))
+	for i, row := range response.Values {
+		if len(row) == 0 {
+			continue
+		}
+		parsed := parseFeedbackRow(row)
+		parsed.row = i + 2
+		feedback = append(feedback, parsed)
+	}
+
+	return feedback, nil
+}
+
+// parseFeedbackRow converts a sheet row back into FeedbackData. Malformed
+// cells are left at their zero value rather than failing the whole read.
+func parseFeedbackRow(row []any) *FeedbackData {
+	cell := func(i int) string {
+		if i >= len(row) || row[i] == nil {
+			return ""
+		}
+		return fmt.Sprint(row[i])
+	}
+
+	feedback := &FeedbackData{
+		Helpfulness:        cell(colHelpfulness),
+		DocsQuality:        cell(colDocsQuality),
+		SetupIssues:        cell(colSetupIssues),
+		AdditionalFeedback: cell(colAdditionalFeedback),
+		Email:              cell(colEmail),
+		Source:             cell(colSource),
+		ID:                 cell(colSubmissionID),
+		OverflowRef:        cell(colOverflowRef),
+		Status:             cell(colTriageStatus),
+		Notes:              cell(colInternalNotes),
+	}
+	if feedback.Status == "" {
+		feedback.Status = TriageStatusNew
+	}
+
+	if ts, err := time.Parse(time.RFC3339, cell(colTimestamp)); err == nil {
+		feedback.SubmittedAt = ts
+	}
+	if ts, err := time.Parse(time.RFC3339, cell(colClientTimestamp)); err == nil {
+		feedback.ClientSubmittedAt = ts
+	}
+	if difficulty, err := strconv.Atoi(cell(colSetupDifficulty)); err == nil {
+		feedback.SetupDifficulty = difficulty
+	}
+	feedback.ContactConsent, _ = strconv.ParseBool(cell(colContactConsent))
+	feedback.QuoteConsent, _ = strconv.ParseBool(cell(colQuoteConsent))
+	feedback.PrivacyPolicyVersion = cell(colPrivacyPolicyVersion)
+	feedback.EmailHash = cell(colEmailHash)
+	feedback.Redactions = cell(colRedactions)
+	if ts, err := time.Parse(time.RFC3339, cell(colConsentTimestamp)); err == nil {
+		feedback.ConsentedAt = ts
+	}
+	if seconds, err := strconv.ParseInt(cell(colClockSkew), 10, 64); err == nil {
+		feedback.ClockSkew = time.Duration(seconds) * time.Second
+	}
+
+	return feedback
+}
+
+func (q *FeedbackQuery) matches(feedback *FeedbackData) bool {
+	if !q.From.IsZero() && feedback.SubmittedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !feedback.SubmittedAt.Before(q.To) {
+		return false
+	}
+	if q.Source != "" && feedback.Source != q.Source {
+		return false
+	}
+	if q.Helpfulness != "" && feedback.Helpfulness != q.Helpfulness {
+		return false
+	}
+	if q.SetupIssue != "" && !hasSetupIssue(feedback.SetupIssues, q.SetupIssue) {
+		return false
+	}
+	if q.Status != "" && feedback.Status != q.Status {
+		return false
+	}
+	return true
+}
+
+// hasSetupIssue reports whether a comma separated list of setup issues
+// contains issue.
+func hasSetupIssue(issues, issue string) bool {
+	for _, candidate := range strings.Split(issues, ",") {
+		if strings.TrimSpace(candidate) == issue {
+			return true
+		}
+	}
+	return false
+}
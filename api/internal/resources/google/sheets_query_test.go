@@ -0,0 +1,236 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google/sheetstest"
+)
+
+func seedFeedback(t *testing.T) (*sheetstest.Server, *GoogleSheetsService) {
+	t.Helper()
+
+	srv := sheetstest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetValues("spreadsheet-id", "Feedback", [][]string{
+		{"Timestamp", "Helpfulness", "Setup Difficulty", "Docs Quality", "Setup Issues", "Additional Feedback", "Email", "Source", "Submission ID"},
+		{"2025-01-01T10:00:00Z", "very-helpful", "2", "excellent", "", "love it", "", "landing-page", "id-1"},
+		{"2025-01-15T10:00:00Z", "not-helpful", "9", "poor", "docker-installation, port-conflicts", "", "a@example.com", "landing-page", "id-2"},
+		{"2025-02-01T10:00:00Z", "helpful", "5", "good", "gemini-api-key", "", "", "extension", "id-3"},
+		{"2025-02-10T10:00:00Z", "helpful", "not-a-number", "good", "port-conflicts", "", "", "landing-page", "id-4"},
+	})
+
+	return srv, newFakeBackedService(t, srv)
+}
+
+func TestGoogleSheetsService_ListFeedback_Filters(t *testing.T) {
+	_, service := seedFeedback(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		query   *FeedbackQuery
+		wantIDs []string
+	}{
+		{"nil query returns newest first", nil, []string{"id-4", "id-3", "id-2", "id-1"}},
+		{"source", &FeedbackQuery{Source: "extension"}, []string{"id-3"}},
+		{"helpfulness", &FeedbackQuery{Helpfulness: "helpful"}, []string{"id-4", "id-3"}},
+		{"setup issue", &FeedbackQuery{SetupIssue: "port-conflicts"}, []string{"id-4", "id-2"}},
+		{
+			"date range",
+			&FeedbackQuery{
+				From: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC),
+			},
+			[]string{"id-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListFeedback(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if page.Total != len(tt.wantIDs) {
+				t.Errorf("expected total %d, got %d", len(tt.wantIDs), page.Total)
+			}
+			if len(page.Items) != len(tt.wantIDs) {
+				t.Fatalf("expected %d items, got %d", len(tt.wantIDs), len(page.Items))
+			}
+			for i, id := range tt.wantIDs {
+				if page.Items[i].ID != id {
+					t.Errorf("item %d: expected ID %q, got %q", i, id, page.Items[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGoogleSheetsService_ListFeedback_Pagination(t *testing.T) {
+	_, service := seedFeedback(t)
+	ctx := context.Background()
+
+	var ids []string
+	token := ""
+	for pages := 0; pages < 5; pages++ {
+		page, err := service.ListFeedback(ctx, &FeedbackQuery{Limit: 3, PageToken: token})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		token = page.NextPageToken
+		if token == "" {
+			break
+		}
+	}
+
+	if len(ids) != 4 || ids[0] != "id-4" || ids[3] != "id-1" {
+		t.Errorf("expected all submissions across pages, got %v", ids)
+	}
+
+	if _, err := service.ListFeedback(ctx, &FeedbackQuery{PageToken: "bogus"}); err == nil {
+		t.Error("expected error for invalid page token")
+	}
+}
+
+func TestGoogleSheetsService_ListFeedback_PaginationSurvivesChanges(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	first, err := service.ListFeedback(ctx, &FeedbackQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[1].ID != "id-3" || first.NextPageToken == "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	// Between pages the newest row is erased and a newer one is added.
+	srv.SetValues("spreadsheet-id", "Feedback", [][]string{
+		{"Timestamp", "Helpfulness", "Setup Difficulty", "Docs Quality", "Setup Issues", "Additional Feedback", "Email", "Source", "Submission ID"},
+		{"2025-01-01T10:00:00Z", "very-helpful", "2", "excellent", "", "love it", "", "landing-page", "id-1"},
+		{"2025-01-15T10:00:00Z", "not-helpful", "9", "poor", "", "", "", "landing-page", "id-2"},
+		{"2025-02-01T10:00:00Z", "helpful", "5", "good", "", "", "", "extension", "id-3"},
+		{"2025-03-01T10:00:00Z", "helpful", "5", "good", "", "", "", "extension", "id-5"},
+	})
+
+	second, err := service.ListFeedback(ctx, &FeedbackQuery{Limit: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Items) != 2 || second.Items[0].ID != "id-2" || second.Items[1].ID != "id-1" || second.NextPageToken != "" {
+		t.Errorf("expected id-2 and id-1 on the second page, got %+v", second.Items)
+	}
+}
+
+func TestGoogleSheetsService_GetFeedback(t *testing.T) {
+	_, service := seedFeedback(t)
+	ctx := context.Background()
+
+	feedback, err := service.GetFeedback(ctx, "id-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feedback.Helpfulness != "not-helpful" || feedback.SetupDifficulty != 9 || feedback.Email != "a@example.com" {
+		t.Errorf("unexpected feedback: %+v", feedback)
+	}
+	if !feedback.SubmittedAt.Equal(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timestamp: %v", feedback.SubmittedAt)
+	}
+
+	malformed, err := service.GetFeedback(ctx, "id-4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if malformed.SetupDifficulty != 0 {
+		t.Errorf("expected malformed difficulty to parse as 0, got %d", malformed.SetupDifficulty)
+	}
+
+	if _, err := service.GetFeedback(ctx, "missing"); !errors.Is(err, ErrFeedbackNotFound) {
+		t.Errorf("expected ErrFeedbackNotFound, got %v", err)
+	}
+}
+
+func TestGoogleSheetsService_FeedbackAnswers(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	before := len(srv.Requests())
+	answers, err := service.FeedbackAnswers(ctx, time.Date(2025, 2, 10, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := srv.Requests()[before:]
+	if len(requests) != 1 || !strings.HasSuffix(requests[0].Path, "/values/Feedback!A2:E") {
+		t.Errorf("expected a single read of the answer columns, got %+v", requests)
+	}
+
+	if len(answers) != 3 {
+		t.Fatalf("expected the answers before the cutoff, got %+v", answers)
+	}
+	want := FeedbackAnswers{
+		SubmittedAt:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Helpfulness:     "not-helpful",
+		SetupDifficulty: 9,
+		DocsQuality:     "poor",
+		SetupIssues:     "docker-installation, port-conflicts",
+	}
+	if answers[1] != want {
+		t.Errorf("expected %+v, got %+v", want, answers[1])
+	}
+}
+
+func TestGoogleSheetsService_AppendThenGet(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+	service := newFakeBackedService(t, srv)
+	ctx := context.Background()
+
+	feedback := &FeedbackData{Helpfulness: "helpful", SetupIssues: "chrome-extension", Source: "test"}
+	if err := service.AppendFeedback(ctx, feedback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := service.GetFeedback(ctx, feedback.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.SetupIssues != "chrome-extension" || got.SubmittedAt.IsZero() {
+		t.Errorf("unexpected feedback read back: %+v", got)
+	}
+}
+
+func TestFeedbackData_RecordMatchesSheetRow(t *testing.T) {
+	srv, service := seedFeedback(t)
+	row := []string{
+		"2025-03-01T10:00:00Z", "very-helpful", "3", "yes-clear", "other", "=HYPERLINK(\"x\")",
+		"b@example.com", "landing-page", "id-5", "", "2025-03-01T10:00:30Z", "30", "triaged", "checked",
+		"TRUE", "FALSE", "2025-06-01", "2025-03-01T10:00:00Z",
+	}
+	values := srv.Values("spreadsheet-id", "Feedback")
+	srv.SetValues("spreadsheet-id", "Feedback", append(values, row))
+
+	feedback, err := service.GetFeedback(context.Background(), "id-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	record := feedback.Record()
+	if len(record) != len(FeedbackHeaders()) {
+		t.Fatalf("expected %d cells, got %d", len(FeedbackHeaders()), len(record))
+	}
+	for i := range row {
+		if record[i] != row[i] {
+			t.Errorf("column %q: expected %q, got %q", FeedbackHeaders()[i], row[i], record[i])
+		}
+	}
+}
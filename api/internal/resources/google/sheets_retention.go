@@ -0,0 +1,342 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"google.golang.org/api/sheets/v4"
+)
+
+var retentionHeaders = []any{"Timestamp", "Email Cutoff", "Row Cutoff", "Emails Dropped", "Rows Deleted", "Pending Subscribers Deleted", "Privacy Requests Deleted", "Webhook Deliveries Deleted"}
+
+// RetentionPolicy limits how long stored feedback and the logs about it are
+// kept. Zero durations disable the corresponding rule.
+type RetentionPolicy struct {
+	// EmailMaxAge is how long emails, their hashes and internal notes are
+	// kept. Notes go with the email because they often record contact with
+	// the submitter.
+	EmailMaxAge time.Duration
+	// RowMaxAge is how long whole submissions are kept.
+	RowMaxAge time.Duration
+	// PendingMaxAge is how long subscribers who never confirmed their
+	// subscription are kept after their last confirmation email.
+	PendingMaxAge time.Duration
+	// LogMaxAge is how long entries of the privacy request and webhook
+	// delivery logs are kept. Privacy request entries hold a keyed hash of
+	// the requester's email, and delivery entries the submission sent and
+	// the receiver's error.
+	LogMaxAge time.Duration
+}
+
+// RetentionPolicyFromEnv reads RETENTION_EMAIL_DAYS, RETENTION_ROW_DAYS,
+// RETENTION_PENDING_DAYS and RETENTION_LOG_DAYS.
+func RetentionPolicyFromEnv() (*RetentionPolicy, error) {
+	policy := &RetentionPolicy{}
+	for key, target := range map[string]*time.Duration{
+		"RETENTION_EMAIL_DAYS":   &policy.EmailMaxAge,
+		"RETENTION_ROW_DAYS":     &policy.RowMaxAge,
+		"RETENTION_PENDING_DAYS": &policy.PendingMaxAge,
+		"RETENTION_LOG_DAYS":     &policy.LogMaxAge,
+	} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("%s must be a positive number of days, got %q", key, value)
+		}
+		*target = time.Duration(days) * 24 * time.Hour
+	}
+	return policy, nil
+}
+
+// IsZero reports whether the policy has no rules.
+func (p *RetentionPolicy) IsZero() bool {
+	return p == nil || (p.EmailMaxAge <= 0 && p.RowMaxAge <= 0 && p.PendingMaxAge <= 0 && p.LogMaxAge <= 0)
+}
+
+// RetentionReport lists what a retention run removed, or would remove in a
+// dry run.
+type RetentionReport struct {
+	DryRun      bool       `json:"dryRun"`
+	EmailCutoff *time.Time `json:"emailCutoff,omitempty"`
+	RowCutoff   *time.Time `json:"rowCutoff,omitempty"`
+	// EmailsDropped lists submissions whose email and internal notes were
+	// removed. Submissions deleted outright are not listed again.
+	EmailsDropped []string `json:"emailsDropped"`
+	// RowsDeleted lists submissions that were deleted.
+	RowsDeleted []string `json:"rowsDeleted"`
+	// PendingCutoff and SubscribersDeleted cover subscribers deleted
+	// because they never confirmed their subscription.
+	PendingCutoff      *time.Time `json:"pendingCutoff,omitempty"`
+	SubscribersDeleted []string   `json:"subscribersDeleted"`
+	// LogCutoff, PrivacyRequestsDeleted and DeliveriesDeleted cover the
+	// entries deleted from the privacy request and webhook delivery logs.
+	LogCutoff              *time.Time `json:"logCutoff,omitempty"`
+	PrivacyRequestsDeleted int        `json:"privacyRequestsDeleted"`
+	DeliveriesDeleted      int        `json:"deliveriesDeleted"`
+}
+
+// ApplyRetention enforces policy on stored feedback, subscribers and logs.
+// Emails and internal notes older than the email cutoff are cleared,
+// submissions older than the row cutoff are deleted along with their
+// overflow text, pending subscribers last emailed before the pending cutoff
+// are deleted, and so are log entries older than the log cutoff. Unless
+// dryRun is set, a summary is appended to the retention tab.
+func (g *GoogleSheetsService) ApplyRetention(ctx context.Context, policy *RetentionPolicy, dryRun bool) (*RetentionReport, error) {
+	if policy.IsZero() {
+		return nil, fmt.Errorf("retention policy has no rules")
+	}
+
+	now := g.clock.Now()
+	report := &RetentionReport{DryRun: dryRun, EmailsDropped: []string{}, RowsDeleted: []string{}, SubscribersDeleted: []string{}}
+	if policy.EmailMaxAge > 0 {
+		cutoff := now.Add(-policy.EmailMaxAge)
+		report.EmailCutoff = &cutoff
+	}
+	if policy.RowMaxAge > 0 {
+		cutoff := now.Add(-policy.RowMaxAge)
+		report.RowCutoff = &cutoff
+	}
+	if policy.PendingMaxAge > 0 {
+		cutoff := now.Add(-policy.PendingMaxAge)
+		report.PendingCutoff = &cutoff
+	}
+	if policy.LogMaxAge > 0 {
+		cutoff := now.Add(-policy.LogMaxAge)
+		report.LogCutoff = &cutoff
+	}
+
+	all, err := g.readFeedback(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropEmail, deleteRows []*FeedbackData
+	for _, feedback := range all {
+		if feedback.SubmittedAt.IsZero() {
+			continue
+		}
+		switch {
+		case report.RowCutoff != nil && feedback.SubmittedAt.Before(*report.RowCutoff):
+			deleteRows = append(deleteRows, feedback)
+			report.RowsDeleted = append(report.RowsDeleted, feedback.ID)
+		case report.EmailCutoff != nil && feedback.SubmittedAt.Before(*report.EmailCutoff) &&
+			(feedback.Email != "" || feedback.EmailHash != "" || feedback.Notes != ""):
+			dropEmail = append(dropEmail, feedback)
+			report.EmailsDropped = append(report.EmailsDropped, feedback.ID)
+		}
+	}
+
+	var deleteSubscribers []int
+	if report.PendingCutoff != nil {
+		subscribers, err := g.readSubscribers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, subscriber := range subscribers {
+			lastAsked := subscriber.RequestedAt
+			if lastAsked.IsZero() {
+				lastAsked = subscriber.CreatedAt
+			}
+			if subscriber.Status == SubscriberPending && !lastAsked.IsZero() && lastAsked.Before(*report.PendingCutoff) {
+				deleteSubscribers = append(deleteSubscribers, subscriber.row)
+				report.SubscribersDeleted = append(report.SubscribersDeleted, subscriber.ID)
+			}
+		}
+	}
+
+	var expiredRequests, expiredDeliveries []int
+	if report.LogCutoff != nil {
+		if expiredRequests, err = g.expiredLogRows(ctx, g.privacySheet, *report.LogCutoff); err != nil {
+			return nil, err
+		}
+		if expiredDeliveries, err = g.expiredLogRows(ctx, g.webhookSheet, *report.LogCutoff); err != nil {
+			return nil, err
+		}
+		report.PrivacyRequestsDeleted = len(expiredRequests)
+		report.DeliveriesDeleted = len(expiredDeliveries)
+	}
+
+	if dryRun {
+		logging.Info("Retention dry run", logging.Fields{
+			"count": len(report.EmailsDropped) + len(report.RowsDeleted) + len(report.SubscribersDeleted) +
+				report.PrivacyRequestsDeleted + report.DeliveriesDeleted,
+		})
+		return report, nil
+	}
+
+	cleared := make([]*sheets.ValueRange, 0, 3*len(dropEmail))
+	for _, feedback := range dropEmail {
+		cleared = append(cleared,
+			g.feedbackCells(feedback.row, colEmail, ""),
+			g.feedbackCells(feedback.row, colEmailHash, ""),
+			g.feedbackCells(feedback.row, colInternalNotes, ""))
+	}
+	if err := g.writeFeedbackCells(ctx, cleared); err != nil {
+		return nil, fmt.Errorf("failed to drop emails of %d submissions: %w", len(dropEmail), err)
+	}
+
+	// Overflow text goes first, so that a failed row deletion leaves the
+	// row to be retried rather than orphaned overflow text.
+	for _, feedback := range deleteRows {
+		if feedback.OverflowRef == "" || g.overflow == nil {
+			continue
+		}
+		if err := g.overflow.Delete(ctx, feedback.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete overflow text for submission %s: %w", feedback.ID, err)
+		}
+	}
+	if err := g.deleteRows(ctx, deleteRows); err != nil {
+		return nil, err
+	}
+	if err := g.deleteSheetRows(ctx, g.subscribersSheet, deleteSubscribers); err != nil {
+		return nil, fmt.Errorf("failed to delete pending subscribers: %w", err)
+	}
+	if err := g.deleteSheetRows(ctx, g.privacySheet, expiredRequests); err != nil {
+		return nil, fmt.Errorf("failed to delete expired privacy requests: %w", err)
+	}
+	if err := g.deleteSheetRows(ctx, g.webhookSheet, expiredDeliveries); err != nil {
+		return nil, fmt.Errorf("failed to delete expired webhook deliveries: %w", err)
+	}
+
+	if err := g.recordRetention(ctx, report); err != nil {
+		return nil, err
+	}
+
+	logging.Infof("Retention dropped %d emails and deleted %d submissions, %d pending subscribers, %d privacy requests and %d webhook deliveries",
+		len(report.EmailsDropped), len(report.RowsDeleted), len(report.SubscribersDeleted),
+		report.PrivacyRequestsDeleted, report.DeliveriesDeleted)
+	return report, nil
+}
+
+// expiredLogRows returns the 1-based rows of a log tab whose timestamp, in
+// the first column, is before cutoff. A missing tab has nothing to expire.
+func (g *GoogleSheetsService) expiredLogRows(ctx context.Context, title string, cutoff time.Time) ([]int, error) {
+	sheet, err := g.sheet(ctx, title)
+	if err != nil || sheet == nil {
+		return nil, err
+	}
+
+	range_ := fmt.Sprintf("%s!A2:A", quoteSheetName(title))
+	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from sheet: %w", title, err)
+	}
+
+	var rows []int
+	for i, values := range response.Values {
+		if len(values) == 0 {
+			continue
+		}
+		if ts, err := time.Parse(time.RFC3339, fmt.Sprint(values[0])); err == nil && ts.Before(cutoff) {
+			rows = append(rows, i+2)
+		}
+	}
+	return rows, nil
+}
+
+// deleteRows removes submissions from the feedback sheet in one batch.
+func (g *GoogleSheetsService) deleteRows(ctx context.Context, items []*FeedbackData) error {
+	rows := make([]int, 0, len(items))
+	for _, feedback := range items {
+		rows = append(rows, feedback.row)
+	}
+	if err := g.deleteSheetRows(ctx, g.sheetName, rows); err != nil {
+		return fmt.Errorf("failed to delete expired submissions: %w", err)
+	}
+	return nil
+}
+
+// deleteSheetRows removes 1-based rows from a tab in one batch.
+func (g *GoogleSheetsService) deleteSheetRows(ctx context.Context, title string, rows []int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sheet, err := g.sheet(ctx, title)
+	if err != nil {
+		return err
+	}
+	if sheet == nil {
+		return fmt.Errorf("sheet %q not found", title)
+	}
+
+	_, err = g.service.Spreadsheets.BatchUpdate(g.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: deleteRowRequests(sheet.Properties.SheetId, rows),
+	}).Context(ctx).Do()
+	return err
+}
+
+// deleteRowRequests returns the requests deleting 1-based rows from a tab,
+// bottom up so that earlier deletions do not shift the rows still to be
+// deleted.
+func deleteRowRequests(sheetID int64, rows []int) []*sheets.Request {
+	rows = slices.Clone(rows)
+	sort.Sort(sort.Reverse(sort.IntSlice(rows)))
+
+	var requests []*sheets.Request
+	for i := 0; i < len(rows); {
+		// Merge runs of adjacent rows into a single range.
+		end := rows[i]
+		start := end
+		for i++; i < len(rows) && rows[i] == start-1; i++ {
+			start = rows[i]
+		}
+		requests = append(requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetID,
+					Dimension:  "ROWS",
+					StartIndex: int64(start - 1),
+					EndIndex:   int64(end),
+				},
+			},
+		})
+	}
+	return requests
+}
+
+// recordRetention appends a run summary to the retention tab.
+func (g *GoogleSheetsService) recordRetention(ctx context.Context, report *RetentionReport) error {
+	if err := g.ensureTab(ctx, g.retentionSheet, retentionHeaders); err != nil {
+		return err
+	}
+
+	cutoff := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.In(g.location).Format(time.RFC3339)
+	}
+
+	values := []any{
+		g.clock.Now().In(g.location).Format(time.RFC3339),
+		cutoff(report.EmailCutoff),
+		cutoff(report.RowCutoff),
+		len(report.EmailsDropped),
+		len(report.RowsDeleted),
+		len(report.SubscribersDeleted),
+		report.PrivacyRequestsDeleted,
+		report.DeliveriesDeleted,
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.retentionSheet), columnLetter(len(retentionHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{values},
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to record retention summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package google
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_ApplyRetention(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	// An old submission with overflowed text, appended after the seed rows
+	// so that expired rows are not adjacent.
+	service.clock = fixedClock{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	old := &FeedbackData{Helpfulness: "not-helpful", AdditionalFeedback: strings.Repeat("z", MaxCellLength+10)}
+	if err := service.AppendFeedback(ctx, old); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service.clock = fixedClock{now: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := service.UpdateTriage(ctx, "id-2", &TriageUpdate{Note: "Replied to the submitter"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := &RetentionPolicy{EmailMaxAge: 30 * 24 * time.Hour, RowMaxAge: 50 * 24 * time.Hour}
+
+	before := srv.Values("spreadsheet-id", "Feedback")
+	report, err := service.ApplyRetention(ctx, policy, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.DryRun || strings.Join(report.RowsDeleted, ",") != "id-1,"+old.ID || strings.Join(report.EmailsDropped, ",") != "id-2" {
+		t.Fatalf("unexpected dry run report %+v", report)
+	}
+	if after := srv.Values("spreadsheet-id", "Feedback"); len(after) != len(before) || after[2][colEmail] != "a@example.com" {
+		t.Fatal("expected a dry run to change nothing")
+	}
+	if names := srv.SheetNames("spreadsheet-id"); strings.Contains(strings.Join(names, ","), "Retention") {
+		t.Error("expected a dry run not to write a summary")
+	}
+
+	report, err = service.ApplyRetention(ctx, policy, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.DryRun || len(report.RowsDeleted) != 2 || len(report.EmailsDropped) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	var ids []string
+	for _, row := range rows[1:] {
+		ids = append(ids, row[colSubmissionID])
+	}
+	if strings.Join(ids, ",") != "id-2,id-3,id-4" {
+		t.Errorf("expected expired rows to be deleted, got %v", ids)
+	}
+	if rows[1][colEmail] != "" || rows[1][colInternalNotes] != "" || rows[1][colHelpfulness] != "not-helpful" {
+		t.Errorf("expected only the email and notes to be dropped, got %v", rows[1])
+	}
+
+	for _, chunk := range srv.Values("spreadsheet-id", "Overflow")[1:] {
+		if chunk[5] != ErasedMarker {
+			t.Error("expected overflow text of deleted rows to be erased")
+		}
+	}
+
+	summary := srv.Values("spreadsheet-id", "Retention")
+	want := []string{"2025-03-01T00:00:00Z", "2025-01-30T00:00:00Z", "2025-01-10T00:00:00Z", "1", "2", "0", "0", "0"}
+	if len(summary) != 2 || strings.Join(summary[1], ",") != strings.Join(want, ",") {
+		t.Errorf("expected summary %v, got %v", want, summary)
+	}
+}
+
+func TestGoogleSheetsService_ApplyRetention_PendingSubscribers(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, subscriber := range []*Subscriber{
+		{Email: "stale@example.com", Status: SubscriberPending, RequestedAt: now.AddDate(0, 0, -10)},
+		{Email: "fresh@example.com", Status: SubscriberPending, RequestedAt: now.AddDate(0, 0, -1)},
+		{Email: "confirmed@example.com", Status: SubscriberConfirmed, RequestedAt: now.AddDate(0, 0, -90)},
+	} {
+		if err := service.SaveSubscriber(ctx, subscriber); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	stale := srv.Values("spreadsheet-id", "Subscribers")[1][subColID]
+
+	service.clock = fixedClock{now: now}
+	report, err := service.ApplyRetention(ctx, &RetentionPolicy{PendingMaxAge: 7 * 24 * time.Hour}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(report.SubscribersDeleted, ",") != stale || len(report.RowsDeleted)+len(report.EmailsDropped) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	var emails []string
+	for _, row := range srv.Values("spreadsheet-id", "Subscribers")[1:] {
+		emails = append(emails, row[subColEmail])
+	}
+	if strings.Join(emails, ",") != "fresh@example.com,confirmed@example.com" {
+		t.Errorf("expected only the stale pending subscriber to be deleted, got %v", emails)
+	}
+	if len(srv.Values("spreadsheet-id", "Feedback")) != 5 {
+		t.Error("expected feedback to be left alone")
+	}
+}
+
+func TestGoogleSheetsService_ApplyRetention_Logs(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, at := range []time.Time{now.AddDate(0, 0, -100), now.AddDate(0, 0, -1)} {
+		service.clock = fixedClock{now: at}
+		if err := service.RecordPrivacyRequest(ctx, &PrivacyRecord{RequestID: "req", Type: PrivacyRequestExport, Stage: PrivacyStageRequested}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := service.RecordDelivery(ctx, &DeliveryRecord{SubmissionID: "id-1", Target: "slack", Success: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	service.clock = fixedClock{now: now}
+	policy := &RetentionPolicy{LogMaxAge: 90 * 24 * time.Hour}
+	report, err := service.ApplyRetention(ctx, policy, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.PrivacyRequestsDeleted != 1 || report.DeliveriesDeleted != 1 || len(srv.Values("spreadsheet-id", "PrivacyRequests")) != 3 {
+		t.Fatalf("expected a dry run to only count expired entries, got %+v", report)
+	}
+
+	if _, err := service.ApplyRetention(ctx, policy, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tab := range []string{"PrivacyRequests", "WebhookDeliveries"} {
+		rows := srv.Values("spreadsheet-id", tab)
+		if len(rows) != 2 || rows[1][0] != "2025-02-28T00:00:00Z" {
+			t.Errorf("%s: expected only the recent entry to be kept, got %v", tab, rows)
+		}
+	}
+	if len(srv.Values("spreadsheet-id", "Feedback")) != 5 {
+		t.Error("expected feedback to be left alone")
+	}
+}
+
+func TestGoogleSheetsService_ApplyRetention_RequiresRules(t *testing.T) {
+	_, service := seedFeedback(t)
+	if _, err := service.ApplyRetention(context.Background(), &RetentionPolicy{}, true); err == nil {
+		t.Error("expected an error for an empty policy")
+	}
+}
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv("RETENTION_EMAIL_DAYS", "180")
+	t.Setenv("RETENTION_ROW_DAYS", "")
+	t.Setenv("RETENTION_PENDING_DAYS", "7")
+	t.Setenv("RETENTION_LOG_DAYS", "365")
+
+	policy, err := RetentionPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.EmailMaxAge != 180*24*time.Hour || policy.RowMaxAge != 0 || policy.PendingMaxAge != 7*24*time.Hour || policy.LogMaxAge != 365*24*time.Hour {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	t.Setenv("RETENTION_ROW_DAYS", "-1")
+	if _, err := RetentionPolicyFromEnv(); err == nil {
+		t.Error("expected an error for a negative age")
+	}
+}
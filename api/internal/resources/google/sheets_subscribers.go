@@ -0,0 +1,282 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"github.com/benidevo/vega-ai-landing-page/api/internal/protect"
+	"google.golang.org/api/sheets/v4"
+)
+
+// ErrSubscriberNotFound is returned when no subscriber matches an email.
+var ErrSubscriberNotFound = errors.New("subscriber not found")
+
+// Subscription statuses. Subscribers start pending until they follow the
+// confirmation link.
+const (
+	SubscriberPending      = "pending"
+	SubscriberConfirmed    = "confirmed"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// Topics subscribers can choose to hear about.
+const (
+	TopicReleases         = "releases"
+	TopicExtensionUpdates = "extension-updates"
+)
+
+// Topics lists every valid subscription topic.
+var Topics = []string{TopicReleases, TopicExtensionUpdates}
+
+// IsValidTopic reports whether topic is one of Topics.
+func IsValidTopic(topic string) bool {
+	return slices.Contains(Topics, topic)
+}
+
+var subscriberHeaders = []any{
+	"Created",
+	"Subscriber ID",
+	"Email",
+	"Email Hash",
+	"Topics",
+	"Status",
+	"Requested At",
+	"Confirmed At",
+	"Unsubscribed At",
+	"Privacy Policy Version",
+}
+
+// Column indexes of the subscribers tab, matching subscriberHeaders.
+const (
+	subColCreated = iota
+	subColID
+	subColEmail
+	subColEmailHash
+	subColTopics
+	subColStatus
+	subColRequestedAt
+	subColConfirmedAt
+	subColUnsubscribedAt
+	subColPrivacyPolicyVersion
+)
+
+// Subscriber is one entry in the subscribers tab. There is at most one entry
+// per email address; its hash is always stored so that the entry can be
+// found again after unsubscribing clears the address.
+type Subscriber struct {
+	ID        string
+	CreatedAt time.Time
+	Email     string
+	EmailHash string
+	Topics    []string
+	Status    string
+	// RequestedAt is when a confirmation email was last sent.
+	RequestedAt          time.Time
+	ConfirmedAt          time.Time
+	UnsubscribedAt       time.Time
+	PrivacyPolicyVersion string
+
+	// row is the 1-based sheet row the subscriber was read from.
+	row int
+}
+
+// FindSubscriber returns the subscriber stored for an email address, with
+// the address decrypted.
+func (g *GoogleSheetsService) FindSubscriber(ctx context.Context, email string) (*Subscriber, error) {
+	email = NormalizeEmail(email)
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	all, err := g.readSubscribers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	if g.protector != nil {
+		hashes = g.protector.Hashes(email)
+	}
+
+	for _, subscriber := range all {
+		matches := slices.Contains(hashes, subscriber.EmailHash)
+		if subscriber.EmailHash == "" {
+			matches = !protect.IsSealed(subscriber.Email) && NormalizeEmail(subscriber.Email) == email
+		}
+		if !matches {
+			continue
+		}
+
+		if protect.IsSealed(subscriber.Email) {
+			if g.protector == nil {
+				return nil, fmt.Errorf("subscriber %s has an encrypted email but no keys are configured", subscriber.ID)
+			}
+			if subscriber.Email, err = g.protector.Open(ctx, emailField, subscriber.Email); err != nil {
+				return nil, fmt.Errorf("failed to decrypt email for subscriber %s: %w", subscriber.ID, err)
+			}
+		}
+		return subscriber, nil
+	}
+
+	return nil, ErrSubscriberNotFound
+}
+
+// SaveSubscriber stores a subscriber. Subscribers returned by FindSubscriber
+// are updated in place; new ones are appended with a generated ID.
+func (g *GoogleSheetsService) SaveSubscriber(ctx context.Context, subscriber *Subscriber) error {
+	if subscriber == nil {
+		return fmt.Errorf("subscriber cannot be nil")
+	}
+	if !slices.Contains([]string{SubscriberPending, SubscriberConfirmed, SubscriberUnsubscribed}, subscriber.Status) {
+		return fmt.Errorf("invalid subscriber status %q", subscriber.Status)
+	}
+
+	if subscriber.row == 0 {
+		if subscriber.Email == "" {
+			return fmt.Errorf("email is required")
+		}
+		id, err := newSubmissionID()
+		if err != nil {
+			return fmt.Errorf("failed to generate subscriber ID: %w", err)
+		}
+		subscriber.ID = id
+		subscriber.CreatedAt = g.clock.Now()
+	}
+
+	// The keyed hash is kept after unsubscribing clears the address, so that
+	// a returning subscriber is recognised. Without a protector no hash is
+	// kept and only the address matches.
+	email, _, err := g.protectEmail(ctx, subscriber.Email)
+	if err != nil {
+		return err
+	}
+	if subscriber.Email != "" {
+		subscriber.EmailHash = g.emailHash(subscriber.Email)
+	}
+
+	values := []any{
+		g.formatTime(subscriber.CreatedAt),
+		subscriber.ID,
+		email,
+		subscriber.EmailHash,
+		strings.Join(subscriber.Topics, ", "),
+		subscriber.Status,
+		g.formatTime(subscriber.RequestedAt),
+		g.formatTime(subscriber.ConfirmedAt),
+		g.formatTime(subscriber.UnsubscribedAt),
+		subscriber.PrivacyPolicyVersion,
+	}
+
+	if subscriber.row > 0 {
+		range_ := fmt.Sprintf("%s!A%d:%s%d", quoteSheetName(g.subscribersSheet),
+			subscriber.row, columnLetter(len(subscriberHeaders)-1), subscriber.row)
+		updateCall := g.service.Spreadsheets.Values.Update(g.spreadsheetID, range_, &sheets.ValueRange{
+			Values: [][]any{values},
+		})
+		updateCall.ValueInputOption("RAW")
+		if _, err := updateCall.Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to update subscriber %s: %w", subscriber.ID, err)
+		}
+		return nil
+	}
+
+	if err := g.ensureTab(ctx, g.subscribersSheet, subscriberHeaders); err != nil {
+		return err
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.subscribersSheet), columnLetter(len(subscriberHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{values},
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to append subscriber: %w", err)
+	}
+
+	logging.Info("Added subscriber", logging.Fields{"status": subscriber.Status})
+	return nil
+}
+
+// EraseSubscriber deletes the subscriber stored for an email address, along
+// with its hash, and reports whether there was one.
+func (g *GoogleSheetsService) EraseSubscriber(ctx context.Context, email string) (bool, error) {
+	subscriber, err := g.FindSubscriber(ctx, email)
+	if errors.Is(err, ErrSubscriberNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := g.deleteSheetRows(ctx, g.subscribersSheet, []int{subscriber.row}); err != nil {
+		return false, fmt.Errorf("failed to erase subscriber %s: %w", subscriber.ID, err)
+	}
+	return true, nil
+}
+
+// readSubscribers loads every subscriber in sheet order. A missing tab means
+// there are no subscribers yet.
+func (g *GoogleSheetsService) readSubscribers(ctx context.Context) ([]*Subscriber, error) {
+	sheet, err := g.sheet(ctx, g.subscribersSheet)
+	if err != nil || sheet == nil {
+		return nil, err
+	}
+
+	range_ := fmt.Sprintf("%s!A2:%s", quoteSheetName(g.subscribersSheet), columnLetter(len(subscriberHeaders)-1))
+	response, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, range_).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscribers from sheet: %w", err)
+	}
+
+	subscribers := make([]*Subscriber, 0, len(response.Values))
+	for i, row := range response.Values {
+		if len(row) == 0 {
+			continue
+		}
+		cell := func(col int) string {
+			if col >= len(row) || row[col] == nil {
+				return ""
+			}
+			return fmt.Sprint(row[col])
+		}
+		parseTime := func(col int) time.Time {
+			ts, _ := time.Parse(time.RFC3339, cell(col))
+			return ts
+		}
+
+		subscriber := &Subscriber{
+			ID:                   cell(subColID),
+			CreatedAt:            parseTime(subColCreated),
+			Email:                cell(subColEmail),
+			EmailHash:            cell(subColEmailHash),
+			Status:               cell(subColStatus),
+			RequestedAt:          parseTime(subColRequestedAt),
+			ConfirmedAt:          parseTime(subColConfirmedAt),
+			UnsubscribedAt:       parseTime(subColUnsubscribedAt),
+			PrivacyPolicyVersion: cell(subColPrivacyPolicyVersion),
+			row:                  i + 2,
+		}
+		for _, topic := range strings.Split(cell(subColTopics), ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				subscriber.Topics = append(subscriber.Topics, topic)
+			}
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+	return subscribers, nil
+}
+
+// formatTime formats a timestamp for the sheet, leaving zero times blank.
+func (g *GoogleSheetsService) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(g.location).Format(time.RFC3339)
+}
@@ -0,0 +1,112 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/protect"
+)
+
+func TestGoogleSheetsService_Subscribers(t *testing.T) {
+	srv, service := seedFeedback(t)
+	service.protector = testProtector(t, "k1", "h1", map[string]byte{"k1": 1, "h1": 2})
+	ctx := context.Background()
+
+	if _, err := service.FindSubscriber(ctx, "me@example.com"); !errors.Is(err, ErrSubscriberNotFound) {
+		t.Fatalf("expected ErrSubscriberNotFound before the tab exists, got %v", err)
+	}
+
+	requested := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	subscriber := &Subscriber{
+		Email:       "Me@Example.com",
+		Topics:      []string{TopicReleases, TopicExtensionUpdates},
+		Status:      SubscriberPending,
+		RequestedAt: requested,
+	}
+	if err := service.SaveSubscriber(ctx, subscriber); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Subscribers")
+	if len(rows) != 2 || rows[0][subColEmailHash] != "Email Hash" {
+		t.Fatalf("expected a header and one subscriber, got %v", rows)
+	}
+	if row := rows[1]; !protect.IsSealed(row[subColEmail]) || row[subColEmailHash] == "" || row[subColID] != subscriber.ID {
+		t.Fatalf("expected an encrypted email with its hash, got %v", row)
+	}
+
+	found, err := service.FindSubscriber(ctx, " me@EXAMPLE.com ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Email != "Me@Example.com" || found.Status != SubscriberPending || strings.Join(found.Topics, ",") != "releases,extension-updates" || !found.RequestedAt.Equal(requested) {
+		t.Fatalf("unexpected subscriber %+v", found)
+	}
+
+	// Unsubscribing clears the address, but the entry is still found.
+	found.Status = SubscriberUnsubscribed
+	found.Email = ""
+	if err := service.SaveSubscriber(ctx, found); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows = srv.Values("spreadsheet-id", "Subscribers")
+	if len(rows) != 2 || rows[1][subColEmail] != "" || rows[1][subColStatus] != SubscriberUnsubscribed {
+		t.Fatalf("expected the row to be updated in place, got %v", rows)
+	}
+
+	again, err := service.FindSubscriber(ctx, "me@example.com")
+	if err != nil || again.ID != subscriber.ID || again.Email != "" {
+		t.Fatalf("expected the unsubscribed entry, got %+v, %v", again, err)
+	}
+
+	if _, err := service.FindSubscriber(ctx, "other@example.com"); !errors.Is(err, ErrSubscriberNotFound) {
+		t.Errorf("expected ErrSubscriberNotFound, got %v", err)
+	}
+	if err := service.SaveSubscriber(ctx, &Subscriber{Email: "x@example.com", Status: "maybe"}); err == nil {
+		t.Error("expected an error for an invalid status")
+	}
+}
+
+func TestGoogleSheetsService_Subscribers_Unprotected(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	if err := service.SaveSubscriber(ctx, &Subscriber{Email: "a@example.com", Status: SubscriberPending}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	row := srv.Values("spreadsheet-id", "Subscribers")[1]
+	if row[subColEmail] != "a@example.com" || (len(row) > subColEmailHash && row[subColEmailHash] != "") {
+		t.Errorf("expected a plain email without a hash, got %v", row)
+	}
+	if found, err := service.FindSubscriber(ctx, "A@example.com"); err != nil || found.Email != "a@example.com" {
+		t.Errorf("expected the subscriber, got %+v, %v", found, err)
+	}
+}
+
+func TestGoogleSheetsService_EraseSubscriber(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if err := service.SaveSubscriber(ctx, &Subscriber{Email: email, Status: SubscriberConfirmed}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	erased, err := service.EraseSubscriber(ctx, "A@example.com")
+	if err != nil || !erased {
+		t.Fatalf("expected the subscriber to be erased, got %v, %v", erased, err)
+	}
+	rows := srv.Values("spreadsheet-id", "Subscribers")
+	if len(rows) != 2 || rows[1][subColEmail] != "b@example.com" {
+		t.Errorf("expected only the other subscriber to remain, got %v", rows)
+	}
+
+	if erased, err := service.EraseSubscriber(ctx, "a@example.com"); err != nil || erased {
+		t.Errorf("expected nothing to erase, got %v, %v", erased, err)
+	}
+}
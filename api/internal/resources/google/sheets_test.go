@@ -3,12 +3,21 @@ package google
 import (
 	"context"
 	"errors"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/resources/google/sheetstest"
+	"google.golang.org/api/googleapi"
 )
 
 // MockSheetsService provides a mock implementation for testing
 type MockSheetsService struct {
 	AppendFeedbackFunc func(ctx context.Context, feedback *FeedbackData) error
+	ListFeedbackFunc   func(ctx context.Context, query *FeedbackQuery) (*FeedbackPage, error)
+	GetFeedbackFunc    func(ctx context.Context, id string) (*FeedbackData, error)
+	UpdateTriageFunc   func(ctx context.Context, id string, update *TriageUpdate) (*FeedbackData, error)
 }
 
 func (m *MockSheetsService) AppendFeedback(ctx context.Context, feedback *FeedbackData) error {
@@ -18,6 +27,91 @@ func (m *MockSheetsService) AppendFeedback(ctx context.Context, feedback *Feedba
 	return nil
 }
 
+func (m *MockSheetsService) ListFeedback(ctx context.Context, query *FeedbackQuery) (*FeedbackPage, error) {
+	if m.ListFeedbackFunc != nil {
+		return m.ListFeedbackFunc(ctx, query)
+	}
+	return &FeedbackPage{}, nil
+}
+
+func (m *MockSheetsService) GetFeedback(ctx context.Context, id string) (*FeedbackData, error) {
+	if m.GetFeedbackFunc != nil {
+		return m.GetFeedbackFunc(ctx, id)
+	}
+	return nil, ErrFeedbackNotFound
+}
+
+func (m *MockSheetsService) FeedbackAnswers(ctx context.Context, to time.Time) ([]FeedbackAnswers, error) {
+	return nil, nil
+}
+
+func (m *MockSheetsService) UpdateTriage(ctx context.Context, id string, update *TriageUpdate) (*FeedbackData, error) {
+	if m.UpdateTriageFunc != nil {
+		return m.UpdateTriageFunc(ctx, id, update)
+	}
+	return nil, ErrFeedbackNotFound
+}
+
+func (m *MockSheetsService) FindFeedbackByEmail(ctx context.Context, email string) ([]*FeedbackData, error) {
+	return nil, nil
+}
+
+func (m *MockSheetsService) EraseFeedbackByEmail(ctx context.Context, email string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockSheetsService) RecordPrivacyRequest(ctx context.Context, record *PrivacyRecord) error {
+	return nil
+}
+
+func (m *MockSheetsService) RotateFieldKeys(ctx context.Context) (*KeyRotationResult, error) {
+	return &KeyRotationResult{}, nil
+}
+
+func (m *MockSheetsService) ApplyRetention(ctx context.Context, policy *RetentionPolicy, dryRun bool) (*RetentionReport, error) {
+	return &RetentionReport{DryRun: dryRun}, nil
+}
+
+func (m *MockSheetsService) RecordDelivery(ctx context.Context, record *DeliveryRecord) error {
+	return nil
+}
+
+func (m *MockSheetsService) FindSubscriber(ctx context.Context, email string) (*Subscriber, error) {
+	return nil, ErrSubscriberNotFound
+}
+
+func (m *MockSheetsService) SaveSubscriber(ctx context.Context, subscriber *Subscriber) error {
+	return nil
+}
+
+func (m *MockSheetsService) EraseSubscriber(ctx context.Context, email string) (bool, error) {
+	return false, nil
+}
+
+func (m *MockSheetsService) CompactCounts(ctx context.Context) (*CompactionResult, error) {
+	return &CompactionResult{}, nil
+}
+
+func (m *MockSheetsService) RecordDownload(ctx context.Context, event *DownloadEvent) error {
+	return nil
+}
+
+func (m *MockSheetsService) DownloadCounts(ctx context.Context, from, to time.Time) ([]DownloadCount, error) {
+	return nil, nil
+}
+
+func (m *MockSheetsService) AddEventCounts(ctx context.Context, counts []EventCount) error {
+	return nil
+}
+
+func (m *MockSheetsService) EventCounts(ctx context.Context, from, to time.Time) ([]EventCount, error) {
+	return nil, nil
+}
+
+func (m *MockSheetsService) Location() *time.Location {
+	return time.UTC
+}
+
 func TestSheetsConfig_Validation(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -188,3 +282,182 @@ func TestGoogleSheetsService_AppendFeedback_NilCheck(t *testing.T) {
 		t.Errorf("expected error %q, got %q", expectedError, err.Error())
 	}
 }
+
+func newFakeBackedService(t *testing.T, srv *sheetstest.Server) *GoogleSheetsService {
+	t.Helper()
+
+	service, err := NewGoogleSheetsService(context.Background(), &SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		SheetName:     "Feedback",
+		ClientOptions: srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return service
+}
+
+func TestGoogleSheetsService_WithFakeServer(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	service := newFakeBackedService(t, srv)
+
+	err := service.AppendFeedback(context.Background(), &FeedbackData{
+		Helpfulness:     "very-helpful",
+		SetupDifficulty: 2,
+		Source:          "landing-page",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := srv.Values("spreadsheet-id", "Feedback")
+	if len(rows) != 2 {
+		t.Fatalf("expected header and one data row, got %d rows", len(rows))
+	}
+	if rows[0][0] != "Timestamp" || rows[0][7] != "Source" || rows[0][8] != "Submission ID" {
+		t.Errorf("unexpected headers: %v", rows[0])
+	}
+	if rows[1][8] == "" {
+		t.Error("expected a generated submission ID")
+	}
+	if rows[1][1] != "very-helpful" || rows[1][2] != "2" || rows[1][7] != "landing-page" {
+		t.Errorf("unexpected data row: %v", rows[1])
+	}
+
+	// Headers must not be written twice when the service is recreated.
+	newFakeBackedService(t, srv)
+	if rows := srv.Values("spreadsheet-id", "Feedback"); len(rows) != 2 {
+		t.Errorf("expected existing headers to be kept, got %d rows", len(rows))
+	}
+}
+
+func TestGoogleSheetsService_FakeServerFaults(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	service := newFakeBackedService(t, srv)
+	ctx := context.Background()
+
+	srv.RateLimit(1)
+	err := service.AppendFeedback(ctx, &FeedbackData{Helpfulness: "helpful"})
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+
+	srv.Unauthenticated(1)
+	if _, err := NewGoogleSheetsService(ctx, &SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		ClientOptions: srv.ClientOptions(),
+	}); err == nil {
+		t.Error("expected header initialization to fail on auth error")
+	}
+
+	srv.SetLatency(200 * time.Millisecond)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := service.AppendFeedback(timeoutCtx, &FeedbackData{Helpfulness: "helpful"}); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestAppendFeedback_ClockAndTimezone(t *testing.T) {
+	srv := sheetstest.NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("spreadsheet-id", "Feedback")
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	serverTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service, err := NewGoogleSheetsService(context.Background(), &SheetsConfig{
+		SpreadsheetID: "spreadsheet-id",
+		SheetName:     "Feedback",
+		Clock:         fixedClock{now: serverTime},
+		Location:      berlin,
+		ClientOptions: srv.ClientOptions(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		clientTime time.Time
+		wantClient string
+		wantSkew   string
+	}{
+		{"no client time", time.Time{}, "", ""},
+		{"client ahead", serverTime.Add(90 * time.Second), "2025-03-01T13:01:30+01:00", "90"},
+		{"client behind beyond threshold", serverTime.Add(-2 * time.Hour), "2025-03-01T11:00:00+01:00", "-7200"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedback := &FeedbackData{Helpfulness: "helpful", ClientSubmittedAt: tt.clientTime}
+			if err := service.AppendFeedback(context.Background(), feedback); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			row := srv.Values("spreadsheet-id", "Feedback")[i+1]
+			if row[colTimestamp] != "2025-03-01T13:00:00+01:00" {
+				t.Errorf("expected server time in configured zone, got %q", row[colTimestamp])
+			}
+			if got := cellAt(row, colClientTimestamp); got != tt.wantClient {
+				t.Errorf("expected client timestamp %q, got %q", tt.wantClient, got)
+			}
+			if got := cellAt(row, colClockSkew); got != tt.wantSkew {
+				t.Errorf("expected clock skew %q, got %q", tt.wantSkew, got)
+			}
+
+			stored, err := service.GetFeedback(context.Background(), feedback.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !stored.SubmittedAt.Equal(serverTime) || stored.ClockSkew != feedback.ClockSkew {
+				t.Errorf("expected timestamps to round trip, got %v and %v", stored.SubmittedAt, stored.ClockSkew)
+			}
+		})
+	}
+}
+
+func cellAt(row []string, col int) string {
+	if col < len(row) {
+		return row[col]
+	}
+	return ""
+}
+
+func TestEmulatorOptions(t *testing.T) {
+	for _, endpoint := range []string{"http://127.0.0.1:8085/", "http://localhost:8085/", "http://[::1]:8085/"} {
+		if _, err := emulatorOptions(endpoint); err != nil {
+			t.Errorf("%s: unexpected error: %v", endpoint, err)
+		}
+	}
+	for _, endpoint := range []string{"", "https://sheets.example.com/", "http://10.0.0.5:8085/", "localhost:8085"} {
+		if _, err := emulatorOptions(endpoint); err == nil {
+			t.Errorf("%s: expected an error", endpoint)
+		}
+	}
+}
+
+func TestNewGoogleSheetsServiceFromEnv_EndpointNeedsEmulatorFlag(t *testing.T) {
+	t.Setenv("GOOGLE_SPREADSHEET_ID", "spreadsheet-id")
+	t.Setenv("GOOGLE_SHEETS_ENDPOINT", "http://127.0.0.1:8085/")
+
+	if _, err := NewGoogleSheetsServiceFromEnv(context.Background()); err == nil || !strings.Contains(err.Error(), "GOOGLE_SHEETS_EMULATOR") {
+		t.Fatalf("expected the endpoint to be rejected without the emulator flag, got %v", err)
+	}
+}
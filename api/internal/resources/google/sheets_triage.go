@@ -0,0 +1,82 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/logging"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Triage statuses maintainers can assign to a submission.
+const (
+	TriageStatusNew      = "new"
+	TriageStatusTriaged  = "triaged"
+	TriageStatusActioned = "actioned"
+	TriageStatusWontfix  = "wontfix"
+)
+
+// TriageStatuses lists every valid triage status.
+var TriageStatuses = []string{TriageStatusNew, TriageStatusTriaged, TriageStatusActioned, TriageStatusWontfix}
+
+// IsValidTriageStatus reports whether status is one of TriageStatuses.
+func IsValidTriageStatus(status string) bool {
+	for _, candidate := range TriageStatuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TriageUpdate changes the triage state of a submission. Empty fields are
+// left unchanged.
+type TriageUpdate struct {
+	Status string
+	// Note is appended to the existing internal notes with a timestamp.
+	Note string
+}
+
+// UpdateTriage sets the triage status of a submission and appends a note.
+func (g *GoogleSheetsService) UpdateTriage(ctx context.Context, id string, update *TriageUpdate) (*FeedbackData, error) {
+	if update == nil || (update.Status == "" && update.Note == "") {
+		return nil, fmt.Errorf("triage update must set a status or a note")
+	}
+	if update.Status != "" && !IsValidTriageStatus(update.Status) {
+		return nil, fmt.Errorf("invalid triage status: %q", update.Status)
+	}
+
+	feedback, err := g.GetFeedback(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Status != "" {
+		feedback.Status = update.Status
+	}
+	if note := strings.TrimSpace(update.Note); note != "" {
+		entry := fmt.Sprintf("%s: %s", g.clock.Now().In(g.location).Format(time.RFC3339), note)
+		if feedback.Notes == "" {
+			feedback.Notes = entry
+		} else {
+			feedback.Notes += "\n" + entry
+		}
+	}
+
+	notes, _ := limitCell(feedback.Notes, " … [truncated]")
+	range_ := fmt.Sprintf("%s!%s%d:%s%d", g.sheetName,
+		columnLetter(colTriageStatus), feedback.row, columnLetter(colInternalNotes), feedback.row)
+	updateCall := g.service.Spreadsheets.Values.Update(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{{feedback.Status, notes}},
+	})
+	updateCall.ValueInputOption("RAW")
+
+	if _, err := updateCall.Context(ctx).Do(); err != nil {
+		return nil, fmt.Errorf("failed to update triage for submission %s: %w", id, err)
+	}
+
+	logging.Infof("Updated triage for submission %s to status: %s", id, feedback.Status)
+	return feedback, nil
+}
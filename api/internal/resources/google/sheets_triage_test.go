@@ -0,0 +1,61 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_UpdateTriage(t *testing.T) {
+	srv, service := seedFeedback(t)
+	service.clock = fixedClock{now: time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)}
+	ctx := context.Background()
+
+	feedback, err := service.UpdateTriage(ctx, "id-2", &TriageUpdate{Status: TriageStatusTriaged, Note: "Reproduced on Windows"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feedback.Status != TriageStatusTriaged {
+		t.Errorf("expected status triaged, got %q", feedback.Status)
+	}
+
+	if _, err := service.UpdateTriage(ctx, "id-2", &TriageUpdate{Note: "Fixed in docs"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	row := srv.Values("spreadsheet-id", "Feedback")[2]
+	if row[colSubmissionID] != "id-2" {
+		t.Fatalf("expected the matching row to be updated, got %v", row)
+	}
+	if row[colTriageStatus] != TriageStatusTriaged {
+		t.Errorf("expected note-only update to keep status, got %q", row[colTriageStatus])
+	}
+	wantNotes := "2025-03-01T09:30:00Z: Reproduced on Windows\n2025-03-01T09:30:00Z: Fixed in docs"
+	if row[colInternalNotes] != wantNotes {
+		t.Errorf("expected notes %q, got %q", wantNotes, row[colInternalNotes])
+	}
+
+	page, err := service.ListFeedback(ctx, &FeedbackQuery{Status: TriageStatusNew})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Total != 3 {
+		t.Errorf("expected untriaged rows to default to new, got %d", page.Total)
+	}
+}
+
+func TestGoogleSheetsService_UpdateTriage_Validation(t *testing.T) {
+	_, service := seedFeedback(t)
+	ctx := context.Background()
+
+	if _, err := service.UpdateTriage(ctx, "id-1", &TriageUpdate{}); err == nil {
+		t.Error("expected error for empty update")
+	}
+	if _, err := service.UpdateTriage(ctx, "id-1", &TriageUpdate{Status: "done"}); err == nil {
+		t.Error("expected error for invalid status")
+	}
+	if _, err := service.UpdateTriage(ctx, "missing", &TriageUpdate{Status: TriageStatusWontfix}); !errors.Is(err, ErrFeedbackNotFound) {
+		t.Errorf("expected ErrFeedbackNotFound, got %v", err)
+	}
+}
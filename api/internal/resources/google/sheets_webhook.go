@@ -0,0 +1,75 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+var deliveryHeaders = []any{"Timestamp", "Submission ID", "Target", "Attempts", "Status Code", "Result", "Error"}
+
+// Webhook delivery results recorded in the delivery log.
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// DeliveryRecord is one entry in the webhook delivery log. Targets are
+// recorded by name only, since webhook URLs may embed credentials.
+type DeliveryRecord struct {
+	SubmissionID string
+	Target       string
+	Attempts     int
+	StatusCode   int
+	Success      bool
+	Error        string
+	CompletedAt  time.Time
+}
+
+// RecordDelivery appends an entry to the webhook delivery log tab.
+func (g *GoogleSheetsService) RecordDelivery(ctx context.Context, record *DeliveryRecord) error {
+	if record == nil {
+		return fmt.Errorf("delivery record cannot be nil")
+	}
+
+	if err := g.ensureTab(ctx, g.webhookSheet, deliveryHeaders); err != nil {
+		return err
+	}
+
+	completedAt := record.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = g.clock.Now()
+	}
+	result := DeliveryFailed
+	if record.Success {
+		result = DeliveryDelivered
+	}
+	statusCode := ""
+	if record.StatusCode != 0 {
+		statusCode = fmt.Sprint(record.StatusCode)
+	}
+
+	values := []any{
+		completedAt.In(g.location).Format(time.RFC3339),
+		record.SubmissionID,
+		record.Target,
+		record.Attempts,
+		statusCode,
+		result,
+		record.Error,
+	}
+
+	range_ := fmt.Sprintf("%s!A:%s", quoteSheetName(g.webhookSheet), columnLetter(len(deliveryHeaders)-1))
+	appendCall := g.service.Spreadsheets.Values.Append(g.spreadsheetID, range_, &sheets.ValueRange{
+		Values: [][]any{values},
+	})
+	appendCall.ValueInputOption("RAW")
+	appendCall.InsertDataOption("INSERT_ROWS")
+
+	if _, err := appendCall.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package google
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoogleSheetsService_RecordDelivery(t *testing.T) {
+	srv, service := seedFeedback(t)
+	ctx := context.Background()
+
+	records := []*DeliveryRecord{
+		{SubmissionID: "id-1", Target: "slack", Attempts: 1, StatusCode: 200, Success: true, CompletedAt: time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC)},
+		{SubmissionID: "id-2", Target: "discord", Attempts: 4, Error: "request failed: connection refused", CompletedAt: time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, record := range records {
+		if err := service.RecordDelivery(ctx, record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	rows := srv.Values("spreadsheet-id", "WebhookDeliveries")
+	want := []string{
+		"Timestamp,Submission ID,Target,Attempts,Status Code,Result,Error",
+		"2025-04-01T08:00:00Z,id-1,slack,1,200,delivered,",
+		"2025-04-01T09:00:00Z,id-2,discord,4,,failed,request failed: connection refused",
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if got := strings.Join(rows[i], ","); got != want[i] {
+			t.Errorf("row %d: expected %q, got %q", i, want[i], got)
+		}
+	}
+
+	if err := service.RecordDelivery(ctx, nil); err == nil {
+		t.Error("expected an error for a nil record")
+	}
+}
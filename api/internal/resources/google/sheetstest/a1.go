@@ -0,0 +1,166 @@
+package sheetstest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gridRange is a parsed A1 range. Rows and columns are zero based, end
+// bounds are exclusive and -1 means the range is unbounded in that direction.
+type gridRange struct {
+	sheet    string
+	startRow int
+	endRow   int
+	startCol int
+	endCol   int
+}
+
+// parseA1 parses ranges such as "Sheet!A1:H1", "'My Sheet'!A:H", "Sheet!A2:H"
+// or a bare sheet name. A range without a sheet name uses defaultSheet.
+func parseA1(a1, defaultSheet string) (gridRange, error) {
+	sheet := defaultSheet
+	cells := a1
+
+	if idx := strings.LastIndex(a1, "!"); idx >= 0 {
+		sheet = unquoteSheetName(a1[:idx])
+		cells = a1[idx+1:]
+	} else if !looksLikeCells(a1) {
+		sheet = unquoteSheetName(a1)
+		cells = ""
+	}
+
+	if sheet == "" {
+		return gridRange{}, fmt.Errorf("unable to parse range: %s", a1)
+	}
+
+	r := gridRange{sheet: sheet, endRow: -1, endCol: -1}
+	if cells == "" {
+		return r, nil
+	}
+
+	start, end, isSpan := strings.Cut(cells, ":")
+	startCol, startRow, err := parseCell(start)
+	if err != nil {
+		return gridRange{}, fmt.Errorf("unable to parse range: %s", a1)
+	}
+
+	if startCol >= 0 {
+		r.startCol = startCol
+	}
+	if startRow >= 0 {
+		r.startRow = startRow
+	}
+
+	if !isSpan {
+		if startCol >= 0 {
+			r.endCol = startCol + 1
+		}
+		if startRow >= 0 {
+			r.endRow = startRow + 1
+		}
+		return r, nil
+	}
+
+	endCol, endRow, err := parseCell(end)
+	if err != nil {
+		return gridRange{}, fmt.Errorf("unable to parse range: %s", a1)
+	}
+	if endCol >= 0 {
+		r.endCol = endCol + 1
+	}
+	if endRow >= 0 {
+		r.endRow = endRow + 1
+	}
+
+	return r, nil
+}
+
+// parseCell splits a cell reference such as "B12" into zero based column and
+// row indexes. A missing part is reported as -1.
+func parseCell(cell string) (col, row int, err error) {
+	if cell == "" {
+		return -1, -1, fmt.Errorf("empty cell reference")
+	}
+
+	i := 0
+	for i < len(cell) && isLetter(cell[i]) {
+		i++
+	}
+
+	col = -1
+	if i > 0 {
+		col = columnIndex(strings.ToUpper(cell[:i]))
+	}
+
+	row = -1
+	if i < len(cell) {
+		n, convErr := strconv.Atoi(cell[i:])
+		if convErr != nil || n < 1 {
+			return -1, -1, fmt.Errorf("invalid row in cell reference %q", cell)
+		}
+		row = n - 1
+	}
+
+	return col, row, nil
+}
+
+// columnIndex converts a column name such as "AB" into a zero based index.
+func columnIndex(name string) int {
+	idx := 0
+	for _, c := range name {
+		idx = idx*26 + int(c-'A'+1)
+	}
+	return idx - 1
+}
+
+// columnName converts a zero based column index into its A1 name.
+func columnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+// formatA1 renders a bounded range in A1 notation.
+func formatA1(sheet string, startRow, startCol, endRow, endCol int) string {
+	return fmt.Sprintf("%s!%s%d:%s%d", quoteSheetName(sheet),
+		columnName(startCol), startRow+1, columnName(endCol-1), endRow)
+}
+
+func looksLikeCells(s string) bool {
+	start, end, _ := strings.Cut(s, ":")
+	if _, _, err := parseCell(start); err != nil {
+		return false
+	}
+	if end != "" {
+		if _, _, err := parseCell(end); err != nil {
+			return false
+		}
+	}
+	// Plain words such as "Feedback" parse as a column reference, so only
+	// treat short letter runs or references with digits as cells.
+	return strings.ContainsAny(s, "0123456789") || len(start) <= 3 && strings.Contains(s, ":")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func unquoteSheetName(name string) string {
+	if len(name) >= 2 && name[0] == '\'' && name[len(name)-1] == '\'' {
+		return strings.ReplaceAll(name[1:len(name)-1], "''", "'")
+	}
+	return name
+}
+
+func quoteSheetName(name string) string {
+	for _, c := range name {
+		if !isLetter(byte(c)) && (c < '0' || c > '9') && c != '_' {
+			return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+		}
+	}
+	return name
+}
@@ -0,0 +1,694 @@
+// Package sheetstest provides an in-memory fake of the subset of the Google
+// Sheets v4 REST API used by the google resources package, so that the
+// storage layer and the HTTP handlers built on top of it can be exercised
+// without network access or credentials.
+package sheetstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Fault describes an injected failure. Faults are consumed in the order they
+// were added, one per matching request.
+type Fault struct {
+	// Method restricts the fault to requests with this HTTP method. Empty
+	// matches every method.
+	Method string
+	// PathContains restricts the fault to request paths containing this
+	// substring. Empty matches every path.
+	PathContains string
+	// Status is the HTTP status returned instead of handling the request.
+	Status int
+	// Message is returned in the Google style error body.
+	Message string
+	// Times is how many requests the fault applies to. Zero means once.
+	Times int
+}
+
+// Request records a call received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+type sheet struct {
+	id                 int64
+	name               string
+	rows               [][]string
+	conditionalFormats []*sheets.ConditionalFormatRule
+}
+
+type spreadsheet struct {
+	sheets []*sheet
+	nextID int64
+}
+
+// Server is an in-memory Google Sheets API server.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	spreadsheets map[string]*spreadsheet
+	faults       []*Fault
+	latency      time.Duration
+	requests     []Request
+}
+
+// NewServer starts a fake Sheets server. Callers must Close it when done.
+func NewServer() *Server {
+	s := &Server{spreadsheets: make(map[string]*spreadsheet)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// ClientOptions returns the options needed to point a sheets.Service at the
+// fake server without credentials.
+func (s *Server) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(s.URL + "/"),
+		option.WithoutAuthentication(),
+	}
+}
+
+// CreateSpreadsheet registers a spreadsheet with the given sheet tabs.
+func (s *Server) CreateSpreadsheet(spreadsheetID string, sheetNames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := &spreadsheet{}
+	for _, name := range sheetNames {
+		ss.addSheet(name)
+	}
+	s.spreadsheets[spreadsheetID] = ss
+}
+
+// Values returns a copy of every stored row in a sheet tab.
+func (s *Server) Values(spreadsheetID, sheetName string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		return nil
+	}
+	sh := ss.sheet(sheetName)
+	if sh == nil {
+		return nil
+	}
+
+	rows := make([][]string, len(sh.rows))
+	for i, row := range sh.rows {
+		rows[i] = append([]string(nil), row...)
+	}
+	return rows
+}
+
+// SetValues replaces the contents of a sheet tab, creating it if needed.
+func (s *Server) SetValues(spreadsheetID, sheetName string, rows [][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		ss = &spreadsheet{}
+		s.spreadsheets[spreadsheetID] = ss
+	}
+	sh := ss.sheet(sheetName)
+	if sh == nil {
+		sh = ss.addSheet(sheetName)
+	}
+
+	sh.rows = make([][]string, len(rows))
+	for i, row := range rows {
+		sh.rows[i] = append([]string(nil), row...)
+	}
+}
+
+// SheetNames returns the tab names of a spreadsheet in order.
+func (s *Server) SheetNames(spreadsheetID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(ss.sheets))
+	for _, sh := range ss.sheets {
+		names = append(names, sh.name)
+	}
+	return names
+}
+
+// InjectFault queues a failure for upcoming requests.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// RateLimit makes the next n requests fail with 429 Too Many Requests.
+func (s *Server) RateLimit(n int) {
+	s.InjectFault(Fault{Status: http.StatusTooManyRequests, Message: "Quota exceeded", Times: n})
+}
+
+// Unauthenticated makes the next n requests fail with 401 Unauthorized.
+func (s *Server) Unauthenticated(n int) {
+	s.InjectFault(Fault{Status: http.StatusUnauthorized, Message: "Request had invalid authentication credentials.", Times: n})
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Requests returns the calls received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   body,
+	})
+	latency := s.latency
+	fault := s.takeFault(r)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		writeError(w, fault.Status, fault.Message)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/")
+	if path == r.URL.Path {
+		writeError(w, http.StatusNotFound, "unknown endpoint: "+r.URL.Path)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spreadsheetID, rest, hasValues := strings.Cut(path, "/values/")
+	switch {
+	case !hasValues && r.Method == http.MethodPost && strings.HasSuffix(path, "/values:batchUpdate"):
+		s.handleBatchUpdateValues(w, strings.TrimSuffix(path, "/values:batchUpdate"), body)
+	case hasValues && r.Method == http.MethodGet:
+		s.handleGetValues(w, spreadsheetID, rest)
+	case hasValues && r.Method == http.MethodPut:
+		s.handleUpdateValues(w, spreadsheetID, rest, body)
+	case hasValues && r.Method == http.MethodPost && strings.HasSuffix(rest, ":append"):
+		s.handleAppendValues(w, spreadsheetID, strings.TrimSuffix(rest, ":append"), body)
+	case hasValues && r.Method == http.MethodPost && strings.HasSuffix(rest, ":clear"):
+		s.handleClearValues(w, spreadsheetID, strings.TrimSuffix(rest, ":clear"))
+	case !hasValues && r.Method == http.MethodPost && strings.HasSuffix(path, ":batchUpdate"):
+		s.handleBatchUpdate(w, strings.TrimSuffix(path, ":batchUpdate"), body)
+	case !hasValues && r.Method == http.MethodGet && !strings.Contains(path, "/"):
+		s.handleGetSpreadsheet(w, path)
+	default:
+		writeError(w, http.StatusNotFound, "unsupported endpoint: "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// takeFault returns the first queued fault matching r. The caller must hold mu.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.PathContains != "" && !strings.Contains(r.URL.Path, f.PathContains) {
+			continue
+		}
+
+		f.Times--
+		if f.Times <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) lookup(w http.ResponseWriter, spreadsheetID, a1 string) (*spreadsheet, *sheet, gridRange, bool) {
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Requested entity was not found.")
+		return nil, nil, gridRange{}, false
+	}
+
+	defaultSheet := ""
+	if len(ss.sheets) > 0 {
+		defaultSheet = ss.sheets[0].name
+	}
+
+	rng, err := parseA1(a1, defaultSheet)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, nil, gridRange{}, false
+	}
+
+	sh := ss.sheet(rng.sheet)
+	if sh == nil {
+		writeError(w, http.StatusBadRequest, "Unable to parse range: "+a1)
+		return nil, nil, gridRange{}, false
+	}
+
+	return ss, sh, rng, true
+}
+
+func (s *Server) handleGetValues(w http.ResponseWriter, spreadsheetID, a1 string) {
+	_, sh, rng, ok := s.lookup(w, spreadsheetID, a1)
+	if !ok {
+		return
+	}
+
+	var values [][]any
+	endRow := len(sh.rows)
+	if rng.endRow >= 0 && rng.endRow < endRow {
+		endRow = rng.endRow
+	}
+	for i := rng.startRow; i < endRow; i++ {
+		row := sh.rows[i]
+		endCol := len(row)
+		if rng.endCol >= 0 && rng.endCol < endCol {
+			endCol = rng.endCol
+		}
+
+		out := []any{}
+		for j := rng.startCol; j < endCol; j++ {
+			out = append(out, row[j])
+		}
+		values = append(values, trimRow(out))
+	}
+
+	// The real API omits trailing empty rows from the response.
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+
+	writeJSON(w, &sheets.ValueRange{
+		Range:          a1,
+		MajorDimension: "ROWS",
+		Values:         values,
+	})
+}
+
+func (s *Server) handleUpdateValues(w http.ResponseWriter, spreadsheetID, a1 string, body []byte) {
+	_, sh, rng, ok := s.lookup(w, spreadsheetID, a1)
+	if !ok {
+		return
+	}
+
+	var vr sheets.ValueRange
+	if err := json.Unmarshal(body, &vr); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid value range: "+err.Error())
+		return
+	}
+
+	updated := sh.write(rng.startRow, rng.startCol, vr.Values)
+	writeJSON(w, &updated)
+}
+
+func (s *Server) handleBatchUpdateValues(w http.ResponseWriter, spreadsheetID string, body []byte) {
+	var req sheets.BatchUpdateValuesRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid batch update: "+err.Error())
+		return
+	}
+
+	// Every range is resolved before anything is written, so that a bad
+	// range leaves the spreadsheet untouched as the real API does.
+	targets := make([]*sheet, len(req.Data))
+	ranges := make([]gridRange, len(req.Data))
+	for i, vr := range req.Data {
+		_, sh, rng, ok := s.lookup(w, spreadsheetID, vr.Range)
+		if !ok {
+			return
+		}
+		targets[i], ranges[i] = sh, rng
+	}
+
+	resp := &sheets.BatchUpdateValuesResponse{SpreadsheetId: spreadsheetID}
+	for i, vr := range req.Data {
+		updated := targets[i].write(ranges[i].startRow, ranges[i].startCol, vr.Values)
+		resp.Responses = append(resp.Responses, &updated)
+		resp.TotalUpdatedCells += updated.UpdatedCells
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleAppendValues(w http.ResponseWriter, spreadsheetID, a1 string, body []byte) {
+	_, sh, rng, ok := s.lookup(w, spreadsheetID, a1)
+	if !ok {
+		return
+	}
+
+	var vr sheets.ValueRange
+	if err := json.Unmarshal(body, &vr); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid value range: "+err.Error())
+		return
+	}
+
+	start := sh.lastUsedRow() + 1
+	if start < rng.startRow {
+		start = rng.startRow
+	}
+
+	updated := sh.write(start, rng.startCol, vr.Values)
+	writeJSON(w, &sheets.AppendValuesResponse{
+		SpreadsheetId: spreadsheetID,
+		TableRange:    a1,
+		Updates:       &updated,
+	})
+}
+
+func (s *Server) handleClearValues(w http.ResponseWriter, spreadsheetID, a1 string) {
+	_, sh, rng, ok := s.lookup(w, spreadsheetID, a1)
+	if !ok {
+		return
+	}
+
+	for i := rng.startRow; i < len(sh.rows) && (rng.endRow < 0 || i < rng.endRow); i++ {
+		for j := rng.startCol; j < len(sh.rows[i]) && (rng.endCol < 0 || j < rng.endCol); j++ {
+			sh.rows[i][j] = ""
+		}
+	}
+
+	writeJSON(w, &sheets.ClearValuesResponse{SpreadsheetId: spreadsheetID, ClearedRange: a1})
+}
+
+func (s *Server) handleGetSpreadsheet(w http.ResponseWriter, spreadsheetID string) {
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Requested entity was not found.")
+		return
+	}
+
+	resp := &sheets.Spreadsheet{SpreadsheetId: spreadsheetID}
+	for i, sh := range ss.sheets {
+		resp.Sheets = append(resp.Sheets, &sheets.Sheet{
+			Properties: &sheets.SheetProperties{
+				SheetId:         sh.id,
+				Title:           sh.name,
+				Index:           int64(i),
+				ForceSendFields: []string{"SheetId"},
+			},
+			ConditionalFormats: sh.conditionalFormats,
+		})
+	}
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleBatchUpdate(w http.ResponseWriter, spreadsheetID string, body []byte) {
+	ss, ok := s.spreadsheets[spreadsheetID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Requested entity was not found.")
+		return
+	}
+
+	var req sheets.BatchUpdateSpreadsheetRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid batch update: "+err.Error())
+		return
+	}
+
+	// Validate every request before applying any, as the real API applies
+	// batch updates atomically.
+	pending := map[string]bool{}
+	for _, r := range req.Requests {
+		if r.AddSheet == nil || r.AddSheet.Properties == nil {
+			continue
+		}
+		title := r.AddSheet.Properties.Title
+		if ss.sheet(title) != nil || pending[title] {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid requests[0].addSheet: A sheet with the name %q already exists. Please enter another name.", title))
+			return
+		}
+		pending[title] = true
+	}
+
+	resp := &sheets.BatchUpdateSpreadsheetResponse{SpreadsheetId: spreadsheetID}
+	for _, r := range req.Requests {
+		reply := &sheets.Response{}
+		if r.AddSheet != nil && r.AddSheet.Properties != nil {
+			sh := ss.addSheet(r.AddSheet.Properties.Title)
+			reply.AddSheet = &sheets.AddSheetResponse{
+				Properties: &sheets.SheetProperties{
+					SheetId:         sh.id,
+					Title:           sh.name,
+					Index:           int64(len(ss.sheets) - 1),
+					ForceSendFields: []string{"SheetId"},
+				},
+			}
+		}
+		if r.AddConditionalFormatRule != nil && r.AddConditionalFormatRule.Rule != nil {
+			if sh := ss.sheetByID(ruleSheetID(r.AddConditionalFormatRule.Rule)); sh != nil {
+				sh.conditionalFormats = append(sh.conditionalFormats, r.AddConditionalFormatRule.Rule)
+			}
+		}
+		if r.UpdateCells != nil && r.UpdateCells.Start != nil {
+			if sh := ss.sheetByID(r.UpdateCells.Start.SheetId); sh != nil {
+				sh.write(int(r.UpdateCells.Start.RowIndex), int(r.UpdateCells.Start.ColumnIndex), cellValues(r.UpdateCells.Rows))
+			}
+		}
+		if r.DeleteDimension != nil && r.DeleteDimension.Range != nil {
+			if sh := ss.sheetByID(r.DeleteDimension.Range.SheetId); sh != nil {
+				sh.deleteDimension(r.DeleteDimension.Range)
+			}
+		}
+		// Other request kinds, such as formatting, are accepted and recorded
+		// by Requests but have no effect on stored values.
+		resp.Replies = append(resp.Replies, reply)
+	}
+
+	writeJSON(w, resp)
+}
+
+// deleteDimension removes rows, shifting later rows up. Column deletion is
+// not used by the storage layer and is ignored.
+func (sh *sheet) deleteDimension(r *sheets.DimensionRange) {
+	if r.Dimension != "ROWS" {
+		return
+	}
+	start, end := int(r.StartIndex), int(r.EndIndex)
+	if start >= len(sh.rows) || end <= start {
+		return
+	}
+	end = min(end, len(sh.rows))
+	sh.rows = append(sh.rows[:start], sh.rows[end:]...)
+}
+
+func (ss *spreadsheet) sheet(name string) *sheet {
+	for _, sh := range ss.sheets {
+		if sh.name == name {
+			return sh
+		}
+	}
+	return nil
+}
+
+func (ss *spreadsheet) sheetByID(id int64) *sheet {
+	for _, sh := range ss.sheets {
+		if sh.id == id {
+			return sh
+		}
+	}
+	return nil
+}
+
+func ruleSheetID(rule *sheets.ConditionalFormatRule) int64 {
+	if len(rule.Ranges) == 0 {
+		return 0
+	}
+	return rule.Ranges[0].SheetId
+}
+
+func (ss *spreadsheet) addSheet(name string) *sheet {
+	sh := &sheet{id: ss.nextID, name: name}
+	ss.nextID++
+	ss.sheets = append(ss.sheets, sh)
+	return sh
+}
+
+// lastUsedRow returns the index of the last row holding any value, or -1.
+func (sh *sheet) lastUsedRow() int {
+	for i := len(sh.rows) - 1; i >= 0; i-- {
+		for _, cell := range sh.rows[i] {
+			if cell != "" {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// write stores values starting at the given cell, growing the grid as needed.
+func (sh *sheet) write(startRow, startCol int, values [][]any) sheets.UpdateValuesResponse {
+	maxCols := 0
+	cells := 0
+	for i, row := range values {
+		r := startRow + i
+		for len(sh.rows) <= r {
+			sh.rows = append(sh.rows, nil)
+		}
+		for j, v := range row {
+			c := startCol + j
+			for len(sh.rows[r]) <= c {
+				sh.rows[r] = append(sh.rows[r], "")
+			}
+			sh.rows[r][c] = formatValue(v)
+			cells++
+		}
+		if len(row) > maxCols {
+			maxCols = len(row)
+		}
+	}
+
+	resp := sheets.UpdateValuesResponse{
+		UpdatedRows:    int64(len(values)),
+		UpdatedColumns: int64(maxCols),
+		UpdatedCells:   int64(cells),
+	}
+	if len(values) > 0 && maxCols > 0 {
+		resp.UpdatedRange = formatA1(sh.name, startRow, startCol, startRow+len(values), startCol+maxCols)
+	}
+	return resp
+}
+
+// formatValue renders a JSON value the way the API returns it with the
+// default FORMATTED_VALUE render option.
+// cellValues converts the entered values of UpdateCells rows to the values
+// accepted by write.
+func cellValues(rows []*sheets.RowData) [][]any {
+	values := make([][]any, len(rows))
+	for i, row := range rows {
+		for _, cell := range row.Values {
+			var value any
+			if v := cell.UserEnteredValue; v != nil {
+				switch {
+				case v.StringValue != nil:
+					value = *v.StringValue
+				case v.NumberValue != nil:
+					value = *v.NumberValue
+				case v.BoolValue != nil:
+					value = *v.BoolValue
+				}
+			}
+			values[i] = append(values[i], value)
+		}
+	}
+	return values
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func trimRow(row []any) []any {
+	for len(row) > 0 && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return row
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	defer r.Body.Close()
+	return io.ReadAll(r.Body)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeError writes an error in the format used by Google APIs so that the
+// client library surfaces it as a *googleapi.Error.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    status,
+			"message": message,
+			"status":  statusName(status),
+		},
+	})
+}
+
+func statusName(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	default:
+		return "INTERNAL"
+	}
+}
@@ -0,0 +1,315 @@
+package sheetstest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+func newClient(t *testing.T, srv *Server) *sheets.Service {
+	t.Helper()
+
+	service, err := sheets.NewService(context.Background(), srv.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("failed to create sheets client: %v", err)
+	}
+	return service
+}
+
+func TestServer_UpdateGetAndAppend(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	_, err := client.Spreadsheets.Values.Update("sheet-id", "Feedback!A1:C1", &sheets.ValueRange{
+		Values: [][]any{{"Timestamp", "Helpfulness", "Setup Difficulty"}},
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	for _, row := range [][]any{{"t1", "helpful", 3}, {"t2", "not-helpful", 8}} {
+		resp, err := client.Spreadsheets.Values.Append("sheet-id", "Feedback!A:C", &sheets.ValueRange{
+			Values: [][]any{row},
+		}).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+		if resp.Updates.UpdatedRows != 1 {
+			t.Errorf("expected 1 updated row, got %d", resp.Updates.UpdatedRows)
+		}
+	}
+
+	got, err := client.Spreadsheets.Values.Get("sheet-id", "Feedback!A2:C").Context(ctx).Do()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+
+	if len(got.Values) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got.Values))
+	}
+	if got.Values[1][2] != "8" {
+		t.Errorf("expected numeric values to be returned formatted, got %v", got.Values[1][2])
+	}
+
+	rows := srv.Values("sheet-id", "Feedback")
+	if len(rows) != 3 || rows[0][0] != "Timestamp" {
+		t.Errorf("unexpected stored rows: %v", rows)
+	}
+}
+
+func TestServer_BatchUpdateValues(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetValues("sheet-id", "Feedback", [][]string{{"a", "b", "c"}, {"d", "e", "f"}})
+
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	_, err := client.Spreadsheets.Values.BatchUpdate("sheet-id", &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data: []*sheets.ValueRange{
+			{Range: "Feedback!B1", Values: [][]any{{"x"}}},
+			{Range: "Feedback!C2", Values: [][]any{{""}}},
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+
+	rows := srv.Values("sheet-id", "Feedback")
+	if rows[0][1] != "x" || rows[1][2] != "" || rows[1][0] != "d" {
+		t.Errorf("unexpected rows %v", rows)
+	}
+
+	_, err = client.Spreadsheets.Values.BatchUpdate("sheet-id", &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data: []*sheets.ValueRange{
+			{Range: "Feedback!A1", Values: [][]any{{"changed"}}},
+			{Range: "Missing!A1", Values: [][]any{{"y"}}},
+		},
+	}).Context(ctx).Do()
+	if err == nil {
+		t.Fatal("expected an error for an unknown sheet")
+	}
+	if rows := srv.Values("sheet-id", "Feedback"); rows[0][0] != "a" {
+		t.Errorf("expected a failed batch to write nothing, got %v", rows)
+	}
+}
+
+func TestServer_GetEmptyRange(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+
+	got, err := newClient(t, srv).Spreadsheets.Values.Get("sheet-id", "Feedback!A1:H1").Do()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if len(got.Values) != 0 {
+		t.Errorf("expected no values, got %v", got.Values)
+	}
+}
+
+func TestServer_AddSheet(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+
+	client := newClient(t, srv)
+	req := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: "Summary"}},
+		}},
+	}
+
+	resp, err := client.Spreadsheets.BatchUpdate("sheet-id", req).Do()
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+	if resp.Replies[0].AddSheet.Properties.Title != "Summary" {
+		t.Errorf("unexpected reply: %+v", resp.Replies[0].AddSheet.Properties)
+	}
+
+	names := srv.SheetNames("sheet-id")
+	if len(names) != 2 || names[1] != "Summary" {
+		t.Errorf("expected Summary tab to be added, got %v", names)
+	}
+
+	if _, err := client.Spreadsheets.BatchUpdate("sheet-id", req).Do(); err == nil {
+		t.Error("expected error when adding a duplicate sheet")
+	}
+}
+
+func TestServer_DeleteRows(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetValues("sheet-id", "Feedback", [][]string{{"header"}, {"a"}, {"b"}, {"c"}, {"d"}})
+
+	client := newClient(t, srv)
+	spreadsheet, err := client.Spreadsheets.Get("sheet-id").Do()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	sheetID := spreadsheet.Sheets[0].Properties.SheetId
+
+	deleteRows := func(start, end int64) *sheets.Request {
+		return &sheets.Request{DeleteDimension: &sheets.DeleteDimensionRequest{
+			Range: &sheets.DimensionRange{SheetId: sheetID, Dimension: "ROWS", StartIndex: start, EndIndex: end},
+		}}
+	}
+	_, err = client.Spreadsheets.BatchUpdate("sheet-id", &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{deleteRows(3, 5), deleteRows(1, 2)},
+	}).Do()
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+
+	rows := srv.Values("sheet-id", "Feedback")
+	if len(rows) != 2 || rows[0][0] != "header" || rows[1][0] != "b" {
+		t.Errorf("unexpected rows after deletion: %v", rows)
+	}
+}
+
+func TestServer_UpdateCells(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetValues("sheet-id", "Counts", [][]string{{"Day", "Count"}, {"2025-03-01", "1"}, {"2025-03-01", "2"}})
+
+	client := newClient(t, srv)
+	spreadsheet, err := client.Spreadsheets.Get("sheet-id").Do()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	sheetID := spreadsheet.Sheets[0].Properties.SheetId
+
+	day, count := "2025-03-02", 3.0
+	_, err = client.Spreadsheets.BatchUpdate("sheet-id", &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{UpdateCells: &sheets.UpdateCellsRequest{
+				Start: &sheets.GridCoordinate{SheetId: sheetID, RowIndex: 1},
+				Rows: []*sheets.RowData{{Values: []*sheets.CellData{
+					{UserEnteredValue: &sheets.ExtendedValue{StringValue: &day}},
+					{UserEnteredValue: &sheets.ExtendedValue{NumberValue: &count}},
+				}}},
+				Fields: "userEnteredValue",
+			}},
+			{DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{SheetId: sheetID, Dimension: "ROWS", StartIndex: 2, EndIndex: 3},
+			}},
+		},
+	}).Do()
+	if err != nil {
+		t.Fatalf("batch update failed: %v", err)
+	}
+
+	rows := srv.Values("sheet-id", "Counts")
+	if len(rows) != 2 || rows[1][0] != "2025-03-02" || rows[1][1] != "3" {
+		t.Errorf("unexpected rows after update: %v", rows)
+	}
+}
+
+func TestServer_UnknownSpreadsheetAndSheet(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+
+	client := newClient(t, srv)
+
+	_, err := client.Spreadsheets.Values.Get("missing", "Feedback!A1").Do()
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, err = client.Spreadsheets.Values.Get("sheet-id", "Other!A1").Do()
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestServer_FaultInjection(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+
+	client := newClient(t, srv)
+
+	srv.RateLimit(2)
+	for i := 0; i < 2; i++ {
+		_, err := client.Spreadsheets.Values.Get("sheet-id", "Feedback!A1").Do()
+		assertStatus(t, err, http.StatusTooManyRequests)
+	}
+	if _, err := client.Spreadsheets.Values.Get("sheet-id", "Feedback!A1").Do(); err != nil {
+		t.Errorf("expected request to succeed once faults are exhausted, got %v", err)
+	}
+
+	srv.Unauthenticated(1)
+	_, err := client.Spreadsheets.Values.Get("sheet-id", "Feedback!A1").Do()
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	srv.InjectFault(Fault{Method: http.MethodPost, Status: http.StatusServiceUnavailable})
+	if _, err := client.Spreadsheets.Values.Get("sheet-id", "Feedback!A1").Do(); err != nil {
+		t.Errorf("expected GET to skip a POST-only fault, got %v", err)
+	}
+	_, err = client.Spreadsheets.Values.Append("sheet-id", "Feedback!A:A", &sheets.ValueRange{
+		Values: [][]any{{"x"}},
+	}).ValueInputOption("RAW").Do()
+	assertStatus(t, err, http.StatusServiceUnavailable)
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.CreateSpreadsheet("sheet-id", "Feedback")
+	srv.SetLatency(200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := newClient(t, srv).Spreadsheets.Values.Get("sheet-id", "Feedback!A1").Context(ctx).Do()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestParseA1(t *testing.T) {
+	tests := []struct {
+		in   string
+		want gridRange
+	}{
+		{"Feedback!A1:H1", gridRange{sheet: "Feedback", startRow: 0, endRow: 1, startCol: 0, endCol: 8}},
+		{"Feedback!A:H", gridRange{sheet: "Feedback", startRow: 0, endRow: -1, startCol: 0, endCol: 8}},
+		{"Feedback!A2:H", gridRange{sheet: "Feedback", startRow: 1, endRow: -1, startCol: 0, endCol: 8}},
+		{"'My Sheet'!B3", gridRange{sheet: "My Sheet", startRow: 2, endRow: 3, startCol: 1, endCol: 2}},
+		{"Feedback", gridRange{sheet: "Feedback", endRow: -1, endCol: -1}},
+		{"A1:B2", gridRange{sheet: "Default", startRow: 0, endRow: 2, startCol: 0, endCol: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseA1(tt.in, "Default")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected googleapi error with status %d, got %v", status, err)
+	}
+	if apiErr.Code != status {
+		t.Errorf("expected status %d, got %d", status, apiErr.Code)
+	}
+}
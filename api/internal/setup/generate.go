@@ -0,0 +1,195 @@
+// Package setup helps people install Vega: it generates the config file and
+// Docker commands for their choices. Secrets passed in are only ever
+// written into the generated output; this package never stores or logs
+// them.
+package setup
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Image is the Vega container image.
+const Image = "ghcr.io/benidevo/vega-ai"
+
+// ContainerPort is the port Vega listens on inside its container, and
+// DataPath the directory holding its database.
+const (
+	ContainerPort = 8765
+	DataPath      = "/app/data"
+)
+
+// Placeholders written in place of secrets that were not supplied.
+const (
+	PlaceholderGeminiAPIKey  = "your-gemini-api-key"
+	PlaceholderAdminPassword = "your-secure-password"
+)
+
+// RestartPolicies lists the Docker restart policies accepted in Options.
+var RestartPolicies = []string{"no", "always", "unless-stopped", "on-failure"}
+
+// MinPasswordLength is the shortest admin password accepted.
+const MinPasswordLength = 8
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.@-]{3,64}$`)
+	tagPattern      = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+	timezonePattern = regexp.MustCompile(`^(UTC|[A-Z][A-Za-z_]+(/[A-Za-z0-9_+-]+){1,2})$`)
+)
+
+// Options are the choices made in the setup form. Zero values take the
+// defaults used in the Vega documentation.
+type Options struct {
+	// Port is the host port Vega is published on. Defaults to 8765.
+	Port int `json:"port"`
+	// VolumeName is the Docker volume holding Vega's data. Defaults to
+	// "vega-data".
+	VolumeName string `json:"volumeName"`
+	// ContainerName defaults to "vega-ai".
+	ContainerName string `json:"containerName"`
+	// ImageTag defaults to "latest".
+	ImageTag string `json:"imageTag"`
+	// RestartPolicy is one of RestartPolicies. Empty means Docker's default.
+	RestartPolicy string `json:"restartPolicy"`
+	// CreateAdminUser adds an admin account on first start. Defaults to
+	// true.
+	CreateAdminUser *bool  `json:"createAdminUser"`
+	AdminUsername   string `json:"adminUsername"`
+	// Timezone is an IANA time zone name passed to the container as TZ.
+	Timezone string `json:"timezone"`
+
+	// GeminiAPIKey and AdminPassword are secrets. When empty, placeholders
+	// are written for the user to replace.
+	GeminiAPIKey  string `json:"geminiApiKey"`
+	AdminPassword string `json:"adminPassword"`
+}
+
+// Result is the generated setup.
+type Result struct {
+	// Config is the env file passed to the container with --env-file.
+	Config    string `json:"config"`
+	DockerRun string `json:"dockerRun"`
+	Compose   string `json:"compose"`
+	// Placeholders lists the config keys that still need a real value.
+	Placeholders []string `json:"placeholders"`
+}
+
+// FieldError is an invalid option.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate applies the defaults and checks every option, returning a
+// *FieldError for the first invalid one. Messages never include secret
+// values.
+func (o *Options) Validate() error {
+	if o.Port == 0 {
+		o.Port = ContainerPort
+	}
+	if o.VolumeName == "" {
+		o.VolumeName = "vega-data"
+	}
+	if o.ContainerName == "" {
+		o.ContainerName = "vega-ai"
+	}
+	if o.ImageTag == "" {
+		o.ImageTag = "latest"
+	}
+	if o.CreateAdminUser == nil {
+		create := true
+		o.CreateAdminUser = &create
+	}
+	if *o.CreateAdminUser && o.AdminUsername == "" {
+		o.AdminUsername = "admin"
+	}
+
+	switch {
+	case o.Port < 1 || o.Port > 65535:
+		return &FieldError{"port", "must be a number from 1 to 65535"}
+	case !namePattern.MatchString(o.VolumeName):
+		return &FieldError{"volumeName", "may only contain letters, digits, '_', '.' and '-', and must start with a letter or digit"}
+	case !namePattern.MatchString(o.ContainerName):
+		return &FieldError{"containerName", "may only contain letters, digits, '_', '.' and '-', and must start with a letter or digit"}
+	case !tagPattern.MatchString(o.ImageTag):
+		return &FieldError{"imageTag", "is not a valid Docker image tag"}
+	case o.RestartPolicy != "" && !slices.Contains(RestartPolicies, o.RestartPolicy):
+		return &FieldError{"restartPolicy", "must be one of " + strings.Join(RestartPolicies, ", ")}
+	case o.Timezone != "" && !timezonePattern.MatchString(o.Timezone):
+		return &FieldError{"timezone", "must be a time zone name such as Europe/London"}
+	case *o.CreateAdminUser && !usernamePattern.MatchString(o.AdminUsername):
+		return &FieldError{"adminUsername", "must be 3 to 64 letters, digits or '_', '.', '@', '-'"}
+	case o.GeminiAPIKey != "" && !isEnvValue(o.GeminiAPIKey):
+		return &FieldError{"geminiApiKey", "must be a single line without spaces or quotes"}
+	case o.AdminPassword != "" && !isEnvValue(o.AdminPassword):
+		return &FieldError{"adminPassword", "must be a single line without spaces or quotes"}
+	case o.AdminPassword != "" && len([]rune(o.AdminPassword)) < MinPasswordLength:
+		return &FieldError{"adminPassword", fmt.Sprintf("must be at least %d characters", MinPasswordLength)}
+	}
+	return nil
+}
+
+// isEnvValue reports whether value can be written unquoted in an env file
+// read by docker --env-file, which takes everything after "=" literally.
+func isEnvValue(value string) bool {
+	return !strings.ContainsAny(value, " \t\r\n\"'`\\#")
+}
+
+// Generate validates the options and renders the config file, docker run
+// command and docker-compose file.
+func Generate(o Options) (*Result, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Placeholders: []string{}}
+
+	var config strings.Builder
+	writeEnv := func(key, value, placeholder string) {
+		if value == "" {
+			value = placeholder
+			result.Placeholders = append(result.Placeholders, key)
+		}
+		fmt.Fprintf(&config, "%s=%s\n", key, value)
+	}
+	writeEnv("GEMINI_API_KEY", o.GeminiAPIKey, PlaceholderGeminiAPIKey)
+	if *o.CreateAdminUser {
+		writeEnv("CREATE_ADMIN_USER", "true", "")
+		writeEnv("ADMIN_USERNAME", o.AdminUsername, "")
+		writeEnv("ADMIN_PASSWORD", o.AdminPassword, PlaceholderAdminPassword)
+	}
+	if o.Timezone != "" {
+		writeEnv("TZ", o.Timezone, "")
+	}
+	result.Config = config.String()
+
+	image := Image + ":" + o.ImageTag
+	ports := fmt.Sprintf("%d:%d", o.Port, ContainerPort)
+	volume := o.VolumeName + ":" + DataPath
+
+	run := []string{"docker run --pull always -d", "--name " + o.ContainerName}
+	if o.RestartPolicy != "" {
+		run = append(run, "--restart "+o.RestartPolicy)
+	}
+	run = append(run, "-p "+ports, "-v "+volume, "--env-file config", image)
+	result.DockerRun = strings.Join(run, " \\\n  ") + "\n"
+
+	// Every interpolated scalar is quoted, so that names such as "true" or
+	// "0123" stay strings in YAML.
+	var compose strings.Builder
+	fmt.Fprintf(&compose, "services:\n  vega-ai:\n    image: %q\n    container_name: %q\n    pull_policy: always\n", image, o.ContainerName)
+	if o.RestartPolicy != "" {
+		fmt.Fprintf(&compose, "    restart: %q\n", o.RestartPolicy)
+	}
+	fmt.Fprintf(&compose, "    ports:\n      - %q\n    volumes:\n      - %q\n    env_file:\n      - config\n\nvolumes:\n  %q:\n", ports, volume, o.VolumeName)
+	result.Compose = compose.String()
+
+	return result, nil
+}
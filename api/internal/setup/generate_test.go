@@ -0,0 +1,105 @@
+package setup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_Defaults(t *testing.T) {
+	result, err := Generate(Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantConfig := "GEMINI_API_KEY=your-gemini-api-key\nCREATE_ADMIN_USER=true\nADMIN_USERNAME=admin\nADMIN_PASSWORD=your-secure-password\n"
+	if result.Config != wantConfig {
+		t.Errorf("unexpected config:\n%s", result.Config)
+	}
+	wantRun := "docker run --pull always -d \\\n  --name vega-ai \\\n  -p 8765:8765 \\\n  -v vega-data:/app/data \\\n  --env-file config \\\n  ghcr.io/benidevo/vega-ai:latest\n"
+	if result.DockerRun != wantRun {
+		t.Errorf("unexpected docker run command:\n%s", result.DockerRun)
+	}
+	if strings.Join(result.Placeholders, ",") != "GEMINI_API_KEY,ADMIN_PASSWORD" {
+		t.Errorf("unexpected placeholders %v", result.Placeholders)
+	}
+	if !strings.Contains(result.Compose, `image: "ghcr.io/benidevo/vega-ai:latest"`) || !strings.Contains(result.Compose, "volumes:\n  \"vega-data\":\n") {
+		t.Errorf("unexpected compose file:\n%s", result.Compose)
+	}
+}
+
+func TestGenerate_Choices(t *testing.T) {
+	create := false
+	result, err := Generate(Options{
+		Port:            9000,
+		VolumeName:      "vega_prod",
+		ContainerName:   "vega",
+		ImageTag:        "v1.4.0",
+		RestartPolicy:   "unless-stopped",
+		CreateAdminUser: &create,
+		Timezone:        "America/New_York",
+		GeminiAPIKey:    "AIzaExampleKey",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Config != "GEMINI_API_KEY=AIzaExampleKey\nTZ=America/New_York\n" || len(result.Placeholders) != 0 {
+		t.Errorf("unexpected config %q, placeholders %v", result.Config, result.Placeholders)
+	}
+	for _, want := range []string{"--name vega \\", "--restart unless-stopped \\", "-p 9000:8765 \\", "-v vega_prod:/app/data \\", "vega-ai:v1.4.0"} {
+		if !strings.Contains(result.DockerRun, want) {
+			t.Errorf("expected %q in the docker run command:\n%s", want, result.DockerRun)
+		}
+	}
+	for _, want := range []string{`restart: "unless-stopped"`, `- "9000:8765"`, `- "vega_prod:/app/data"`, "container_name: \"vega\"\n"} {
+		if !strings.Contains(result.Compose, want) {
+			t.Errorf("expected %q in the compose file:\n%s", want, result.Compose)
+		}
+	}
+}
+
+func TestGenerate_QuotesYAMLScalars(t *testing.T) {
+	// Unquoted, these names would be read as a boolean and a number.
+	result, err := Generate(Options{ContainerName: "true", VolumeName: "2024"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"container_name: \"true\"\n", "- \"2024:/app/data\"\n", "volumes:\n  \"2024\":\n"} {
+		if !strings.Contains(result.Compose, want) {
+			t.Errorf("expected %q in the compose file:\n%s", want, result.Compose)
+		}
+	}
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		options Options
+		field   string
+	}{
+		"port":           {Options{Port: 70000}, "port"},
+		"volume":         {Options{VolumeName: "-bad"}, "volumeName"},
+		"container":      {Options{ContainerName: "a b"}, "containerName"},
+		"tag":            {Options{ImageTag: "latest; rm -rf /"}, "imageTag"},
+		"restart":        {Options{RestartPolicy: "sometimes"}, "restartPolicy"},
+		"timezone":       {Options{Timezone: "Mars/Olympus Mons"}, "timezone"},
+		"username":       {Options{AdminUsername: "a"}, "adminUsername"},
+		"key with quote": {Options{GeminiAPIKey: `AIza"x`}, "geminiApiKey"},
+		"multiline":      {Options{AdminPassword: "longenough\nEVIL=1"}, "adminPassword"},
+		"short password": {Options{AdminPassword: "short"}, "adminPassword"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Generate(tt.options)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field {
+				t.Fatalf("expected an error for %s, got %v", tt.field, err)
+			}
+			if tt.options.AdminPassword != "" && strings.Contains(err.Error(), tt.options.AdminPassword) {
+				t.Error("expected the error not to echo the secret")
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package setup
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/benidevo/vega-ai-landing-page/api/internal/redact"
+)
+
+// MaxConfigLines bounds the config files ValidateConfig accepts.
+const MaxConfigLines = 500
+
+// Diagnostic severities. Errors stop Vega from starting or configuring
+// itself as intended; warnings are likely mistakes.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic codes reported by ValidateConfig.
+const (
+	CodeByteOrderMark     = "byte-order-mark"
+	CodeCRLF              = "crlf"
+	CodeExport            = "export"
+	CodeMissingEquals     = "missing-equals"
+	CodeSpaceAroundEquals = "space-around-equals"
+	CodeInvalidKey        = "invalid-key"
+	CodeUnknownKey        = "unknown-key"
+	CodeDuplicateKey      = "duplicate-key"
+	CodeQuotedValue       = "quoted-value"
+	CodeWhitespace        = "whitespace"
+	CodeInlineComment     = "inline-comment"
+	CodeEmptyValue        = "empty-value"
+	CodePlaceholder       = "placeholder"
+	CodeInvalidValue      = "invalid-value"
+	CodeInvalidPort       = "invalid-port"
+	CodeMissingKey        = "missing-key"
+)
+
+var (
+	keyPattern       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	apiKeyPattern    = regexp.MustCompile(`^AIza[0-9A-Za-z_\-]{35}$`)
+	secretKeyPattern = regexp.MustCompile(`(?i)(KEY|SECRET|PASSWORD|TOKEN)`)
+)
+
+// Diagnostic is a problem found in a config file. Messages never include
+// values from the file.
+type Diagnostic struct {
+	// Line is the 1-based line number, or 0 for the file as a whole.
+	Line     int    `json:"line"`
+	Key      string `json:"key,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// Validation is the outcome of ValidateConfig.
+type Validation struct {
+	// Valid is true when there are no errors; warnings may remain.
+	Valid       bool         `json:"valid"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Config echoes the file with secret values and lines without '='
+	// masked, and Unix line endings, so diagnostics can be shown next to
+	// their lines.
+	Config string `json:"config"`
+}
+
+// configKey describes a setting Vega reads from its config file.
+type configKey struct {
+	secret bool
+	// check returns a diagnostic for an invalid value, without Line or
+	// Key, or nil.
+	check func(value string) *Diagnostic
+}
+
+// configKeys lists the settings Vega understands.
+var configKeys = map[string]configKey{
+	"GEMINI_API_KEY": {secret: true, check: func(value string) *Diagnostic {
+		if value == PlaceholderGeminiAPIKey {
+			return &Diagnostic{Severity: SeverityError, Code: CodePlaceholder, Message: "replace the placeholder with your Gemini API key from Google AI Studio"}
+		}
+		if !apiKeyPattern.MatchString(value) {
+			return &Diagnostic{Severity: SeverityWarning, Code: CodeInvalidValue, Message: "does not look like a Gemini API key, which starts with AIza and is 39 characters long"}
+		}
+		return nil
+	}},
+	"CREATE_ADMIN_USER":    {check: checkBool},
+	"RESET_ADMIN_PASSWORD": {check: checkBool},
+	"ADMIN_USERNAME": {check: func(value string) *Diagnostic {
+		if !usernamePattern.MatchString(value) {
+			return &Diagnostic{Severity: SeverityError, Code: CodeInvalidValue, Message: "must be 3 to 64 letters, digits or '_', '.', '@', '-'"}
+		}
+		return nil
+	}},
+	"ADMIN_PASSWORD": {secret: true, check: func(value string) *Diagnostic {
+		if value == PlaceholderAdminPassword {
+			return &Diagnostic{Severity: SeverityError, Code: CodePlaceholder, Message: "replace the placeholder with a password of your own"}
+		}
+		if len([]rune(value)) < MinPasswordLength {
+			return &Diagnostic{Severity: SeverityWarning, Code: CodeInvalidValue, Message: fmt.Sprintf("should be at least %d characters", MinPasswordLength)}
+		}
+		return nil
+	}},
+	"TZ": {check: func(value string) *Diagnostic {
+		if !timezonePattern.MatchString(value) {
+			return &Diagnostic{Severity: SeverityError, Code: CodeInvalidValue, Message: "must be a time zone name such as Europe/London"}
+		}
+		return nil
+	}},
+	"PORT": {check: checkPort},
+}
+
+func checkBool(value string) *Diagnostic {
+	if value != "true" && value != "false" {
+		return &Diagnostic{Severity: SeverityError, Code: CodeInvalidValue, Message: "must be true or false"}
+	}
+	return nil
+}
+
+func checkPort(value string) *Diagnostic {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		return &Diagnostic{Severity: SeverityError, Code: CodeInvalidPort, Message: "must be a port number from 1 to 65535"}
+	}
+	return nil
+}
+
+// isSecretKey reports whether values of key must be masked.
+func isSecretKey(key string) bool {
+	if known, ok := configKeys[key]; ok {
+		return known.secret
+	}
+	return secretKeyPattern.MatchString(key)
+}
+
+// secretMask replaces the value of a secret key in the echoed config, in
+// the form used by the redact package, e.g. [redacted:admin-password].
+func secretMask(key string) string {
+	return redact.Mask(strings.ReplaceAll(strings.ToLower(key), "_", "-"))
+}
+
+// strayRedactor masks API keys outside assignments, such as in comments.
+var strayRedactor = redact.New(redact.GeminiAPIKeyDetector)
+
+func maskStray(line string) string {
+	masked, _ := strayRedactor.Redact(line)
+	return masked
+}
+
+// ValidateConfig checks the contents of a Vega config file the way
+// docker --env-file reads it: one KEY=value per line, "#" comments and blank
+// lines ignored, and values taken literally up to the end of the line. It
+// reports format problems and common mistakes per line. The content is not
+// stored; secret values are masked in the echoed config.
+func ValidateConfig(content string) (*Validation, error) {
+	v := &Validation{Diagnostics: []Diagnostic{}}
+	report := func(line int, key, severity, code, message string) {
+		v.Diagnostics = append(v.Diagnostics, Diagnostic{Line: line, Key: key, Severity: severity, Code: code, Message: message})
+	}
+
+	if trimmed, ok := strings.CutPrefix(content, "\ufeff"); ok {
+		content = trimmed
+		report(1, "", SeverityError, CodeByteOrderMark, "the file starts with a byte order mark, which becomes part of the first key; save it as UTF-8 without BOM")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) > MaxConfigLines {
+		return nil, &FieldError{"config", fmt.Sprintf("must have at most %d lines", MaxConfigLines)}
+	}
+
+	echoed := make([]string, len(lines))
+	values := map[string]string{}
+	seen := map[string]int{}
+	crlf := false
+	for i, line := range lines {
+		number := i + 1
+		if trimmed, ok := strings.CutSuffix(line, "\r"); ok {
+			line = trimmed
+			if !crlf {
+				crlf = true
+				report(number, "", SeverityWarning, CodeCRLF, "the file has Windows line endings; the carriage return is kept at the end of every value")
+			}
+		}
+
+		stripped := strings.TrimSpace(line)
+		if stripped == "" || strings.HasPrefix(stripped, "#") {
+			echoed[i] = maskStray(line)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// A line without '=' may be a value pasted on its own, such as a
+			// password, so none of it is echoed.
+			echoed[i] = redact.Mask("bare-line")
+			report(number, "", SeverityWarning, CodeMissingEquals, "a line without '=' copies the variable from the environment running Docker; write KEY=value")
+			continue
+		}
+
+		key := strings.TrimSpace(name)
+		if after, ok := strings.CutPrefix(key, "export "); ok {
+			key = strings.TrimSpace(after)
+			report(number, key, SeverityError, CodeExport, "remove 'export'; env files only contain KEY=value")
+		} else if strings.TrimLeft(name, " \t") != key {
+			report(number, key, SeverityError, CodeSpaceAroundEquals, "remove the space before '='; Docker rejects keys containing whitespace")
+		} else if key != name {
+			report(number, key, SeverityWarning, CodeWhitespace, "Docker ignores the whitespace before the key; remove it")
+		}
+
+		if isSecretKey(key) && value != "" {
+			echoed[i] = name + "=" + secretMask(key)
+		} else {
+			echoed[i] = maskStray(line)
+		}
+
+		if !keyPattern.MatchString(key) {
+			report(number, "", SeverityError, CodeInvalidKey, "keys may only contain letters, digits and '_', and cannot start with a digit")
+			continue
+		}
+		if previous, ok := seen[key]; ok {
+			report(number, key, SeverityWarning, CodeDuplicateKey, fmt.Sprintf("also set on line %d; the last value wins", previous))
+		}
+		seen[key] = number
+
+		if strings.TrimSpace(value) != value {
+			if strings.HasPrefix(value, " ") || strings.HasPrefix(value, "\t") {
+				report(number, key, SeverityWarning, CodeSpaceAroundEquals, "the space after '=' becomes part of the value")
+			} else {
+				report(number, key, SeverityWarning, CodeWhitespace, "the trailing whitespace becomes part of the value")
+			}
+			value = strings.TrimSpace(value)
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			report(number, key, SeverityWarning, CodeQuotedValue, "Docker keeps quotes as part of the value; remove them")
+			value = value[1 : len(value)-1]
+		}
+		if strings.Contains(value, " #") {
+			report(number, key, SeverityWarning, CodeInlineComment, "comments must be on their own line; everything after '=' is part of the value")
+		}
+		values[key] = value
+
+		known, ok := configKeys[key]
+		_, upper := configKeys[strings.ToUpper(key)]
+		switch {
+		case !ok && upper:
+			report(number, key, SeverityError, CodeUnknownKey, fmt.Sprintf("keys are case-sensitive; did you mean %s?", strings.ToUpper(key)))
+		case !ok && strings.HasSuffix(key, "_PORT") && value != "":
+			if d := checkPort(value); d != nil {
+				report(number, key, d.Severity, d.Code, d.Message)
+			}
+		case !ok:
+			report(number, key, SeverityWarning, CodeUnknownKey, "Vega does not use this setting")
+		case value == "":
+			report(number, key, SeverityError, CodeEmptyValue, "has no value")
+		default:
+			if d := known.check(value); d != nil {
+				report(number, key, d.Severity, d.Code, d.Message)
+			}
+		}
+	}
+
+	required := []string{"GEMINI_API_KEY"}
+	if values["CREATE_ADMIN_USER"] == "true" {
+		required = append(required, "ADMIN_USERNAME", "ADMIN_PASSWORD")
+	}
+	for _, key := range required {
+		if _, ok := seen[key]; !ok {
+			report(0, key, SeverityError, CodeMissingKey, "is required")
+		}
+	}
+
+	sort.SliceStable(v.Diagnostics, func(i, j int) bool {
+		return v.Diagnostics[i].Line < v.Diagnostics[j].Line
+	})
+	for _, d := range v.Diagnostics {
+		if d.Severity == SeverityError {
+			v.Errors++
+		} else {
+			v.Warnings++
+		}
+	}
+	v.Valid = v.Errors == 0
+	v.Config = strings.Join(echoed, "\n")
+	if content != "" {
+		v.Config += "\n"
+	}
+	return v, nil
+}
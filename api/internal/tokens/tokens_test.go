@@ -0,0 +1,74 @@
+package tokens
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte(strings.Repeat("s", minSecretLength))
+
+func TestSigner_RoundTrip(t *testing.T) {
+	signer, err := NewSigner(testSecret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, issued, err := signer.Sign("privacy/export", "a@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := signer.Verify(token, "privacy/export")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.Subject != "a@example.com" || claims.ID != issued.ID || claims.ID == "" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestSigner_Rejects(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	signer, _ := NewSigner(testSecret)
+	signer.SetClock(func() time.Time { return now })
+
+	token, _, _ := signer.Sign("privacy/erase", "a@example.com", time.Hour)
+	other, _ := NewSigner([]byte(strings.Repeat("o", minSecretLength)))
+	foreign, _, _ := other.Sign("privacy/erase", "a@example.com", time.Hour)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	tampered := payload[:len(payload)-2] + "xx." + signature
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		want    error
+	}{
+		{"wrong purpose", token, "privacy/export", ErrInvalidToken},
+		{"other secret", foreign, "privacy/erase", ErrInvalidToken},
+		{"tampered", tampered, "privacy/erase", ErrInvalidToken},
+		{"garbage", "not-a-token", "privacy/erase", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Verify(tt.token, tt.purpose); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := signer.Verify(token, "privacy/erase"); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestNewSigner_ShortSecret(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); err == nil {
+		t.Error("expected error for short secret")
+	}
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Payload formats accepted in Target.Format.
+const (
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+// maxTextLength caps free text in chat messages, well below the Slack and
+// Discord limits.
+const maxTextLength = 1000
+
+// Formatter renders an event as a request body.
+type Formatter interface {
+	Format(e *Event) ([]byte, error)
+}
+
+// FormatterFunc adapts a function to Formatter.
+type FormatterFunc func(e *Event) ([]byte, error)
+
+// Format implements Formatter.
+func (f FormatterFunc) Format(e *Event) ([]byte, error) {
+	return f(e)
+}
+
+var formatters = map[string]Formatter{
+	"":            FormatterFunc(formatJSON),
+	FormatJSON:    FormatterFunc(formatJSON),
+	FormatSlack:   FormatterFunc(formatSlack),
+	FormatDiscord: FormatterFunc(formatDiscord),
+}
+
+// RegisterFormatter adds a payload format that targets can select by name.
+// It must be called before dispatchers are created.
+func RegisterFormatter(name string, formatter Formatter) {
+	formatters[name] = formatter
+}
+
+func formatJSON(e *Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func formatSlack(e *Event) ([]byte, error) {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+	lines := []string{fmt.Sprintf("*New Vega AI feedback* from %s: *%s*", escape(e.Source), escape(summary(e)))}
+	if len(e.SetupIssues) > 0 {
+		lines = append(lines, "Setup issues: "+escape(strings.Join(e.SetupIssues, ", ")))
+	}
+	if text := truncate(e.AdditionalFeedback); text != "" {
+		lines = append(lines, "> "+strings.ReplaceAll(escape(text), "\n", "\n> "))
+	}
+	lines = append(lines, "Submission "+e.ID)
+
+	return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Fields      []discordField `json:"fields"`
+	Timestamp   string         `json:"timestamp"`
+	Footer      map[string]any `json:"footer"`
+}
+
+func formatDiscord(e *Event) ([]byte, error) {
+	embed := discordEmbed{
+		Title:       "New Vega AI feedback: " + summary(e),
+		Description: truncate(e.AdditionalFeedback),
+		Fields: []discordField{
+			{Name: "Source", Value: orNone(e.Source), Inline: true},
+			{Name: "Docs quality", Value: orNone(e.DocsQuality), Inline: true},
+			{Name: "Setup issues", Value: orNone(strings.Join(e.SetupIssues, ", "))},
+		},
+		Timestamp: e.SubmittedAt.UTC().Format(time.RFC3339),
+		Footer:    map[string]any{"text": "Submission " + e.ID},
+	}
+	return json.Marshal(map[string]any{
+		// Mentions in feedback text must not ping anyone.
+		"allowed_mentions": map[string]any{"parse": []string{}},
+		"embeds":           []discordEmbed{embed},
+	})
+}
+
+func summary(e *Event) string {
+	return fmt.Sprintf("%s, setup difficulty %d/10", orNone(e.Helpfulness), e.SetupDifficulty)
+}
+
+func truncate(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxTextLength {
+		return string(runes)
+	}
+	return string(runes[:maxTextLength]) + "…"
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
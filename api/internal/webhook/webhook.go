@@ -0,0 +1,371 @@
+// Package webhook notifies external services about new feedback. A
+// Dispatcher posts each event to every configured target whose filter
+// matches, formatted for that target, signed with HMAC-SHA256 and retried
+// with exponential backoff. Every delivery outcome is passed to a
+// DeliveryLog.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers set on every delivery. The signature covers the timestamp and
+// the body joined by a dot, as "sha256=<hex>".
+const (
+	HeaderSignature = "X-Vega-Signature"
+	HeaderTimestamp = "X-Vega-Timestamp"
+	HeaderEvent     = "X-Vega-Event"
+)
+
+// EventFeedbackCreated is sent for each stored submission.
+const EventFeedbackCreated = "feedback.created"
+
+// Defaults applied by NewDispatcher.
+const (
+	DefaultMaxAttempts = 4
+	DefaultBackoff     = 500 * time.Millisecond
+	DefaultTimeout     = 5 * time.Second
+)
+
+// Event is a stored submission as sent to webhook targets. Emails are never
+// included.
+type Event struct {
+	Type               string    `json:"event"`
+	ID                 string    `json:"id"`
+	SubmittedAt        time.Time `json:"submittedAt"`
+	Helpfulness        string    `json:"helpfulness"`
+	SetupDifficulty    int       `json:"setupDifficulty"`
+	DocsQuality        string    `json:"docsQuality"`
+	SetupIssues        []string  `json:"setupIssues"`
+	AdditionalFeedback string    `json:"additionalFeedback"`
+	Source             string    `json:"source"`
+}
+
+// Filter selects the events sent to a target. Empty fields match every
+// event.
+type Filter struct {
+	Helpfulness []string `json:"helpfulness,omitempty"`
+	// HasSetupIssues only matches events reporting at least one issue.
+	HasSetupIssues bool `json:"hasSetupIssues,omitempty"`
+	// SetupIssues matches events reporting any of the listed issues.
+	SetupIssues        []string `json:"setupIssues,omitempty"`
+	MinSetupDifficulty int      `json:"minSetupDifficulty,omitempty"`
+	Sources            []string `json:"sources,omitempty"`
+}
+
+// Matches reports whether the event passes the filter.
+func (f *Filter) Matches(e *Event) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Helpfulness) > 0 && !slices.Contains(f.Helpfulness, e.Helpfulness) {
+		return false
+	}
+	if f.HasSetupIssues && len(e.SetupIssues) == 0 {
+		return false
+	}
+	if len(f.SetupIssues) > 0 && !slices.ContainsFunc(e.SetupIssues, func(issue string) bool {
+		return slices.Contains(f.SetupIssues, issue)
+	}) {
+		return false
+	}
+	if f.MinSetupDifficulty > 0 && e.SetupDifficulty < f.MinSetupDifficulty {
+		return false
+	}
+	if len(f.Sources) > 0 && !slices.Contains(f.Sources, e.Source) {
+		return false
+	}
+	return true
+}
+
+// Target is a webhook endpoint.
+type Target struct {
+	// Name identifies the target in logs and the delivery log, which never
+	// record the URL since chat webhook URLs embed credentials.
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Format is FormatJSON (the default), FormatSlack or FormatDiscord.
+	Format string `json:"format,omitempty"`
+	// Secret signs deliveries. SecretEnv names an environment variable
+	// holding it instead, keeping secrets out of the target file.
+	Secret    string  `json:"secret,omitempty"`
+	SecretEnv string  `json:"secretEnv,omitempty"`
+	Filter    *Filter `json:"filter,omitempty"`
+
+	formatter Formatter
+}
+
+// Delivery is the outcome of sending one event to one target.
+type Delivery struct {
+	EventID    string
+	Target     string
+	Attempts   int
+	StatusCode int
+	Success    bool
+	// Error describes the last failure, without the target URL.
+	Error       string
+	CompletedAt time.Time
+}
+
+// DeliveryLog records delivery outcomes.
+type DeliveryLog interface {
+	RecordDelivery(ctx context.Context, delivery *Delivery) error
+}
+
+// Config configures a Dispatcher.
+type Config struct {
+	Targets []*Target
+	// Client sends deliveries. Defaults to a client with DefaultTimeout.
+	Client *http.Client
+	// Log receives every delivery outcome. It is optional.
+	Log DeliveryLog
+	// MaxAttempts bounds attempts per delivery, including the first.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles per retry.
+	Backoff time.Duration
+}
+
+// Dispatcher sends events to webhook targets.
+type Dispatcher struct {
+	targets     []*Target
+	client      *http.Client
+	log         DeliveryLog
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatcher validates the targets and returns a Dispatcher.
+func NewDispatcher(config *Config) (*Dispatcher, error) {
+	seen := map[string]bool{}
+	for _, target := range config.Targets {
+		if target.Name == "" {
+			return nil, fmt.Errorf("webhook target name is required")
+		}
+		if seen[target.Name] {
+			return nil, fmt.Errorf("duplicate webhook target %q", target.Name)
+		}
+		seen[target.Name] = true
+
+		parsed, err := url.Parse(target.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook target %q has an invalid URL", target.Name)
+		}
+
+		formatter, ok := formatters[target.Format]
+		if !ok {
+			return nil, fmt.Errorf("webhook target %q has unknown format %q", target.Name, target.Format)
+		}
+		target.formatter = formatter
+
+		if target.SecretEnv != "" {
+			target.Secret = os.Getenv(target.SecretEnv)
+			if target.Secret == "" {
+				return nil, fmt.Errorf("webhook target %q secret %s is not set", target.Name, target.SecretEnv)
+			}
+		}
+	}
+
+	d := &Dispatcher{
+		targets:     config.Targets,
+		client:      config.Client,
+		log:         config.Log,
+		maxAttempts: config.MaxAttempts,
+		backoff:     config.Backoff,
+		now:         time.Now,
+		sleep:       sleepContext,
+	}
+	if d.client == nil {
+		d.client = &http.Client{Timeout: DefaultTimeout}
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = DefaultMaxAttempts
+	}
+	if d.backoff <= 0 {
+		d.backoff = DefaultBackoff
+	}
+	return d, nil
+}
+
+// NewDispatcherFromEnv loads targets from the JSON array in WEBHOOK_TARGETS
+// or the file named by WEBHOOK_TARGETS_FILE. It returns nil without error
+// when neither is set. WEBHOOK_MAX_ATTEMPTS overrides the retry limit.
+func NewDispatcherFromEnv(log DeliveryLog) (*Dispatcher, error) {
+	data := []byte(os.Getenv("WEBHOOK_TARGETS"))
+	if path := os.Getenv("WEBHOOK_TARGETS_FILE"); len(data) == 0 && path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read webhook targets: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var targets []*Target
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("invalid webhook targets: %w", err)
+	}
+
+	config := &Config{Targets: targets, Log: log}
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts <= 0 {
+			return nil, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be a positive number, got %q", value)
+		}
+		config.MaxAttempts = attempts
+	}
+	return NewDispatcher(config)
+}
+
+// Dispatch sends the event to every matching target concurrently and waits
+// for all deliveries to finish or give up.
+func (d *Dispatcher) Dispatch(ctx context.Context, event *Event) []*Delivery {
+	if event.Type == "" {
+		event.Type = EventFeedbackCreated
+	}
+
+	var matching []*Target
+	for _, target := range d.targets {
+		if target.Filter.Matches(event) {
+			matching = append(matching, target)
+		}
+	}
+
+	deliveries := make([]*Delivery, len(matching))
+	var wg sync.WaitGroup
+	for i, target := range matching {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliveries[i] = d.deliver(ctx, target, event)
+		}()
+	}
+	wg.Wait()
+
+	if d.log != nil {
+		for _, delivery := range deliveries {
+			// The log is best effort: a failure to record a delivery must
+			// not look like a failed delivery.
+			_ = d.log.RecordDelivery(ctx, delivery)
+		}
+	}
+	return deliveries
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, target *Target, event *Event) *Delivery {
+	delivery := &Delivery{EventID: event.ID, Target: target.Name}
+
+	body, err := target.formatter.Format(event)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to format event: %v", err)
+		delivery.CompletedAt = d.now()
+		return delivery
+	}
+
+	wait := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		status, retry, err := d.send(ctx, target, event, body)
+		delivery.StatusCode = status
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+		if !retry || attempt == d.maxAttempts {
+			break
+		}
+
+		if err := d.sleep(ctx, wait); err != nil {
+			delivery.Error = err.Error()
+			break
+		}
+		wait *= 2
+	}
+
+	delivery.CompletedAt = d.now()
+	return delivery
+}
+
+// send makes one delivery attempt. It reports whether a failure is worth
+// retrying: network errors, rate limiting and server errors are.
+func (d *Dispatcher) send(ctx context.Context, target *Target, event *Event, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to build request")
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vega-ai-feedback-webhook")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if target.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(target.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// url.Error includes the URL, which may embed credentials.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.StatusCode, true, fmt.Errorf("target responded with status %d", resp.StatusCode)
+	default:
+		return resp.StatusCode, false, fmt.Errorf("target responded with status %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the signature header value for a delivery.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value, for receivers written in Go.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a local HTTP endpoint that records deliveries and answers
+// with scripted status codes.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.requests = append(r.requests, &receivedRequest{header: req.Header, body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []*receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*receivedRequest(nil), r.requests...)
+}
+
+type memoryLog struct {
+	mu         sync.Mutex
+	deliveries []*Delivery
+}
+
+func (m *memoryLog) RecordDelivery(ctx context.Context, delivery *Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, log DeliveryLog, targets ...*Target) (*Dispatcher, *[]time.Duration) {
+	t.Helper()
+
+	d, err := NewDispatcher(&Config{Targets: targets, Log: log, Backoff: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var waits []time.Duration
+	var mu sync.Mutex
+	d.sleep = func(ctx context.Context, wait time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, wait)
+		return nil
+	}
+	d.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return d, &waits
+}
+
+func testEvent() *Event {
+	return &Event{
+		ID:                 "abc123",
+		SubmittedAt:        time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Helpfulness:        "not-helpful",
+		SetupDifficulty:    8,
+		DocsQuality:        "no-insufficient",
+		SetupIssues:        []string{"docker-installation"},
+		AdditionalFeedback: "Container <exits> & restarts",
+		Source:             "landing-page",
+	}
+}
+
+func TestDispatcher_SignsGenericJSON(t *testing.T) {
+	recv := newReceiver(t)
+	log := &memoryLog{}
+	d, _ := newTestDispatcher(t, log, &Target{Name: "generic", URL: recv.URL, Secret: "s3cret"})
+
+	deliveries := d.Dispatch(context.Background(), testEvent())
+
+	if len(deliveries) != 1 || !deliveries[0].Success || deliveries[0].Attempts != 1 {
+		t.Fatalf("unexpected deliveries %+v", deliveries)
+	}
+	got := recv.received()
+	if len(got) != 1 {
+		t.Fatalf("expected one request, got %d", len(got))
+	}
+
+	req := got[0]
+	if req.header.Get(HeaderTimestamp) != "1700000000" || req.header.Get(HeaderEvent) != EventFeedbackCreated {
+		t.Errorf("unexpected headers %v", req.header)
+	}
+	if !Verify("s3cret", "1700000000", req.body, req.header.Get(HeaderSignature)) {
+		t.Error("expected a valid signature")
+	}
+	if Verify("other", "1700000000", req.body, req.header.Get(HeaderSignature)) {
+		t.Error("expected the signature to depend on the secret")
+	}
+
+	var event Event
+	if err := json.Unmarshal(req.body, &event); err != nil || event.ID != "abc123" || event.Type != EventFeedbackCreated {
+		t.Errorf("unexpected payload %s", req.body)
+	}
+
+	if len(log.deliveries) != 1 || log.deliveries[0].Target != "generic" {
+		t.Errorf("expected the delivery to be logged, got %+v", log.deliveries)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantSuccess  bool
+		wantAttempts int
+		wantWaits    int
+	}{
+		{"recovers after server errors", []int{500, 503, 200}, true, 3, 2},
+		{"retries rate limiting", []int{429, 200}, true, 2, 1},
+		{"gives up after max attempts", []int{500, 500, 500, 500}, false, 4, 3},
+		{"does not retry client errors", []int{400}, false, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recv := newReceiver(t, tt.statuses...)
+			d, waits := newTestDispatcher(t, nil, &Target{Name: "t", URL: recv.URL})
+
+			delivery := d.Dispatch(context.Background(), testEvent())[0]
+
+			if delivery.Success != tt.wantSuccess || delivery.Attempts != tt.wantAttempts {
+				t.Errorf("unexpected delivery %+v", delivery)
+			}
+			if len(*waits) != tt.wantWaits {
+				t.Fatalf("expected %d waits, got %v", tt.wantWaits, *waits)
+			}
+			for i, wait := range *waits {
+				if want := 100 * time.Millisecond << i; wait != want {
+					t.Errorf("wait %d: expected %s, got %s", i, want, wait)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_ErrorsOmitURL(t *testing.T) {
+	d, _ := newTestDispatcher(t, nil, &Target{Name: "slack", URL: "http://127.0.0.1:1/services/T000/B000/token"})
+
+	delivery := d.Dispatch(context.Background(), testEvent())[0]
+
+	if delivery.Success || delivery.Error == "" || strings.Contains(delivery.Error, "token") {
+		t.Errorf("expected a failure without the URL, got %+v", delivery)
+	}
+}
+
+func TestDispatcher_Filters(t *testing.T) {
+	recv := newReceiver(t)
+	d, _ := newTestDispatcher(t, nil,
+		&Target{Name: "unhappy", URL: recv.URL, Filter: &Filter{Helpfulness: []string{"not-helpful"}}},
+		&Target{Name: "issues", URL: recv.URL, Filter: &Filter{HasSetupIssues: true}},
+		&Target{Name: "ports", URL: recv.URL, Filter: &Filter{SetupIssues: []string{"port-conflicts"}}},
+		&Target{Name: "extension", URL: recv.URL, Filter: &Filter{Sources: []string{"extension"}}},
+		&Target{Name: "hard", URL: recv.URL, Filter: &Filter{MinSetupDifficulty: 9}},
+	)
+
+	deliveries := d.Dispatch(context.Background(), testEvent())
+
+	var names []string
+	for _, delivery := range deliveries {
+		names = append(names, delivery.Target)
+	}
+	if strings.Join(names, ",") != "unhappy,issues" {
+		t.Errorf("expected only matching targets, got %v", names)
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	event := testEvent()
+	event.Type = EventFeedbackCreated
+
+	slack, err := formatSlack(event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var slackPayload map[string]string
+	_ = json.Unmarshal(slack, &slackPayload)
+	text := slackPayload["text"]
+	if !strings.Contains(text, "not-helpful, setup difficulty 8/10") || !strings.Contains(text, "&lt;exits&gt; &amp; restarts") {
+		t.Errorf("unexpected Slack text %q", text)
+	}
+
+	discord, err := formatDiscord(event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var discordPayload struct {
+		AllowedMentions struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+		Embeds []discordEmbed `json:"embeds"`
+	}
+	if err := json.Unmarshal(discord, &discordPayload); err != nil || len(discordPayload.Embeds) != 1 {
+		t.Fatalf("unexpected Discord payload %s", discord)
+	}
+	embed := discordPayload.Embeds[0]
+	if embed.Description != "Container <exits> & restarts" || embed.Timestamp != "2025-03-01T12:00:00Z" || embed.Fields[2].Value != "docker-installation" {
+		t.Errorf("unexpected embed %+v", embed)
+	}
+
+	event.AdditionalFeedback = strings.Repeat("é", maxTextLength+5)
+	if got := []rune(truncate(event.AdditionalFeedback)); len(got) != maxTextLength+1 {
+		t.Errorf("expected text to be truncated, got %d runes", len(got))
+	}
+}
+
+func TestNewDispatcher_Invalid(t *testing.T) {
+	t.Setenv("MISSING_SECRET", "")
+
+	tests := map[string][]*Target{
+		"missing name":   {{URL: "https://example.com"}},
+		"duplicate name": {{Name: "a", URL: "https://example.com"}, {Name: "a", URL: "https://example.org"}},
+		"invalid URL":    {{Name: "a", URL: "ftp://example.com"}},
+		"unknown format": {{Name: "a", URL: "https://example.com", Format: "teams"}},
+		"missing secret": {{Name: "a", URL: "https://example.com", SecretEnv: "MISSING_SECRET"}},
+	}
+
+	for name, targets := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewDispatcher(&Config{Targets: targets}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewDispatcherFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_TARGETS", "")
+	t.Setenv("WEBHOOK_TARGETS_FILE", "")
+	if d, err := NewDispatcherFromEnv(nil); d != nil || err != nil {
+		t.Fatalf("expected no dispatcher without targets, got %v, %v", d, err)
+	}
+
+	t.Setenv("SLACK_SECRET", "from-env")
+	t.Setenv("WEBHOOK_TARGETS", `[{"name":"slack","url":"https://hooks.example.com/x","format":"slack","secretEnv":"SLACK_SECRET","filter":{"helpfulness":["not-helpful"]}}]`)
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "2")
+
+	d, err := NewDispatcherFromEnv(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(d.targets) != 1 || d.targets[0].Secret != "from-env" || d.maxAttempts != 2 {
+		t.Errorf("unexpected dispatcher %+v", d)
+	}
+}
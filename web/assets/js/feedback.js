@@ -13,6 +13,10 @@ function toggleFeedbackForm() {
   
   container.classList.toggle('hidden');
   icon.classList.toggle('rotate-180');
+
+  if (!container.classList.contains('hidden') && typeof trackEvent === 'function') {
+    trackEvent('expand-feedback');
+  }
   
   if (!container.classList.contains('hidden') && slider) {
     slider.style.setProperty('--value', ((slider.value - 1) / 9 * 100) + '%');
@@ -31,10 +35,17 @@ async function submitFeedback(event) {
   const formData = new FormData(form);
   const messageDiv = document.getElementById('form-message');
   
+  if (formData.get('email') && !formData.get('consentContact')) {
+    messageDiv.innerHTML = '<div class="bg-red-500/10 border border-red-500/20 rounded-lg p-4 text-red-400">Please allow us to contact you, or leave the email field empty.</div>';
+    return;
+  }
+  
+  let suggestions = [];
   try {
     messageDiv.innerHTML = '<div class="bg-blue-500/10 border border-blue-500/20 rounded-lg p-4 text-blue-400">Sending feedback...</div>';
     
     const urlParams = new URLSearchParams(formData);
+    urlParams.append('clientTimestamp', new Date().toISOString());
     
     const response = await fetch('https://us-central1-vega-ai-live.cloudfunctions.net/vega-landing-api?action=feedback', {
       method: 'POST',
@@ -44,6 +55,9 @@ async function submitFeedback(event) {
     
     if (!response.ok) {
       console.warn('Feedback submission failed:', response.status, response.statusText);
+    } else {
+      const result = await response.json();
+      suggestions = Array.isArray(result.suggestions) ? result.suggestions : [];
     }
     
   } catch (error) {
@@ -52,10 +66,87 @@ async function submitFeedback(event) {
   
   messageDiv.innerHTML = '<div class="bg-green-500/10 border border-green-500/20 rounded-lg p-4 text-green-400"><div class="flex items-center gap-2"><svg class="w-5 h-5" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M10 18a8 8 0 100-16 8 8 0 000 16zm3.707-9.293a1 1 0 00-1.414-1.414L9 10.586 7.707 9.293a1 1 0 00-1.414 1.414l2 2a1 1 0 001.414 0l4-4z" clip-rule="evenodd"></path></svg><span>Thank you for your feedback!</span></div></div>';
   
+  if (suggestions.length > 0) {
+    messageDiv.appendChild(renderSuggestions(suggestions));
+    form.reset();
+    document.getElementById('difficulty-value').innerText = '5';
+    return;
+  }
+  
   form.reset();
   document.getElementById('difficulty-value').innerText = '5';
   setTimeout(() => {
     document.getElementById('feedback-form-container').classList.add('hidden');
     document.getElementById('expand-icon').classList.remove('rotate-180');
   }, 3000);
-}
\ No newline at end of file
+}
+
+// Suggestions come from the API, so they are built as DOM nodes rather than
+// HTML strings. Text between backticks is shown as code.
+function renderSuggestions(suggestions) {
+  const container = document.createElement('div');
+  container.className = 'mt-4 space-y-4';
+  
+  const heading = document.createElement('p');
+  heading.className = 'text-gray-300 text-sm';
+  heading.textContent = 'These tips may help with the problems you reported:';
+  container.appendChild(heading);
+  
+  suggestions.forEach(suggestion => {
+    const card = document.createElement('div');
+    card.className = 'bg-slate-800/60 border border-slate-700 rounded-lg p-4 text-left';
+    
+    const title = document.createElement('h4');
+    title.className = 'text-white font-semibold mb-1';
+    title.textContent = suggestion.title;
+    card.appendChild(title);
+    
+    if (suggestion.summary) {
+      const summary = document.createElement('p');
+      summary.className = 'text-gray-400 text-sm mb-2';
+      summary.textContent = suggestion.summary;
+      card.appendChild(summary);
+    }
+    
+    if (Array.isArray(suggestion.steps) && suggestion.steps.length > 0) {
+      const steps = document.createElement('ol');
+      steps.className = 'list-decimal list-inside text-gray-300 text-sm space-y-1 mb-2';
+      suggestion.steps.forEach(step => {
+        const item = document.createElement('li');
+        appendWithCode(item, step);
+        steps.appendChild(item);
+      });
+      card.appendChild(steps);
+    }
+    
+    (suggestion.links || []).forEach(link => {
+      if (!/^https:\/\//.test(link.url)) {
+        return;
+      }
+      const anchor = document.createElement('a');
+      anchor.href = link.url;
+      anchor.target = '_blank';
+      anchor.rel = 'noopener';
+      anchor.className = 'text-primary hover:text-primary-dark transition-colors text-sm mr-4';
+      anchor.textContent = link.title + ' →';
+      card.appendChild(anchor);
+    });
+    
+    container.appendChild(card);
+  });
+  
+  return container;
+}
+
+function appendWithCode(element, text) {
+  text.split('`').forEach((part, index) => {
+    if (index % 2 === 1) {
+      const code = document.createElement('code');
+      code.className = 'bg-slate-700 text-primary px-1 py-0.5 rounded text-xs font-mono';
+      code.textContent = part;
+      element.appendChild(code);
+    } else {
+      element.appendChild(document.createTextNode(part));
+    }
+  });
+}
@@ -189,4 +189,49 @@ document.addEventListener('DOMContentLoaded', function() {
   const currentYearEl = document.getElementById('current-year');
   if (currentYearEl) currentYearEl.textContent = new Date().getFullYear();
 
-});
\ No newline at end of file
+  loadReleases();
+
+  // Count the visit and which sections are used. No cookies or identifiers
+  // are stored in the browser.
+  trackEvent('pageview');
+  document.querySelectorAll('#faq details').forEach(details => {
+    details.addEventListener('toggle', () => {
+      if (details.open) trackEvent('open-faq');
+    });
+  });
+
+});
+
+// Send an anonymous page event. sendBeacon survives navigation and its
+// response is ignored, so tracking never affects the page.
+function trackEvent(name) {
+  if (!navigator.sendBeacon) return;
+  navigator.sendBeacon('https://us-central1-vega-ai-live.cloudfunctions.net/vega-landing-api?action=events',
+    JSON.stringify({ event: name }));
+}
+
+// Show the latest release versions next to install steps. The page works
+// without them, so failures are ignored.
+async function loadReleases() {
+  const badges = document.querySelectorAll('[data-release]');
+  if (badges.length === 0) return;
+
+  try {
+    const response = await fetch('https://us-central1-vega-ai-live.cloudfunctions.net/vega-landing-api?action=releases');
+    if (!response.ok) return;
+    const releases = await response.json();
+
+    badges.forEach(badge => {
+      const release = releases[badge.dataset.release];
+      if (!release || !release.version) return;
+
+      const published = new Date(release.publishedAt);
+      badge.textContent = isNaN(published) ? release.version :
+        `${release.version} · ${published.toLocaleDateString(undefined, { year: 'numeric', month: 'short', day: 'numeric' })}`;
+      if (release.notes) badge.title = release.notes;
+      badge.classList.remove('hidden');
+    });
+  } catch (error) {
+    // Keep the static links.
+  }
+}
\ No newline at end of file